@@ -4,23 +4,117 @@ import (
 	"errors"
 )
 
-// GenerateCursorQuery generates and returns a cursor range query
+// GenerateCursorQuery generates and returns a cursor range query. Secondarily sorting on _id is
+// redundant when the paginated field is _id, the query then compares the _id only, to the first
+// cursor field value.
 func GenerateCursorQuery(shouldSecondarySortOnID bool, paginatedField string, comparisonOp string, cursorFieldValues []interface{}) (map[string]interface{}, error) {
-	var query map[string]interface{}
+	if shouldSecondarySortOnID && paginatedField == "_id" {
+		if len(cursorFieldValues) != 1 && len(cursorFieldValues) != 2 {
+			return nil, errors.New("wrong number of cursor field values specified")
+		}
+		shouldSecondarySortOnID = false
+		cursorFieldValues = cursorFieldValues[:1]
+	}
 	if (shouldSecondarySortOnID && len(cursorFieldValues) != 2) ||
 		(!shouldSecondarySortOnID && len(cursorFieldValues) != 1) {
 		return nil, errors.New("wrong number of cursor field values specified")
 	}
+	fields := []string{paginatedField}
+	comparisonOps := []string{comparisonOp}
 	if shouldSecondarySortOnID {
-		query = map[string]interface{}{"$or": []map[string]interface{}{
-			{paginatedField: map[string]interface{}{comparisonOp: cursorFieldValues[0]}},
-			{"$and": []map[string]interface{}{
-				{paginatedField: map[string]interface{}{"$eq": cursorFieldValues[0]}},
-				{"_id": map[string]interface{}{comparisonOp: cursorFieldValues[1]}},
-			}},
+		fields = append(fields, "_id")
+		comparisonOps = append(comparisonOps, comparisonOp)
+	}
+	return GenerateCompoundCursorQuery(fields, comparisonOps, cursorFieldValues)
+}
+
+// GenerateCompoundCursorQuery generates and returns a cursor range query over the specified fields,
+// in sort order, each field being compared with its comparison operator. A document is after the
+// cursor if its first field is past the cursor's value, or if it is equal on the first field and
+// past the cursor on the remaining fields, and so on.
+func GenerateCompoundCursorQuery(fields []string, comparisonOps []string, cursorFieldValues []interface{}) (map[string]interface{}, error) {
+	if len(fields) == 0 || len(fields) != len(cursorFieldValues) {
+		return nil, errors.New("wrong number of cursor field values specified")
+	}
+	if len(fields) != len(comparisonOps) {
+		return nil, errors.New("wrong number of comparison operators specified")
+	}
+	if len(fields) == 1 {
+		return map[string]interface{}{fields[0]: map[string]interface{}{comparisonOps[0]: cursorFieldValues[0]}}, nil
+	}
+	or := make([]map[string]interface{}, 0, len(fields))
+	or = append(or, map[string]interface{}{fields[0]: map[string]interface{}{comparisonOps[0]: cursorFieldValues[0]}})
+	for i := 1; i < len(fields); i++ {
+		and := make([]map[string]interface{}, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, map[string]interface{}{fields[j]: map[string]interface{}{"$eq": cursorFieldValues[j]}})
+		}
+		and = append(and, map[string]interface{}{fields[i]: map[string]interface{}{comparisonOps[i]: cursorFieldValues[i]}})
+		or = append(or, map[string]interface{}{"$and": and})
+	}
+	return map[string]interface{}{"$or": or}, nil
+}
+
+// Comparison returns the query selecting the documents whose field compares to the cursor value
+// with the comparison operator, one of $gt, $gte, $lt, $lte and $eq, e.g. to compare a computed key
+// with an $expr query.
+type Comparison func(field string, comparisonOp string, value interface{}) map[string]interface{}
+
+// GenerateNullableCursorQuery generates and returns a cursor range query over the specified fields,
+// like GenerateCompoundCursorQuery, for fields whose value may be null or missing. Null and missing
+// values sort before any other value, so a null cursor value is before every non null value and a
+// document with a null field is before every non null cursor value.
+func GenerateNullableCursorQuery(fields []string, comparisonOps []string, cursorFieldValues []interface{}) (map[string]interface{}, error) {
+	return GenerateCustomCursorQuery(fields, comparisonOps, cursorFieldValues, NullableComparison)
+}
+
+// GenerateCustomCursorQuery generates and returns a cursor range query over the specified fields,
+// like GenerateCompoundCursorQuery, each comparison of a field to its cursor value, the equality
+// ones included, being returned by compare. A nil compare defaults to NullableComparison.
+func GenerateCustomCursorQuery(fields []string, comparisonOps []string, cursorFieldValues []interface{}, compare Comparison) (map[string]interface{}, error) {
+	if len(fields) == 0 || len(fields) != len(cursorFieldValues) {
+		return nil, errors.New("wrong number of cursor field values specified")
+	}
+	if len(fields) != len(comparisonOps) {
+		return nil, errors.New("wrong number of comparison operators specified")
+	}
+	if compare == nil {
+		compare = NullableComparison
+	}
+	if len(fields) == 1 {
+		return compare(fields[0], comparisonOps[0], cursorFieldValues[0]), nil
+	}
+	or := make([]map[string]interface{}, 0, len(fields))
+	or = append(or, compare(fields[0], comparisonOps[0], cursorFieldValues[0]))
+	for i := 1; i < len(fields); i++ {
+		and := make([]map[string]interface{}, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, compare(fields[j], "$eq", cursorFieldValues[j]))
+		}
+		and = append(and, compare(fields[i], comparisonOps[i], cursorFieldValues[i]))
+		or = append(or, map[string]interface{}{"$and": and})
+	}
+	return map[string]interface{}{"$or": or}, nil
+}
+
+// NullableComparison returns the query comparing the field to the value with the comparison
+// operator, null and missing values being less than any other value. It's the Comparison of
+// GenerateNullableCursorQuery.
+func NullableComparison(field string, comparisonOp string, value interface{}) map[string]interface{} {
+	switch {
+	case value == nil && comparisonOp == "$gt":
+		// Every non null value is greater than null
+		return map[string]interface{}{field: map[string]interface{}{"$ne": nil}}
+	case value == nil && comparisonOp == "$gte":
+		return map[string]interface{}{}
+	case value != nil && (comparisonOp == "$lt" || comparisonOp == "$lte"):
+		// Null and missing values are less than any non null value
+		return map[string]interface{}{"$or": []map[string]interface{}{
+			{field: map[string]interface{}{comparisonOp: value}},
+			{field: map[string]interface{}{"$eq": nil}},
 		}}
-	} else {
-		query = map[string]interface{}{paginatedField: map[string]interface{}{comparisonOp: cursorFieldValues[0]}}
 	}
-	return query, nil
+	// $eq null matches both null and missing values, $lt null matches nothing and $lte null
+	// matches null and missing values
+	return map[string]interface{}{field: map[string]interface{}{comparisonOp: value}}
 }
@@ -59,6 +59,33 @@ func TestGenerateCursorQuery(t *testing.T) {
 			map[string]interface{}{"_id": map[string]interface{}{"$lt": "123"}},
 			nil,
 		},
+		{
+			"return a simple _id cursor query when the paginated field is _id and shouldSecondarySortOnID is true",
+			true,
+			"_id",
+			"$gt",
+			[]interface{}{"123", "123"},
+			map[string]interface{}{"_id": map[string]interface{}{"$gt": "123"}},
+			nil,
+		},
+		{
+			"return a simple _id cursor query from a single value when the paginated field is _id and shouldSecondarySortOnID is true",
+			true,
+			"_id",
+			"$lt",
+			[]interface{}{"123"},
+			map[string]interface{}{"_id": map[string]interface{}{"$lt": "123"}},
+			nil,
+		},
+		{
+			"error when wrong number of cursor field values specified and the paginated field is _id",
+			true,
+			"_id",
+			"$lt",
+			[]interface{}{},
+			nil,
+			errors.New("wrong number of cursor field values specified"),
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -68,3 +95,215 @@ func TestGenerateCursorQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCompoundCursorQuery(t *testing.T) {
+	var cases = []struct {
+		name              string
+		fields            []string
+		comparisonOps     []string
+		cursorFieldValues []interface{}
+		expectedQuery     map[string]interface{}
+		expectedErr       error
+	}{
+		{
+			"error when no fields specified",
+			[]string{},
+			[]string{},
+			[]interface{}{},
+			nil,
+			errors.New("wrong number of cursor field values specified"),
+		},
+		{
+			"error when the number of cursor field values doesn't match the number of fields",
+			[]string{"name", "region", "seq"},
+			[]string{"$gt", "$gt", "$gt"},
+			[]interface{}{"test item", "emea"},
+			nil,
+			errors.New("wrong number of cursor field values specified"),
+		},
+		{
+			"error when the number of comparison operators doesn't match the number of fields",
+			[]string{"name", "_id"},
+			[]string{"$gt"},
+			[]interface{}{"test item", "123"},
+			nil,
+			errors.New("wrong number of comparison operators specified"),
+		},
+		{
+			"return appropriate cursor query for a single field",
+			[]string{"_id"},
+			[]string{"$lt"},
+			[]interface{}{"123"},
+			map[string]interface{}{"_id": map[string]interface{}{"$lt": "123"}},
+			nil,
+		},
+		{
+			"return appropriate cursor query for a composite tie-breaker of mixed types",
+			[]string{"name", "region", "seq"},
+			[]string{"$gt", "$gt", "$gt"},
+			[]interface{}{"test item", "emea", int32(7)},
+			map[string]interface{}{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$gt": "test item"}},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item"}},
+					{"region": map[string]interface{}{"$gt": "emea"}}},
+				},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item"}},
+					{"region": map[string]interface{}{"$eq": "emea"}},
+					{"seq": map[string]interface{}{"$gt": int32(7)}}},
+				},
+			}},
+			nil,
+		},
+		{
+			"return appropriate cursor query with a different comparison operator per field",
+			[]string{"name", "_id"},
+			[]string{"$lt", "$gte"},
+			[]interface{}{"test item", "123"},
+			map[string]interface{}{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$lt": "test item"}},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item"}},
+					{"_id": map[string]interface{}{"$gte": "123"}}},
+				},
+			}},
+			nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := GenerateCompoundCursorQuery(tc.fields, tc.comparisonOps, tc.cursorFieldValues)
+			require.Equal(t, tc.expectedQuery, query)
+			require.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestGenerateNullableCursorQuery(t *testing.T) {
+	var cases = []struct {
+		name              string
+		fields            []string
+		comparisonOps     []string
+		cursorFieldValues []interface{}
+		expectedQuery     map[string]interface{}
+		expectedErr       error
+	}{
+		{
+			"error when the number of cursor field values doesn't match the number of fields",
+			[]string{"name", "_id"},
+			[]string{"$gt", "$gt"},
+			[]interface{}{"test item"},
+			nil,
+			errors.New("wrong number of cursor field values specified"),
+		},
+		{
+			"error when the number of comparison operators doesn't match the number of fields",
+			[]string{"name", "_id"},
+			[]string{"$gt"},
+			[]interface{}{"test item", "123"},
+			nil,
+			errors.New("wrong number of comparison operators specified"),
+		},
+		{
+			"return a $gt comparison for a non null value",
+			[]string{"name"},
+			[]string{"$gt"},
+			[]interface{}{"test item"},
+			map[string]interface{}{"name": map[string]interface{}{"$gt": "test item"}},
+			nil,
+		},
+		{
+			"return a $ne null comparison for a $gt null value",
+			[]string{"name"},
+			[]string{"$gt"},
+			[]interface{}{nil},
+			map[string]interface{}{"name": map[string]interface{}{"$ne": nil}},
+			nil,
+		},
+		{
+			"return an empty query for a $gte null value",
+			[]string{"name"},
+			[]string{"$gte"},
+			[]interface{}{nil},
+			map[string]interface{}{},
+			nil,
+		},
+		{
+			"include null values for a $lt non null value",
+			[]string{"name"},
+			[]string{"$lt"},
+			[]interface{}{"test item"},
+			map[string]interface{}{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$lt": "test item"}},
+				{"name": map[string]interface{}{"$eq": nil}},
+			}},
+			nil,
+		},
+		{
+			"return a $lt null comparison for a $lt null value",
+			[]string{"name"},
+			[]string{"$lt"},
+			[]interface{}{nil},
+			map[string]interface{}{"name": map[string]interface{}{"$lt": nil}},
+			nil,
+		},
+		{
+			"return appropriate cursor query over several fields with null values",
+			[]string{"status", "score", "_id"},
+			[]string{"$gt", "$lt", "$gt"},
+			[]interface{}{nil, int32(3), "123"},
+			map[string]interface{}{"$or": []map[string]interface{}{
+				{"status": map[string]interface{}{"$ne": nil}},
+				{"$and": []map[string]interface{}{
+					{"status": map[string]interface{}{"$eq": nil}},
+					{"$or": []map[string]interface{}{
+						{"score": map[string]interface{}{"$lt": int32(3)}},
+						{"score": map[string]interface{}{"$eq": nil}},
+					}},
+				}},
+				{"$and": []map[string]interface{}{
+					{"status": map[string]interface{}{"$eq": nil}},
+					{"score": map[string]interface{}{"$eq": int32(3)}},
+					{"_id": map[string]interface{}{"$gt": "123"}},
+				}},
+			}},
+			nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := GenerateNullableCursorQuery(tc.fields, tc.comparisonOps, tc.cursorFieldValues)
+			require.Equal(t, tc.expectedQuery, query)
+			require.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestGenerateCustomCursorQuery(t *testing.T) {
+	// The comparisons, the equality ones included, are returned by the custom comparison
+	lowercase := func(field string, comparisonOp string, value interface{}) map[string]interface{} {
+		return map[string]interface{}{"$expr": map[string]interface{}{
+			comparisonOp: []interface{}{map[string]interface{}{"$toLower": "$" + field}, value},
+		}}
+	}
+	query, err := GenerateCustomCursorQuery([]string{"name", "_id"}, []string{"$gt", "$gt"}, []interface{}{"item", "123"}, lowercase)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"$or": []map[string]interface{}{
+		lowercase("name", "$gt", "item"),
+		{"$and": []map[string]interface{}{
+			lowercase("name", "$eq", "item"),
+			lowercase("_id", "$gt", "123"),
+		}},
+	}}, query)
+
+	// A nil comparison defaults to the nullable comparison
+	query, err = GenerateCustomCursorQuery([]string{"name", "_id"}, []string{"$lt", "$lt"}, []interface{}{nil, "123"}, nil)
+	require.NoError(t, err)
+	expected, err := GenerateNullableCursorQuery([]string{"name", "_id"}, []string{"$lt", "$lt"}, []interface{}{nil, "123"})
+	require.NoError(t, err)
+	require.Equal(t, expected, query)
+
+	_, err = GenerateCustomCursorQuery([]string{"name"}, []string{"$gt"}, nil, lowercase)
+	require.EqualError(t, err, "wrong number of cursor field values specified")
+}
@@ -0,0 +1,55 @@
+package mgo
+
+import (
+	"context"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// QueryExecutor executes the count and find queries of Find, e.g. so the handlers paginating with
+// Find can be unit tested against canned results rather than a mongo database.
+type QueryExecutor interface {
+	// Count returns the number of documents of the collection matching all the queries
+	Count(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error)
+	// Find fills the results, a pointer to a slice, with at most limit documents of the collection
+	// matching all the queries, sorted by the sort fields, e.g. "-name" for a descending sort, and
+	// compared with the collation when not nil. The limit is the one of the query: Find passes one
+	// more than the Limit of the FindParams to see if there's another page, and the executor must
+	// not add to it
+	Find(ctx context.Context, db MgoDb, collectionName string, queries []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error
+}
+
+// DefaultExecutor is the QueryExecutor executing the queries on the DB of the FindParams, used
+// when their Executor is nil, e.g. to wrap it in an Executor logging the queries.
+var DefaultExecutor QueryExecutor = dbExecutor{}
+
+// dbExecutor is the QueryExecutor executing the queries on the DB.
+type dbExecutor struct{}
+
+func (dbExecutor) Count(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+	return executeCountQuery(ctx, db, collectionName, queries)
+}
+
+func (dbExecutor) Find(ctx context.Context, db MgoDb, collectionName string, queries []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+	return executeCursorQuery(ctx, db, collectionName, queries, sort, limit, collation, results)
+}
+
+// executor returns the Executor of the FindParams, or the DefaultExecutor when it's nil.
+func executor(p FindParams) QueryExecutor {
+	if p.Executor != nil {
+		return p.Executor
+	}
+	return DefaultExecutor
+}
+
+// countQuery executes the count query of the FindParams on their executor.
+func countQuery(ctx context.Context, p FindParams, queries []bson.M) (int, error) {
+	return executor(p).Count(ctx, p.DB, p.CollectionName, queries)
+}
+
+// cursorQuery executes the find query of the FindParams on their executor, getting an additional
+// element to see if there's another page.
+func cursorQuery(ctx context.Context, p FindParams, queries []bson.M, sort []string, results interface{}) error {
+	return executor(p).Find(ctx, p.DB, p.CollectionName, queries, sort, lookaheadLimit(p.Limit), p.Collation, results)
+}
@@ -0,0 +1,191 @@
+package mgo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+// cannedExecutor is a QueryExecutor returning canned results and recording the queries.
+type cannedExecutor struct {
+	count   int
+	results []item
+	queries [][]bson.M
+	sort    []string
+	limit   int
+}
+
+func (e *cannedExecutor) Count(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+	return e.count, nil
+}
+
+func (e *cannedExecutor) Find(ctx context.Context, db MgoDb, collectionName string, queries []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+	e.queries, e.sort, e.limit = append(e.queries, queries), sort, limit
+	page := e.results
+	if len(page) > limit {
+		page = page[:limit]
+	}
+	*results.(*[]item) = append([]item{}, page...)
+	return nil
+}
+
+func TestFindExecutor(t *testing.T) {
+	executor := &cannedExecutor{
+		count: 3,
+		results: []item{
+			{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1"},
+			{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Name: "test item 2"},
+			{ID: bson.ObjectIdHex("3addf533e81549de7696cb04"), Name: "test item 3"},
+		},
+	}
+	p := FindParams{
+		CollectionName: "items",
+		Query:          bson.M{"group": "a"},
+		PaginatedField: "name",
+		SortAscending:  true,
+		Limit:          2,
+		CountTotal:     true,
+		Executor:       executor,
+	}
+
+	// The queries are executed without a DB, fetching one more document than the limit
+	var results []item
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []item{executor.results[0], executor.results[1]}, results)
+	require.Equal(t, 3, cursor.Count)
+	require.Equal(t, int64(2), cursor.Returned)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, [][]bson.M{{{"group": "a"}}}, executor.queries)
+	require.Equal(t, []string{"name", "_id"}, executor.sort)
+	require.Equal(t, 3, executor.limit)
+
+	// The cursor query of the next page is executed too
+	p.Next = cursor.Next
+	executor.results = executor.results[2:]
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, int64(1), cursor.Returned)
+	require.False(t, cursor.HasNext)
+	require.Len(t, executor.queries[1], 2)
+}
+
+// countingExecutor decorates a QueryExecutor, counting the queries it executes.
+type countingExecutor struct {
+	QueryExecutor
+	finds int
+}
+
+func (e *countingExecutor) Find(ctx context.Context, db MgoDb, collectionName string, queries []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+	e.finds++
+	return e.QueryExecutor.Find(ctx, db, collectionName, queries, sort, limit, collation, results)
+}
+
+func TestFindDefaultExecutor(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	var limits []int
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		limits = append(limits, limit)
+		return nil
+	}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		Limit:          2,
+	}
+
+	// The query limit is the same with or without an Executor decorating the DefaultExecutor
+	var results []item
+	_, err := Find(p, &results)
+	require.NoError(t, err)
+	executor := &countingExecutor{QueryExecutor: DefaultExecutor}
+	p.Executor = executor
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 3}, limits)
+	require.Equal(t, 1, executor.finds)
+}
+
+func TestFindExactlyLimitDocuments(t *testing.T) {
+	executor := &cannedExecutor{
+		results: []item{
+			{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1"},
+			{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Name: "test item 2"},
+		},
+	}
+	p := FindParams{
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		SortAscending:  true,
+		Limit:          2,
+		Executor:       executor,
+	}
+
+	// The lookahead document isn't found, there's no next page
+	var results []item
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.False(t, cursor.HasNext)
+	require.Empty(t, cursor.Next)
+
+	// The limit of the query doesn't overflow with the largest limit
+	p.Limit = math.MaxInt
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, math.MaxInt, executor.limit)
+	require.Len(t, results, 2)
+	require.False(t, cursor.HasNext)
+}
+
+func TestFindMaxLimit(t *testing.T) {
+	executor := &cannedExecutor{
+		results: []item{
+			{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1"},
+			{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Name: "test item 2"},
+			{ID: bson.ObjectIdHex("3addf533e81549de7696cb04"), Name: "test item 3"},
+		},
+	}
+	p := FindParams{
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		SortAscending:  true,
+		Limit:          1000000,
+		MaxLimit:       2,
+		Executor:       executor,
+	}
+
+	// A Limit above the MaxLimit is clamped to it
+	var results []item
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, executor.limit)
+	require.Len(t, results, 2)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, 2, cursor.EffectiveLimit)
+
+	// A Limit below it is kept
+	p.Limit = 1
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, 1, cursor.EffectiveLimit)
+
+	// A Limit of at least 1 is still required
+	p.Limit = 0
+	_, err = Find(p, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+}
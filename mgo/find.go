@@ -3,10 +3,14 @@
 package mgo
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
@@ -35,14 +39,18 @@ type (
 		Query bson.M
 		// The number of results to fetch, should be > 0
 		Limit int
+		// When set, the largest Limit, a greater Limit being clamped to it rather than returning an
+		// error, e.g. when the Limit is passed by clients. The Cursor holds the EffectiveLimit
+		MaxLimit int
 		// true, if the results should be sort ascending, false otherwise
 		SortAscending bool
 		// The name of the mongo collection field being paginated and sorted on. This field must:
 		// 1. Be orderable. We must sort by this value. If duplicate values for paginatedField field
-		//    exist, the results will be secondarily ordered by the _id
+		//    exist, the results will be secondarily ordered by the IDField
 		// 2. Be indexed. For large collections, this should be indexed for query performance
 		// 3. Be immutable. If the value changes between paged queries, it could appear twice
-		// 4. Match the bson field name the result struct. e.g.:
+		// 4. Match the bson field name the result struct, Find returning ErrUnknownPaginatedField
+		//    otherwise when the results are structs. e.g.:
 		//
 		//    PaginatedField would be "name" when paginating employees by name
 		//
@@ -52,7 +60,20 @@ type (
 		//    }
 		//
 		PaginatedField string
-		// The collation to use for the sort ordering.
+		// When set, the documents sharing the same PaginatedField value are sorted on this field
+		// before the IDField, e.g. "dueDate" when paginating by "priority", and the cursors hold its
+		// value between their PaginatedField and IDField values. This is ignored if PaginatedField
+		// is empty
+		SecondaryPaginatedField string
+		// When set, the SecondaryPaginatedField is sorted in this direction rather than according
+		// to SortAscending, e.g. to sort by ascending priority with the latest due dates first
+		SecondarySortAscending *bool
+		// The field uniquely identifying the documents, e.g. a custom primary key such as "uid". It's
+		// paginated on when PaginatedField is empty, and secondarily sorted on otherwise, so the
+		// cursors hold its value. Defaults to _id
+		IDField string
+		// The collation to use for the sort ordering. It's set on the find query, so the comparisons
+		// of the cursor queries are evaluated with it as well.
 		// See https://docs.mongodb.com/manual/reference/collation-locales-defaults/#supported-languages-and-locales
 		// This is ignored if PaginatedField is empty
 		Collation *mgo.Collation
@@ -63,22 +84,68 @@ type (
 		// Whether or not to include total count of documents matching filter in the cursor
 		// Specifying true makes an additionnal query
 		CountTotal bool
+		// The context used to cancel the queries or set their deadline, context.Background() if nil.
+		// When it carries an OpenTelemetry span, the queries are traced in child spans of it.
+		// mgo queries can't be interrupted: a cancelled query keeps running in the background, but
+		// Find returns as soon as the context is done and the results are left untouched
+		Context context.Context
+		// When set, it's filled in with the find query sent to mongo, e.g. to log the keyset query of
+		// each page
+		Debug *FindDebug
+		// When set, it executes the count and find queries instead of the DB, which can then be
+		// nil, e.g. to unit test a handler without a mongo database
+		Executor QueryExecutor
+		// When true, Find doesn't query mongo: the cursors are parsed and the find query is built
+		// as usual and filled in the Debug, which is then required, but the count and find queries
+		// aren't executed, e.g. to test the queries built for given cursors without a database. The
+		// results are left untouched and the returned Cursor is empty
+		DryRun bool
+		// When set, it's called with the duration of the count and find queries once they succeeded,
+		// e.g. to export the latencies and page sizes as metrics
+		Observer Observer
 	}
 
-	// Cursor holds the pagination data about the find mongo query that was performed.
+	// FindDebug holds the find query Find sent to mongo.
+	FindDebug struct {
+		// The queries ANDed together in the find query: the Query of the FindParams, followed by the
+		// cursor query when querying with a Next or Previous cursor
+		Queries []bson.M
+		// The sort of the find query, reversed when querying with a Previous cursor
+		Sort []string
+		// The limit of the find query, one more than the Limit of the FindParams to see if there's
+		// another page
+		Limit int
+		// The collation of the find query, nil if none
+		Collation *mgo.Collation
+	}
+
+	// Cursor holds the pagination data about the find mongo query that was performed. It marshals
+	// to JSON with camelCase field names, leaving out the empty cursors.
 	Cursor struct {
 		// The URL safe previous page cursor to pass in a Find call to get the previous page.
 		// This is set to the empty string if there is no previous page.
-		Previous string
+		Previous string `json:"previous,omitempty"`
 		// The URL safe next page cursor to pass in a Find call to get the next page.
 		// This is set to the empty string if there is no next page.
-		Next string
+		Next string `json:"next,omitempty"`
 		// true if there is a previous page, false otherwise
-		HasPrevious bool
+		HasPrevious bool `json:"hasPrevious"`
 		// true if there is a next page, false otherwise
-		HasNext bool
-		// Total count of documents matching filter - only computed if CountTotal is True
-		Count int
+		HasNext bool `json:"hasNext"`
+		// The cursors of the first and last documents of the page, e.g. to bookmark the page. Unlike
+		// Previous and Next, they're set even when there's no page before or after this one, and
+		// are only empty when the page is empty or when their document misses a field the cursors
+		// hold, which fails Find only when the cursor is also the Previous or Next one
+		StartCursor string `json:"startCursor,omitempty"`
+		EndCursor   string `json:"endCursor,omitempty"`
+		// The number of documents of the page, at most the Limit
+		Returned int64 `json:"returned"`
+		// The Limit the page was queried with, the MaxLimit of the FindParams when their Limit
+		// exceeded it
+		EffectiveLimit int `json:"effectiveLimit"`
+		// Total count of documents matching filter - only computed if CountTotal is True. It's the
+		// count of all the pages, independent of the Limit and of the cursor of the page
+		Count int `json:"count"`
 	}
 
 	CursorError struct {
@@ -86,49 +153,121 @@ type (
 	}
 )
 
+// ErrInvalidResultsType is the error returned when the results passed to Find aren't a non nil
+// pointer to a slice
+var ErrInvalidResultsType = errors.New("results must be a non nil pointer to a slice")
+
+// The errors returned when the parameters passed to Find are invalid
+var (
+	ErrNilResults    = errors.New("results can't be nil")
+	ErrNilDB         = errors.New("DB can't be nil")
+	ErrNilCollection = errors.New("collection can't be nil")
+	ErrLimitTooSmall = errors.New("a limit of at least 1 is required")
+	ErrNilDebug      = errors.New("Debug can't be nil in a dry run")
+)
+
+// ErrUnknownPaginatedField is the error returned when the PaginatedField passed to Find isn't a
+// field of the struct type of the results
+var ErrUnknownPaginatedField = errors.New("the paginated field isn't a field of the results")
+
+// ErrCursorShapeMismatch matches, using errors.Is, the errors returned when a cursor passed to Find
+// doesn't hold a value for each of the sorted fields, e.g. a cursor generated before the sort of the
+// FindParams changed, so the pagination can be reset rather than failing
+var ErrCursorShapeMismatch = errors.New("the cursor doesn't match the sort")
+
+// cursorShapeError is the error of a cursor not holding a value for each of the sorted fields. It
+// matches ErrCursorShapeMismatch while keeping the message telling the expected shape.
+type cursorShapeError struct {
+	msg string
+}
+
+func (e *cursorShapeError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the target is ErrCursorShapeMismatch
+func (e *cursorShapeError) Is(target error) bool {
+	return target == ErrCursorShapeMismatch
+}
+
+// ErrBadCursor matches, using errors.Is, the errors returned when a cursor passed to Find is
+// malformed or can't be used, i.e. all the CursorErrors
+var ErrBadCursor = errors.New("bad cursor")
+
 func (e *CursorError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the cursor error
+func (e *CursorError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is ErrBadCursor, so errors.Is can tell the cursor errors apart
+func (e *CursorError) Is(target error) bool {
+	return target == ErrBadCursor
+}
+
+// FindWithContext executes a find mongo query like Find, using ctx as the FindParams' Context.
+func FindWithContext(ctx context.Context, p FindParams, results interface{}) (Cursor, error) {
+	p.Context = ctx
+	return Find(p, results)
+}
+
+// collectionDb is the MgoDb of a collection passed directly, returning it whatever the name.
+type collectionDb struct {
+	coll *mgo.Collection
+}
+
+func (db collectionDb) C(string) *mgo.Collection {
+	return db.coll
+}
+
+// FindInCollection executes a find mongo query like Find on the collection, e.g. one already
+// obtained with the right session, ignoring the DB and CollectionName of the FindParams.
+func FindInCollection(coll *mgo.Collection, p FindParams, results interface{}) (Cursor, error) {
+	if coll == nil {
+		return Cursor{}, ErrNilCollection
+	}
+	p.DB, p.CollectionName = collectionDb{coll: coll}, coll.Name
+	return Find(p, results)
+}
+
 // Find executes a find mongo query by using the provided FindParams, fills the passed in result
 // slice pointer and returns a Cursor.
 func Find(p FindParams, results interface{}) (Cursor, error) {
-	var err error
-	if results == nil {
-		return Cursor{}, errors.New("results can't be nil")
+	if p.Context == nil {
+		p.Context = context.Background()
 	}
-
-	if p.PaginatedField == "" {
-		p.PaginatedField = "_id"
-		p.Collation = nil
+	if results == nil {
+		return Cursor{}, ErrNilResults
 	}
-	shouldSecondarySortOnID := p.PaginatedField != "_id"
-
-	if p.DB == nil {
-		return Cursor{}, errors.New("DB can't be nil")
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
 	}
-
-	if p.Limit <= 0 {
-		return Cursor{}, errors.New("a limit of at least 1 is required")
+	if err := validatePaginatedField(p.PaginatedField, results); err != nil {
+		return Cursor{}, err
 	}
-
-	nextCursorValues, err := parseCursor(p.Next, shouldSecondarySortOnID)
-	if err != nil {
-		return Cursor{}, &CursorError{fmt.Errorf("next cursor parse failed: %s", err)}
+	if err := validatePaginatedField(p.SecondaryPaginatedField, results); err != nil {
+		return Cursor{}, err
 	}
 
-	previousCursorValues, err := parseCursor(p.Previous, shouldSecondarySortOnID)
+	q, err := p.parseQuery()
 	if err != nil {
-		return Cursor{}, &CursorError{fmt.Errorf("previous cursor parse failed: %s", err)}
+		return Cursor{}, err
 	}
-
-	// Figure out the sort direction and comparison operator that will be used in the augmented query
-	sortAsc := (!p.SortAscending && p.Previous != "") || (p.SortAscending && p.Previous == "")
-	comparisonOp := "$gt"
-	sortDir := ""
-	if !sortAsc {
-		comparisonOp = "$lt"
-		sortDir = "-"
+	p, fields, ascending := q.p, q.fields, q.ascending
+	nextCursorValues, previousCursorValues := q.next, q.previous
+
+	// Figure out the sort direction and comparison operator of each field that will be used in the
+	// augmented query, the previous page being queried in the reverse order
+	comparisonOps := make([]string, len(fields))
+	sort := make([]string, len(fields))
+	for i, field := range fields {
+		comparisonOps[i], sort[i] = "$gt", field
+		if ascending[i] != (p.Previous == "") {
+			comparisonOps[i], sort[i] = "$lt", "-"+field
+		}
 	}
 
 	// Augment the specified find query with cursor data
@@ -136,10 +275,17 @@ func Find(p FindParams, results interface{}) (Cursor, error) {
 
 	// Compute total count of documents matching filter - only computed if CountTotal is True
 	var count int
-	if p.CountTotal {
-		count, err = executeCountQuery(p.DB, p.CollectionName, queries)
+	if p.CountTotal && !p.DryRun {
+		start := time.Now()
+		err = traced(p, countSpanName, func(ctx context.Context) (err error) {
+			count, err = countQuery(ctx, p, queries)
+			return err
+		})
 		if err != nil {
-			return Cursor{}, err
+			return Cursor{}, contextError(p.Context, "count query", err)
+		}
+		if p.Observer != nil {
+			p.Observer.ObserveCount(time.Since(start))
 		}
 	}
 
@@ -152,28 +298,27 @@ func Find(p FindParams, results interface{}) (Cursor, error) {
 			cursorValues = previousCursorValues
 		}
 		var cursorQuery bson.M
-		cursorQuery, err = mcpbson.GenerateCursorQuery(shouldSecondarySortOnID, p.PaginatedField, comparisonOp, cursorValues)
+		cursorQuery, err = mcpbson.GenerateCompoundCursorQuery(fields, comparisonOps, cursorValues)
 		if err != nil {
 			return Cursor{}, err
 		}
 		queries = append(queries, cursorQuery)
 	}
 
-	// Setup the sort query
-	var sort []string
-	if shouldSecondarySortOnID {
-		sort = []string{
-			fmt.Sprintf("%s%s", sortDir, p.PaginatedField),
-			fmt.Sprintf("%s%s", sortDir, "_id"),
-		}
-	} else {
-		sort = []string{fmt.Sprintf("%s%s", sortDir, "_id")}
+	if p.Debug != nil {
+		*p.Debug = FindDebug{Queries: queries, Sort: sort, Limit: lookaheadLimit(p.Limit), Collation: p.Collation}
+	}
+	if p.DryRun {
+		return Cursor{}, nil
 	}
 
 	// Execute the augmented query, get an additional element to see if there's another page
-	err = executeCursorQuery(p.DB, p.CollectionName, queries, sort, p.Limit, p.Collation, results)
+	start := time.Now()
+	err = traced(p, cursorSpanName, func(ctx context.Context) error {
+		return cursorQuery(ctx, p, queries, sort, results)
+	})
 	if err != nil {
-		return Cursor{}, err
+		return Cursor{}, contextError(p.Context, "cursor query", err)
 	}
 
 	// Get the results slice's pointer and value
@@ -186,12 +331,17 @@ func Find(p FindParams, results interface{}) (Cursor, error) {
 	if hasMore {
 		resultsVal = resultsVal.Slice(0, resultsVal.Len()-1)
 	}
+	if p.Observer != nil {
+		p.Observer.ObserveFind(time.Since(start), resultsVal.Len())
+	}
 
 	hasPrevious := p.Next != "" || (p.Previous != "" && hasMore)
 	hasNext := p.Previous != "" || hasMore
 
 	var previousCursor string
 	var nextCursor string
+	var startCursor string
+	var endCursor string
 
 	if resultsVal.Len() > 0 {
 		// If we sorted reverse to get the previous page, correct the sort order
@@ -203,32 +353,46 @@ func Find(p FindParams, results interface{}) (Cursor, error) {
 			}
 		}
 
-		// Generate the previous cursor
-		if hasPrevious {
-			firstResult := resultsVal.Index(0).Interface()
-			previousCursor, err = generateCursor(firstResult, p.PaginatedField, shouldSecondarySortOnID)
-			if err != nil {
+		// Generate the cursors of the first and last results, which only fail the query when
+		// they're the previous or next cursors, e.g. for a document missing the paginated field,
+		// the start or end cursor being left empty otherwise
+		firstResult := resultsVal.Index(0).Interface()
+		startCursor, err = generateFieldsCursor(firstResult, fields)
+		if err != nil {
+			if hasPrevious {
 				return Cursor{}, fmt.Errorf("could not create a previous cursor: %s", err)
 			}
+			startCursor = ""
 		}
-
-		// Generate the next cursor
-		if hasNext {
-			lastResult := resultsVal.Index(resultsVal.Len() - 1).Interface()
-			nextCursor, err = generateCursor(lastResult, p.PaginatedField, shouldSecondarySortOnID)
-			if err != nil {
+		lastResult := resultsVal.Index(resultsVal.Len() - 1).Interface()
+		endCursor, err = generateFieldsCursor(lastResult, fields)
+		if err != nil {
+			if hasNext {
 				return Cursor{}, fmt.Errorf("could not create a next cursor: %s", err)
 			}
+			endCursor = ""
+		}
+
+		// The previous and next cursors are those of the pages around this one
+		if hasPrevious {
+			previousCursor = startCursor
+		}
+		if hasNext {
+			nextCursor = endCursor
 		}
 	}
 
 	// Create the response cursor
 	cursor := Cursor{
-		Previous:    previousCursor,
-		HasPrevious: hasPrevious,
-		Next:        nextCursor,
-		HasNext:     hasNext,
-		Count:       count,
+		Previous:       previousCursor,
+		HasPrevious:    hasPrevious,
+		Next:           nextCursor,
+		HasNext:        hasNext,
+		StartCursor:    startCursor,
+		EndCursor:      endCursor,
+		Returned:       int64(resultsVal.Len()),
+		EffectiveLimit: p.Limit,
+		Count:          count,
 	}
 
 	// Save the modified result slice in the result pointer
@@ -237,24 +401,194 @@ func Find(p FindParams, results interface{}) (Cursor, error) {
 	return cursor, nil
 }
 
+// Validate checks the FindParams without executing any query, returning the error Find would
+// return for them, e.g. ErrNilDB, ErrLimitTooSmall or the error of a Next or Previous cursor which
+// can't be parsed, so invalid requests can be rejected early. The results aren't checked.
+func (p FindParams) Validate() error {
+	_, err := p.parseQuery()
+	return err
+}
+
+// findQuery holds the FindParams with their defaults filled in, the fields sorted on, in order,
+// with whether each is sorted ascending for the next page, and the values of their cursors.
+type findQuery struct {
+	p              FindParams
+	fields         []string
+	ascending      []bool
+	next, previous []interface{}
+}
+
+// parseQuery validates the FindParams and parses their cursors.
+func (p FindParams) parseQuery() (findQuery, error) {
+	if p.IDField == "" {
+		p.IDField = "_id"
+	}
+	if p.PaginatedField == "" {
+		p.PaginatedField = p.IDField
+		p.Collation = nil
+	}
+	shouldSecondarySortOnID := p.PaginatedField != p.IDField
+
+	// The fields sorted on, in order, and whether each is sorted ascending for the next page
+	fields, ascending := []string{p.PaginatedField}, []bool{p.SortAscending}
+	if shouldSecondarySortOnID {
+		if p.SecondaryPaginatedField != "" {
+			secondaryAscending := p.SortAscending
+			if p.SecondarySortAscending != nil {
+				secondaryAscending = *p.SecondarySortAscending
+			}
+			fields, ascending = append(fields, p.SecondaryPaginatedField), append(ascending, secondaryAscending)
+		}
+		fields, ascending = append(fields, p.IDField), append(ascending, p.SortAscending)
+	}
+	parse := func(cursor string) ([]interface{}, error) {
+		return parseCursor(cursor, shouldSecondarySortOnID)
+	}
+	if len(fields) == 3 {
+		parse = parseSecondaryCursor
+	}
+
+	if p.DB == nil && p.Executor == nil && !p.DryRun {
+		return findQuery{}, ErrNilDB
+	}
+
+	if p.Debug == nil && p.DryRun {
+		return findQuery{}, ErrNilDebug
+	}
+
+	if p.Limit <= 0 {
+		return findQuery{}, ErrLimitTooSmall
+	}
+	if p.MaxLimit > 0 && p.Limit > p.MaxLimit {
+		p.Limit = p.MaxLimit
+	}
+
+	next, err := parse(p.Next)
+	if err != nil {
+		return findQuery{}, &CursorError{fmt.Errorf("next cursor parse failed: %w", err)}
+	}
+
+	previous, err := parse(p.Previous)
+	if err != nil {
+		return findQuery{}, &CursorError{fmt.Errorf("previous cursor parse failed: %w", err)}
+	}
+	return findQuery{p: p, fields: fields, ascending: ascending, next: next, previous: previous}, nil
+}
+
+// validateResults returns ErrInvalidResultsType if the results aren't a non nil pointer to a
+// slice, which Find fills in using reflection.
+func validateResults(results interface{}) error {
+	resultsPtr := reflect.ValueOf(results)
+	if resultsPtr.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T", ErrInvalidResultsType, results)
+	}
+	if resultsPtr.IsNil() {
+		return fmt.Errorf("%w, got a nil %T", ErrInvalidResultsType, results)
+	}
+	if resultsPtr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w, got %T", ErrInvalidResultsType, results)
+	}
+	return nil
+}
+
+// The types of the values decoding any document
+var (
+	setterType   = reflect.TypeOf((*bson.Setter)(nil)).Elem()
+	documentType = reflect.TypeOf(bson.D{})
+	rawDocType   = reflect.TypeOf(bson.RawD{})
+	rawType      = reflect.TypeOf(bson.Raw{})
+)
+
+// validatePaginatedField returns ErrUnknownPaginatedField if the results are a slice of structs, or
+// of pointers to structs, with no field the paginated field resolves to, so the cursors couldn't
+// be generated. The fields of other results, e.g. bson.M, are dynamic and aren't checked.
+func validatePaginatedField(field string, results interface{}) error {
+	if field == "" {
+		return nil
+	}
+	elemType := reflect.TypeOf(results).Elem().Elem()
+	if !hasBSONField(elemType, strings.Split(field, ".")) {
+		return fmt.Errorf("%w, got %s for %s", ErrUnknownPaginatedField, field, elemType)
+	}
+	return nil
+}
+
+// hasBSONField reports whether the type may hold the field of the dotted path, by matching the keys
+// of the path with the fields of the struct types along it as the bson package names them. The
+// maps, interfaces, bson.D, bson.RawD, bson.Raw and bson.Setter types may hold any field.
+func hasBSONField(t reflect.Type, path []string) bool {
+	dynamic := func(t reflect.Type) bool {
+		return t == documentType || t == rawDocType || t == rawType || reflect.PtrTo(t).Implements(setterType)
+	}
+	for !dynamic(t) && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if len(path) == 0 || dynamic(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+	default:
+		// Values other than documents have no fields
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		// Like the bson package, use the whole tag when it has no key
+		tagValue := field.Tag.Get("bson")
+		if tagValue == "" && !strings.Contains(string(field.Tag), ":") {
+			tagValue = string(field.Tag)
+		}
+		tag := strings.Split(tagValue, ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		// The fields of an inlined struct are fields of the document, and an embedded struct may
+		// be inlined too
+		inline := false
+		for _, flag := range tag[1:] {
+			inline = inline || flag == "inline"
+		}
+		if (inline || (field.Anonymous && name == "")) && hasBSONField(field.Type, path) {
+			return true
+		}
+		if inline {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == path[0] && hasBSONField(field.Type, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
 var parseCursor = func(cursor string, shouldSecondarySortOnID bool) ([]interface{}, error) {
 	cursorValues := make([]interface{}, 0, 2)
 	if cursor != "" {
-		parsedCursor, err := decodeCursor(cursor)
+		parsedCursor, err := decodeQueryCursor(cursor)
 		if err != nil {
 			return nil, err
 		}
 		var id interface{}
 		if shouldSecondarySortOnID {
 			if len(parsedCursor) != 2 {
-				return nil, errors.New("expecting a cursor with two elements")
+				return nil, &cursorShapeError{"expecting a cursor with two elements"}
 			}
 			paginatedFieldValue := parsedCursor[0].Value
 			id = parsedCursor[1].Value
 			cursorValues = append(cursorValues, paginatedFieldValue)
 		} else {
 			if len(parsedCursor) != 1 {
-				return nil, errors.New("expecting a cursor with a single element")
+				return nil, &cursorShapeError{"expecting a cursor with a single element"}
 			}
 			id = parsedCursor[0].Value
 		}
@@ -263,10 +597,42 @@ var parseCursor = func(cursor string, shouldSecondarySortOnID bool) ([]interface
 	return cursorValues, nil
 }
 
-// decodeCursor decodes cursor data that was previously encoded with createCursor
+// parseSecondaryCursor parses a cursor holding the values of the paginated field, the secondary
+// paginated field and the ID field, in order.
+func parseSecondaryCursor(cursor string) ([]interface{}, error) {
+	if cursor == "" {
+		return []interface{}{}, nil
+	}
+	parsedCursor, err := decodeQueryCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsedCursor) != 3 {
+		return nil, &cursorShapeError{"expecting a cursor with three elements"}
+	}
+	return []interface{}{parsedCursor[0].Value, parsedCursor[1].Value, parsedCursor[2].Value}, nil
+}
+
+// cursorAlphabet maps the characters of the standard base64 alphabet which aren't url safe to the
+// ones of the url safe alphabet
+var cursorAlphabet = strings.NewReplacer("+", "-", "/", "_")
+
+// decodeCursorBytes decodes the base64 of a cursor. The cursors are encoded url safe and unpadded,
+// but the ones encoded with the standard alphabet, with or without padding, are accepted too, e.g.
+// the cursors of earlier versions: the alphabets only differ by the characters of the last two
+// values, so these are mapped to the url safe ones, and a padded cursor is decoded as such.
+func decodeCursorBytes(cursor string) ([]byte, error) {
+	cursor = cursorAlphabet.Replace(cursor)
+	if strings.HasSuffix(cursor, "=") {
+		return base64.URLEncoding.DecodeString(cursor)
+	}
+	return base64.RawURLEncoding.DecodeString(cursor)
+}
+
+// decodeCursor decodes cursor data that was previously encoded with createCursor.
 func decodeCursor(cursor string) (bson.D, error) {
 	var cursorData bson.D
-	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	data, err := decodeCursorBytes(cursor)
 	if err != nil {
 		return cursorData, err
 	}
@@ -275,18 +641,139 @@ func decodeCursor(cursor string) (bson.D, error) {
 	return cursorData, err
 }
 
-var executeCountQuery = func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
-	return db.C(collectionName).Find(bson.M{"$and": queries}).Count()
+// decodeQueryCursor decodes a cursor whose values are compared by the cursor queries. The bson
+// package encodes the integers fitting in 32 bits as int32 unless their type is int64, and decodes
+// the int32 as int, so the integer values are normalized to int64, the type they're stored with in
+// a collection holding integers of either size, and the cursor queries compare them as such.
+func decodeQueryCursor(cursor string) (bson.D, error) {
+	cursorData, err := decodeCursor(cursor)
+	if err != nil {
+		return cursorData, err
+	}
+	for i, e := range cursorData {
+		if value, ok := e.Value.(int); ok {
+			cursorData[i].Value = int64(value)
+		}
+	}
+	return cursorData, nil
+}
+
+// contextError wraps the error of the query with a message if it's the error of the done context,
+// otherwise it returns the error as is.
+func contextError(ctx context.Context, query string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return fmt.Errorf("%s interrupted: %w", query, err)
+	}
+	return err
+}
+
+// runWithContext runs the query, returning the context's error as soon as it's done.
+func runWithContext(ctx context.Context, query func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- query()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-var executeCursorQuery = func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
-	if collation == nil {
-		return db.C(collectionName).Find(bson.M{"$and": query}).Sort(sort...).Limit(limit + 1).All(results)
+var executeCountQuery = func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+	var count int
+	err := runWithContext(ctx, func() error {
+		var err error
+		count, err = db.C(collectionName).Find(bson.M{"$and": queries}).Count()
+		return err
+	})
+	if err != nil {
+		return 0, err
 	}
-	return db.C(collectionName).Find(bson.M{"$and": query}).Sort(sort...).Collation(collation).Limit(limit + 1).All(results)
+	return count, nil
+}
+
+// lookaheadLimit returns the limit of the find query of a page of the specified limit, getting an
+// additional document to see if there's another page. No document can follow a page of the largest
+// limit, which is then kept rather than overflowing.
+func lookaheadLimit(limit int) int {
+	if limit == math.MaxInt {
+		return limit
+	}
+	return limit + 1
+}
+
+var executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+	// Decode into a new slice so that a query still running after the context is done doesn't
+	// write to the results
+	page := reflect.New(reflect.TypeOf(results).Elem())
+	err := runWithContext(ctx, func() error {
+		q := db.C(collectionName).Find(bson.M{"$and": query}).Sort(sort...)
+		if collation != nil {
+			q = q.Collation(collation)
+		}
+		return q.Limit(limit).All(page.Interface())
+	})
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(results).Elem().Set(page.Elem())
+	return nil
+}
+
+// CursorForDocument generates the cursor Find would generate for the result, e.g. to pass as the
+// Next of a FindParams to start paginating after a known document without querying its page. The
+// cursor holds the value of the paginated field of the result, followed by its _id when
+// shouldSecondarySortOnID is true, which is the case in Find unless the PaginatedField is _id.
+func CursorForDocument(result interface{}, paginatedField string, shouldSecondarySortOnID bool) (string, error) {
+	return generateCursor(result, paginatedField, shouldSecondarySortOnID)
+}
+
+// ReverseCursor returns the cursor querying the page on the other side of the document of the
+// cursor, e.g. to query the page before the document a Next cursor was generated from. A cursor
+// holds the values of the paginated field and _id of a document rather than a direction, the
+// direction being given by passing it as the Next or as the Previous of the FindParams, so the
+// cursor is returned unchanged.
+func ReverseCursor(cursor string) string {
+	return cursor
+}
+
+// DecodeCursor decodes the cursor into the document it holds, e.g. to inspect a cursor before
+// passing it to Find. The values are returned as the bson package decodes them, e.g. an int for
+// an integer fitting in 32 bits, whereas ParseCursorValues returns the values Find compares.
+func DecodeCursor(cursor string) (bson.D, error) {
+	return decodeCursor(cursor)
+}
+
+// ParseCursorValues returns the values of the cursor Find would query the page from: the value of
+// the paginated field followed by the _id when shouldSecondarySortOnID is true, or the _id only
+// otherwise. It fails on the cursors Find rejects with a CursorError, returning the underlying
+// error, so a malformed cursor can be rejected before querying. An empty cursor holds no values.
+func ParseCursorValues(cursor string, shouldSecondarySortOnID bool) ([]interface{}, error) {
+	return parseCursor(cursor, shouldSecondarySortOnID)
 }
 
 func generateCursor(result interface{}, paginatedField string, shouldSecondarySortOnID bool) (string, error) {
+	return generateIDFieldCursor(result, paginatedField, "_id", shouldSecondarySortOnID)
+}
+
+// generateIDFieldCursor is generateCursor for documents identified by the idField, whose value
+// follows the value of the paginated field when shouldSecondarySortOnID is true.
+func generateIDFieldCursor(result interface{}, paginatedField string, idField string, shouldSecondarySortOnID bool) (string, error) {
+	fields := []string{paginatedField}
+	if shouldSecondarySortOnID {
+		fields = append(fields, idField)
+	}
+	return generateFieldsCursor(result, fields)
+}
+
+// generateFieldsCursor generates the cursor holding the values of the fields of the result, in
+// order, the first one being the paginated field, which the result must hold.
+func generateFieldsCursor(result interface{}, fields []string) (string, error) {
 	if result == nil {
 		return "", fmt.Errorf("the specified result must be a non nil value")
 	}
@@ -314,17 +801,15 @@ func generateCursor(result interface{}, paginatedField string, shouldSecondarySo
 	if err != nil {
 		return "", err
 	}
-	paginatedFieldValue := recordAsMap[paginatedField]
+	paginatedFieldValue := fieldValue(recordAsMap, fields[0])
 	if paginatedFieldValue == nil {
-		return "", fmt.Errorf("paginated field %s not found", paginatedField)
+		return "", fmt.Errorf("paginated field %s not found", fields[0])
 	}
 	// Set the cursor data
-	cursorData := make(bson.D, 0, 2)
-	cursorData = append(cursorData, bson.DocElem{Name: paginatedField, Value: paginatedFieldValue})
-	if shouldSecondarySortOnID {
-		// Get the value of the ID field
-		id := recordAsMap["_id"]
-		cursorData = append(cursorData, bson.DocElem{Name: "_id", Value: id})
+	cursorData := make(bson.D, 0, len(fields))
+	cursorData = append(cursorData, bson.DocElem{Name: fields[0], Value: paginatedFieldValue})
+	for _, field := range fields[1:] {
+		cursorData = append(cursorData, bson.DocElem{Name: field, Value: fieldValue(recordAsMap, field)})
 	}
 	// Encode the cursor data into a url safe string
 	cursor, err := encodeCursor(cursorData)
@@ -334,6 +819,28 @@ func generateCursor(result interface{}, paginatedField string, shouldSecondarySo
 	return cursor, nil
 }
 
+// fieldValue returns the value of the field of the document, walking the embedded documents of a
+// dotted field path such as metadata.updatedAt, or nil if the document doesn't hold the field.
+func fieldValue(doc map[string]interface{}, field string) interface{} {
+	if value, ok := doc[field]; ok || !strings.Contains(field, ".") {
+		return value
+	}
+	var value interface{} = doc
+	for _, key := range strings.Split(field, ".") {
+		switch embedded := value.(type) {
+		case map[string]interface{}:
+			value = embedded[key]
+		case bson.M:
+			value = embedded[key]
+		case bson.D:
+			value = embedded.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
 // encodeCursor encodes and returns cursor data that is url safe
 var encodeCursor = func(cursorData bson.D) (string, error) {
 	data, err := bson.Marshal(cursorData)
@@ -1,14 +1,19 @@
 package mgo
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	mcpbson "github.com/qlik-oss/mongocursorpagination/bson"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,8 +29,8 @@ func TestFind(t *testing.T) {
 		name               string
 		findParams         FindParams
 		results            interface{}
-		executeCountQuery  func(db MgoDb, collectionName string, queries []bson.M) (int, error)
-		executeCursorQuery func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error
+		executeCountQuery  func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error)
+		executeCursorQuery func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error
 		expectedCursor     Cursor
 		expectedErr        error
 	}{
@@ -38,6 +43,33 @@ func TestFind(t *testing.T) {
 			expectedCursor:     Cursor{},
 			expectedErr:        errors.New("results can't be nil"),
 		},
+		{
+			name:               "errors when results is not a pointer",
+			findParams:         FindParams{},
+			results:            []item{},
+			executeCountQuery:  nil,
+			executeCursorQuery: nil,
+			expectedCursor:     Cursor{},
+			expectedErr:        fmt.Errorf("%w, got []mgo.item", ErrInvalidResultsType),
+		},
+		{
+			name:               "errors when results is a pointer to a non slice",
+			findParams:         FindParams{},
+			results:            &item{},
+			executeCountQuery:  nil,
+			executeCursorQuery: nil,
+			expectedCursor:     Cursor{},
+			expectedErr:        fmt.Errorf("%w, got *mgo.item", ErrInvalidResultsType),
+		},
+		{
+			name:               "errors when results is a nil pointer",
+			findParams:         FindParams{},
+			results:            (*[]item)(nil),
+			executeCountQuery:  nil,
+			executeCursorQuery: nil,
+			expectedCursor:     Cursor{},
+			expectedErr:        fmt.Errorf("%w, got a nil *[]mgo.item", ErrInvalidResultsType),
+		},
 		{
 			name:               "errors when DB is nil",
 			findParams:         FindParams{},
@@ -72,7 +104,7 @@ func TestFind(t *testing.T) {
 			executeCountQuery:  nil,
 			executeCursorQuery: nil,
 			expectedCursor:     Cursor{},
-			expectedErr:        &CursorError{err: errors.New("next cursor parse failed: illegal base64 data at input byte 12")},
+			expectedErr:        &CursorError{err: fmt.Errorf("next cursor parse failed: %w", base64.CorruptInputError(12))},
 		},
 		{
 			name: "errors when previous cursor is bad",
@@ -86,7 +118,7 @@ func TestFind(t *testing.T) {
 			executeCountQuery:  nil,
 			executeCursorQuery: nil,
 			expectedCursor:     Cursor{},
-			expectedErr:        &CursorError{err: errors.New("previous cursor parse failed: illegal base64 data at input byte 12")},
+			expectedErr:        &CursorError{err: fmt.Errorf("previous cursor parse failed: %w", base64.CorruptInputError(12))},
 		},
 		{
 			name: "errors when executeCountQuery errors",
@@ -100,7 +132,7 @@ func TestFind(t *testing.T) {
 				CountTotal:     true,
 			},
 			results: &[]item{},
-			executeCountQuery: func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
+			executeCountQuery: func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
 				return 0, errors.New("error")
 			},
 			executeCursorQuery: nil,
@@ -119,10 +151,10 @@ func TestFind(t *testing.T) {
 				CountTotal:     true,
 			},
 			results: &[]item{},
-			executeCountQuery: func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
+			executeCountQuery: func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
 				return 2, nil
 			},
-			executeCursorQuery: func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+			executeCursorQuery: func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
 				return errors.New("error")
 			},
 			expectedCursor: Cursor{},
@@ -140,10 +172,10 @@ func TestFind(t *testing.T) {
 				CountTotal:     true,
 			},
 			results: &[]*item{},
-			executeCountQuery: func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
+			executeCountQuery: func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
 				return 3, nil
 			},
-			executeCursorQuery: func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+			executeCursorQuery: func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
 				resultv := reflect.ValueOf(results)
 				resultv.Elem().Set(reflect.ValueOf([]*item{
 					&item{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1", CreatedAt: time.Now()},
@@ -153,11 +185,15 @@ func TestFind(t *testing.T) {
 				return nil
 			},
 			expectedCursor: Cursor{
-				Previous:    "",
-				Next:        "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA",
-				HasPrevious: false,
-				HasNext:     true,
-				Count:       3,
+				Previous:       "",
+				Next:           "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA",
+				HasPrevious:    false,
+				HasNext:        true,
+				StartCursor:    "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+				EndCursor:      "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA",
+				Returned:       2,
+				EffectiveLimit: 2,
+				Count:          3,
 			},
 			expectedErr: nil,
 		},
@@ -174,10 +210,10 @@ func TestFind(t *testing.T) {
 				CountTotal:     true,
 			},
 			results: &[]item{},
-			executeCountQuery: func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
+			executeCountQuery: func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
 				return 2, nil
 			},
-			executeCursorQuery: func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+			executeCursorQuery: func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
 				resultv := reflect.ValueOf(results)
 				resultv.Elem().Set(reflect.ValueOf([]item{
 					{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1", CreatedAt: time.Now()},
@@ -186,11 +222,15 @@ func TestFind(t *testing.T) {
 				return nil
 			},
 			expectedCursor: Cursor{
-				Previous:    "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
-				Next:        "",
-				HasPrevious: true,
-				HasNext:     false,
-				Count:       2,
+				Previous:       "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+				Next:           "",
+				HasPrevious:    true,
+				HasNext:        false,
+				StartCursor:    "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+				EndCursor:      "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA",
+				Returned:       2,
+				EffectiveLimit: 2,
+				Count:          2,
 			},
 			expectedErr: nil,
 		},
@@ -207,10 +247,10 @@ func TestFind(t *testing.T) {
 				CountTotal:     true,
 			},
 			results: &[]item{},
-			executeCountQuery: func(db MgoDb, collectionName string, queries []bson.M) (int, error) {
+			executeCountQuery: func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
 				return 2, nil
 			},
-			executeCursorQuery: func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+			executeCursorQuery: func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
 				resultv := reflect.ValueOf(results)
 				resultv.Elem().Set(reflect.ValueOf([]item{
 					{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1", CreatedAt: time.Now()},
@@ -219,11 +259,15 @@ func TestFind(t *testing.T) {
 				return nil
 			},
 			expectedCursor: Cursor{
-				Previous:    "",
-				Next:        "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
-				HasPrevious: false,
-				HasNext:     true,
-				Count:       2,
+				Previous:       "",
+				Next:           "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+				HasPrevious:    false,
+				HasNext:        true,
+				StartCursor:    "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA",
+				EndCursor:      "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+				Returned:       2,
+				EffectiveLimit: 2,
+				Count:          2,
 			},
 			expectedErr: nil,
 		},
@@ -239,7 +283,7 @@ func TestFind(t *testing.T) {
 			},
 			results:           &[]item{},
 			executeCountQuery: nil,
-			executeCursorQuery: func(db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+			executeCursorQuery: func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
 				resultv := reflect.ValueOf(results)
 				resultv.Elem().Set(reflect.ValueOf([]item{
 					{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1", CreatedAt: time.Now()},
@@ -249,11 +293,15 @@ func TestFind(t *testing.T) {
 				return nil
 			},
 			expectedCursor: Cursor{
-				Previous:    "",
-				Next:        "FgAAAAdfaWQAKt31M-gVSd52lssEAA",
-				HasPrevious: false,
-				HasNext:     true,
-				Count:       0,
+				Previous:       "",
+				Next:           "FgAAAAdfaWQAKt31M-gVSd52lssEAA",
+				HasPrevious:    false,
+				HasNext:        true,
+				StartCursor:    "FgAAAAdfaWQAGt31M-gVSd52lssEAA",
+				EndCursor:      "FgAAAAdfaWQAKt31M-gVSd52lssEAA",
+				Returned:       2,
+				EffectiveLimit: 2,
+				Count:          0,
 			},
 			expectedErr: nil,
 		},
@@ -291,6 +339,528 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestFindStartAndEndCursorsMissingField(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	var page []item
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		*results.(*[]item) = append([]item{}, page...)
+		return nil
+	}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "userId",
+		Limit:          2,
+	}
+	withUser := item{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), UserID: "u1"}
+	withoutUser := item{ID: bson.ObjectIdHex("2addf533e81549de7696cb04")}
+
+	// The start cursor of a document missing the paginated field is left empty when it's not the
+	// previous cursor
+	page = []item{withoutUser, withUser, withUser}
+	var results []item
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Empty(t, cursor.StartCursor)
+	require.NotEmpty(t, cursor.EndCursor)
+	require.Equal(t, cursor.EndCursor, cursor.Next)
+
+	// And so is its end cursor when it's not the next cursor
+	page = []item{withUser, withoutUser}
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.NotEmpty(t, cursor.StartCursor)
+	require.Empty(t, cursor.EndCursor)
+
+	// Find fails when the cursor is the previous or next cursor
+	page = []item{withUser, withoutUser, withUser}
+	_, err = Find(p, &results)
+	require.EqualError(t, err, "could not create a next cursor: paginated field userId not found")
+}
+
+func TestFindWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		Limit:          2,
+	}
+
+	// The default queries return before using the DB
+	results := []item{{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "untouched"}}
+	_, err := FindWithContext(ctx, p, &results)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualError(t, err, "cursor query interrupted: context canceled")
+	require.Equal(t, []item{{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "untouched"}}, results)
+
+	p.CountTotal = true
+	_, err = FindWithContext(ctx, p, &results)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualError(t, err, "count query interrupted: context canceled")
+
+	// The context is passed to the queries
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	var queryCtx context.Context
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		queryCtx = ctx
+		return nil
+	}
+	p.CountTotal = false
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, context.Background(), queryCtx)
+}
+
+func TestFindDebug(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	var executed FindDebug
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		executed = FindDebug{Queries: query, Sort: sort, Limit: limit, Collation: collation}
+		return nil
+	}
+	collation := &mgo.Collation{Locale: "en"}
+	debug := &FindDebug{}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{"group": "a"},
+		PaginatedField: "name",
+		Collation:      collation,
+		Limit:          2,
+		Previous:       "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+		Debug:          debug,
+	}
+	_, err := Find(p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, FindDebug{
+		Queries: []bson.M{
+			{"group": "a"},
+			{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$gt": "test item 1"}},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item 1"}},
+					{"_id": map[string]interface{}{"$gt": bson.ObjectIdHex("1addf533e81549de7696cb04")}},
+				}},
+			}},
+		},
+		Sort:      []string{"name", "_id"},
+		Limit:     3,
+		Collation: collation,
+	}, *debug)
+	require.Equal(t, executed, *debug)
+}
+
+func TestFindDryRun(t *testing.T) {
+	executeCountQueryOri, executeCursorQueryOri := executeCountQuery, executeCursorQuery
+	defer func() {
+		executeCountQuery, executeCursorQuery = executeCountQueryOri, executeCursorQueryOri
+	}()
+	executeCountQuery = func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+		t.Fatal("the count query was executed")
+		return 0, nil
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		t.Fatal("the cursor query was executed")
+		return nil
+	}
+	debug := &FindDebug{}
+	p := FindParams{
+		CollectionName: "items",
+		Query:          bson.M{"group": "a"},
+		PaginatedField: "name",
+		Limit:          2,
+		SortAscending:  true,
+		Next:           "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA",
+		CountTotal:     true,
+		Debug:          debug,
+		DryRun:         true,
+	}
+
+	// The query is built from the cursor without a database
+	results := []item{{Name: "untouched"}}
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, Cursor{}, cursor)
+	require.Equal(t, []item{{Name: "untouched"}}, results)
+	require.Equal(t, FindDebug{
+		Queries: []bson.M{
+			{"group": "a"},
+			{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$gt": "test item 1"}},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item 1"}},
+					{"_id": map[string]interface{}{"$gt": bson.ObjectIdHex("1addf533e81549de7696cb04")}},
+				}},
+			}},
+		},
+		Sort:  []string{"name", "_id"},
+		Limit: 3,
+	}, *debug)
+
+	// The cursors are parsed
+	p.Next = "bad"
+	_, err = Find(p, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+
+	// The query is returned in the Debug
+	p.Next, p.Debug = "", nil
+	_, err = Find(p, &results)
+	require.Equal(t, ErrNilDebug, err)
+}
+
+func TestCursorJSON(t *testing.T) {
+	cursor := Cursor{
+		Previous:       "previous",
+		HasPrevious:    true,
+		StartCursor:    "previous",
+		EndCursor:      "end",
+		Returned:       2,
+		EffectiveLimit: 2,
+		Count:          3,
+	}
+	data, err := json.Marshal(cursor)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"previous": "previous",
+		"hasPrevious": true,
+		"hasNext": false,
+		"startCursor": "previous",
+		"endCursor": "end",
+		"returned": 2,
+		"effectiveLimit": 2,
+		"count": 3
+	}`, string(data))
+	var decoded Cursor
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, cursor, decoded)
+
+	// The BSON field names are unchanged
+	data, err = bson.Marshal(cursor)
+	require.NoError(t, err)
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(data, &doc))
+	require.Equal(t, true, doc["hasprevious"])
+	require.NotContains(t, doc, "hasPrevious")
+}
+
+func TestReverseCursor(t *testing.T) {
+	next := "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA"
+	require.Equal(t, next, ReverseCursor(next))
+
+	// The Next cursor passed as Previous queries the documents before its document
+	debug := &FindDebug{}
+	p := FindParams{
+		CollectionName: "items",
+		Query:          bson.M{"group": "a"},
+		PaginatedField: "name",
+		Limit:          2,
+		SortAscending:  true,
+		Previous:       ReverseCursor(next),
+		Debug:          debug,
+		DryRun:         true,
+	}
+	_, err := Find(p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, FindDebug{
+		Queries: []bson.M{
+			{"group": "a"},
+			{"$or": []map[string]interface{}{
+				{"name": map[string]interface{}{"$lt": "test item 1"}},
+				{"$and": []map[string]interface{}{
+					{"name": map[string]interface{}{"$eq": "test item 1"}},
+					{"_id": map[string]interface{}{"$lt": bson.ObjectIdHex("1addf533e81549de7696cb04")}},
+				}},
+			}},
+		},
+		Sort:  []string{"-name", "-_id"},
+		Limit: 3,
+	}, *debug)
+}
+
+func TestFindCountTotal(t *testing.T) {
+	executeCountQueryOri, executeCursorQueryOri := executeCountQuery, executeCursorQuery
+	defer func() {
+		executeCountQuery, executeCursorQuery = executeCountQueryOri, executeCursorQueryOri
+	}()
+	var countQueries [][]bson.M
+	executeCountQuery = func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+		countQueries = append(countQueries, queries)
+		return 350, nil
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		*results.(*[]item) = []item{
+			{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Name: "test item 1"},
+			{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Name: "test item 2"},
+			{ID: bson.ObjectIdHex("3addf533e81549de7696cb04"), Name: "test item 3"},
+		}
+		return nil
+	}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{"group": "a"},
+		PaginatedField: "name",
+		Limit:          2,
+		CountTotal:     true,
+	}
+
+	// The count is the total of the documents matching the query on every page, not the page size
+	var results []item
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, 350, cursor.Count)
+
+	p.Next = cursor.Next
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, 350, cursor.Count)
+	require.Equal(t, [][]bson.M{{p.Query}, {p.Query}}, countQueries)
+}
+
+func TestFindIDField(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	type document struct {
+		UID  string `bson:"uid"`
+		Name string `bson:"name"`
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		*results.(*[]document) = []document{{UID: "u1", Name: "a"}, {UID: "u2", Name: "a"}, {UID: "u3", Name: "b"}}
+		return nil
+	}
+	debug := &FindDebug{}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		IDField:        "uid",
+		Limit:          2,
+		SortAscending:  true,
+		Debug:          debug,
+	}
+
+	// Documents sharing the same name are secondarily sorted on the uid, which the cursors hold
+	var results []document
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "uid"}, debug.Sort)
+	cursorData, err := DecodeCursor(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Name: "name", Value: "a"}, {Name: "uid", Value: "u2"}}, cursorData)
+
+	p.Next = cursor.Next
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$or": []map[string]interface{}{
+		{"name": map[string]interface{}{"$gt": "a"}},
+		{"$and": []map[string]interface{}{
+			{"name": map[string]interface{}{"$eq": "a"}},
+			{"uid": map[string]interface{}{"$gt": "u2"}},
+		}},
+	}}, debug.Queries[1])
+
+	// Without a PaginatedField the documents are paginated on the uid only
+	p.PaginatedField, p.Next = "", ""
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"uid"}, debug.Sort)
+	cursorData, err = DecodeCursor(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Name: "uid", Value: "u2"}}, cursorData)
+
+	p.Next = cursor.Next
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"uid": map[string]interface{}{"$gt": "u2"}}, debug.Queries[1])
+}
+
+func TestFindNumericPaginatedField(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	// The documents store the sequence as int64, decoded into an int which the bson package
+	// encodes as int32 in the cursors
+	type event struct {
+		ID   bson.ObjectId `bson:"_id"`
+		Seq  int           `bson:"seq"`
+		Done bool          `bson:"done"`
+	}
+	events := []event{
+		{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Seq: 1},
+		{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Seq: 2, Done: true},
+		{ID: bson.ObjectIdHex("3addf533e81549de7696cb04"), Seq: 3, Done: true},
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		*results.(*[]event) = append([]event{}, events...)
+		return nil
+	}
+	debug := &FindDebug{}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "events",
+		Query:          bson.M{},
+		PaginatedField: "seq",
+		Limit:          2,
+		SortAscending:  true,
+		Debug:          debug,
+	}
+
+	var results []event
+	cursor, err := Find(p, &results)
+	require.NoError(t, err)
+	data, err := decodeCursorBytes(cursor.Next)
+	require.NoError(t, err)
+	var raw bson.Raw
+	require.NoError(t, bson.Unmarshal(data, &raw))
+	var elems []bson.RawDocElem
+	require.NoError(t, raw.Unmarshal(&elems))
+	require.Equal(t, byte(0x10), elems[0].Value.Kind)
+
+	// DecodeCursor returns the value as decoded, whereas it's compared as an int64, like the
+	// stored sequences
+	cursorData, err := DecodeCursor(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Name: "seq", Value: 2}, {Name: "_id", Value: events[1].ID}}, cursorData)
+	values, err := ParseCursorValues(cursor.Next, true)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(2), events[1].ID}, values)
+	p.Next = cursor.Next
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$or": []map[string]interface{}{
+		{"seq": map[string]interface{}{"$gt": int64(2)}},
+		{"$and": []map[string]interface{}{
+			{"seq": map[string]interface{}{"$eq": int64(2)}},
+			{"_id": map[string]interface{}{"$gt": events[1].ID}},
+		}},
+	}}, debug.Queries[1])
+
+	// Booleans are kept as is, false sorting before true
+	p.PaginatedField, p.Next = "done", ""
+	cursor, err = Find(p, &results)
+	require.NoError(t, err)
+	p.Next = cursor.Next
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$or": []map[string]interface{}{
+		{"done": map[string]interface{}{"$gt": true}},
+		{"$and": []map[string]interface{}{
+			{"done": map[string]interface{}{"$eq": true}},
+			{"_id": map[string]interface{}{"$gt": events[1].ID}},
+		}},
+	}}, debug.Queries[1])
+}
+
+func TestFindInCollection(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	coll := &mgo.Collection{Name: "items", FullName: "test.items"}
+	docs := []item{{ID: bson.NewObjectId(), Name: "a"}, {ID: bson.NewObjectId(), Name: "b"}, {ID: bson.NewObjectId(), Name: "c"}}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		// The queries are executed on the collection whatever the DB and CollectionName
+		require.Equal(t, "items", collectionName)
+		require.True(t, db.C(collectionName) == coll)
+		*results.(*[]item) = docs
+		return nil
+	}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "other",
+		Query:          bson.M{},
+		PaginatedField: "name",
+		Limit:          2,
+		SortAscending:  true,
+	}
+
+	var results []item
+	cursor, err := FindInCollection(coll, p, &results)
+	require.NoError(t, err)
+	require.Equal(t, docs[:2], results)
+	require.True(t, cursor.HasNext)
+	p.DB = nil
+	_, err = FindInCollection(coll, p, &results)
+	require.NoError(t, err)
+
+	_, err = FindInCollection(nil, p, &results)
+	require.True(t, errors.Is(err, ErrNilCollection))
+}
+
+func TestFindSentinelErrors(t *testing.T) {
+	p := FindParams{DB: &mgo.Database{}, CollectionName: "items", Limit: 2}
+	_, err := Find(p, nil)
+	require.True(t, errors.Is(err, ErrNilResults))
+	_, err = Find(FindParams{Limit: 2}, &[]item{})
+	require.True(t, errors.Is(err, ErrNilDB))
+	_, err = Find(FindParams{DB: &mgo.Database{}}, &[]item{})
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+
+	// The messages of the cursor errors are unchanged
+	p.Next = "XXXXXaGVsbG8="
+	_, err = Find(p, &[]item{})
+	require.True(t, errors.Is(err, ErrBadCursor))
+	require.EqualError(t, err, "next cursor parse failed: illegal base64 data at input byte 12")
+	require.False(t, errors.Is(err, ErrCursorShapeMismatch))
+
+	// The cursors generated for another sort are told apart
+	p.PaginatedField, p.Next = "name", "FgAAAAdfaWQAWt31M-gVSd52lssEAA"
+	_, err = Find(p, &[]item{})
+	require.True(t, errors.Is(err, ErrBadCursor))
+	require.True(t, errors.Is(err, ErrCursorShapeMismatch))
+	require.EqualError(t, err, "next cursor parse failed: expecting a cursor with two elements")
+	p.PaginatedField, p.Next = "", "LwAAAAJuYW1lAAoAAAB0ZXN0IGl0ZW0AAl9pZAANAAAAWt31M-gVSd52lssEAAA"
+	_, err = Find(p, &[]item{})
+	require.True(t, errors.Is(err, ErrCursorShapeMismatch))
+	require.EqualError(t, err, "next cursor parse failed: expecting a cursor with a single element")
+}
+
+func TestFindParamsValidate(t *testing.T) {
+	p := FindParams{DB: &mgo.Database{}, CollectionName: "items", Limit: 2}
+	require.NoError(t, p.Validate())
+	require.True(t, errors.Is(FindParams{Limit: 2}.Validate(), ErrNilDB))
+	require.True(t, errors.Is(FindParams{DB: &mgo.Database{}}.Validate(), ErrLimitTooSmall))
+	require.True(t, errors.Is(FindParams{Limit: 2, DryRun: true}.Validate(), ErrNilDebug))
+
+	// The errors are the ones Find returns
+	for _, tc := range []struct {
+		next, previous string
+	}{
+		{"XXXXXaGVsbG8=", ""},
+		{"", "XXXXXaGVsbG8="},
+		{"FgAAAAdfaWQAWt31M-gVSd52lssEAA", ""},
+	} {
+		p.PaginatedField, p.Next, p.Previous = "name", tc.next, tc.previous
+		err := p.Validate()
+		require.True(t, errors.Is(err, ErrBadCursor))
+		_, findErr := Find(p, &[]item{})
+		require.Equal(t, findErr, err)
+	}
+	p.Next = "FgAAAAdfaWQAWt31M-gVSd52lssEAA"
+	require.True(t, errors.Is(p.Validate(), ErrCursorShapeMismatch))
+	p.PaginatedField = ""
+	require.NoError(t, p.Validate())
+}
+
 func TestParseCursor(t *testing.T) {
 	var cases = []struct {
 		name                      string
@@ -325,14 +895,14 @@ func TestParseCursor(t *testing.T) {
 			"FgAAAAdfaWQAWt31M-gVSd52lssEAA",
 			true,
 			nil,
-			errors.New("expecting a cursor with two elements"),
+			&cursorShapeError{"expecting a cursor with two elements"},
 		},
 		{
 			"errors when expecting cursor with 1 elements and only 2 present",
 			"LwAAAAJuYW1lAAoAAAB0ZXN0IGl0ZW0AAl9pZAANAAAAWt31M-gVSd52lssEAAA",
 			false,
 			nil,
-			errors.New("expecting a cursor with a single element"),
+			&cursorShapeError{"expecting a cursor with a single element"},
 		},
 	}
 	for _, tc := range cases {
@@ -340,6 +910,10 @@ func TestParseCursor(t *testing.T) {
 			cursorFieldValues, err := parseCursor(tc.cursor, tc.shouldSecondarySortOnID)
 			require.Equal(t, tc.expectedCursorFieldValues, cursorFieldValues)
 			require.Equal(t, tc.expectedErr, err)
+
+			cursorFieldValues, err = ParseCursorValues(tc.cursor, tc.shouldSecondarySortOnID)
+			require.Equal(t, tc.expectedCursorFieldValues, cursorFieldValues)
+			require.Equal(t, tc.expectedErr, err)
 		})
 	}
 }
@@ -363,16 +937,50 @@ func TestDecodeCursor(t *testing.T) {
 			bson.D{bson.DocElem{Name: "_id", Value: bson.ObjectIdHex("5addf533e81549de7696cb04")}},
 			nil,
 		},
+		{
+			"decodes cursor data of the standard encoding",
+			"FgAAAAdfaWQAWt31M+gVSd52lssEAA==",
+			bson.D{bson.DocElem{Name: "_id", Value: bson.ObjectIdHex("5addf533e81549de7696cb04")}},
+			nil,
+		},
+		{
+			"decodes cursor data of the unpadded standard encoding",
+			"FgAAAAdfaWQAWt31M+gVSd52lssEAA",
+			bson.D{bson.DocElem{Name: "_id", Value: bson.ObjectIdHex("5addf533e81549de7696cb04")}},
+			nil,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			cursorData, err := decodeCursor(tc.cursor)
 			require.Equal(t, tc.expectedCursorData, cursorData)
 			require.Equal(t, tc.expectedErr, err)
+
+			cursorData, err = DecodeCursor(tc.cursor)
+			require.Equal(t, tc.expectedCursorData, cursorData)
+			require.Equal(t, tc.expectedErr, err)
 		})
 	}
 }
 
+func TestCursorForDocument(t *testing.T) {
+	// The cursor is the Next cursor Find generates for the same document
+	doc := item{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Name: "test item 2", CreatedAt: time.Now()}
+	cursor, err := CursorForDocument(doc, "name", true)
+	require.NoError(t, err)
+	require.Equal(t, "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMgAHX2lkACrd9TPoFUnedpbLBAA", cursor)
+
+	cursor, err = CursorForDocument(&doc, "_id", false)
+	require.NoError(t, err)
+	require.Equal(t, "FgAAAAdfaWQAKt31M-gVSd52lssEAA", cursor)
+	values, err := parseCursor(cursor, false)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{doc.ID}, values)
+
+	_, err = CursorForDocument(doc, "missing", true)
+	require.EqualError(t, err, "paginated field missing not found")
+}
+
 func TestGenerateCursor(t *testing.T) {
 	var cases = []struct {
 		name                    string
@@ -457,6 +1065,36 @@ func TestGenerateCursor(t *testing.T) {
 	}
 }
 
+func TestGenerateCursorDottedField(t *testing.T) {
+	type metadata struct {
+		UpdatedAt time.Time `bson:"updatedAt"`
+	}
+	type document struct {
+		ID       bson.ObjectId `bson:"_id"`
+		Metadata metadata      `bson:"metadata"`
+	}
+	updatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	id := bson.ObjectIdHex("1addf533e81549de7696cb04")
+	expected := bson.D{{Name: "metadata.updatedAt", Value: updatedAt}, {Name: "_id", Value: id}}
+
+	// The value is extracted from the nested struct and from the nested map alike
+	for _, result := range []interface{}{
+		document{ID: id, Metadata: metadata{UpdatedAt: updatedAt}},
+		bson.M{"_id": id, "metadata": bson.M{"updatedAt": updatedAt}},
+	} {
+		cursor, err := generateCursor(result, "metadata.updatedAt", true)
+		require.NoError(t, err)
+		cursorData, err := decodeCursor(cursor)
+		require.NoError(t, err)
+		require.Equal(t, expected[0].Name, cursorData[0].Name)
+		require.True(t, updatedAt.Equal(cursorData[0].Value.(time.Time)))
+		require.Equal(t, expected[1], cursorData[1])
+	}
+
+	_, err := generateCursor(document{ID: id}, "metadata.createdAt", true)
+	require.EqualError(t, err, "paginated field metadata.createdAt not found")
+}
+
 func TestEncodeCursorCursor(t *testing.T) {
 	var cases = []struct {
 		name           string
@@ -480,3 +1118,140 @@ func TestEncodeCursorCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestFindSecondaryPaginatedField(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	type task struct {
+		ID       bson.ObjectId `bson:"_id"`
+		Priority int           `bson:"priority"`
+		DueDate  time.Time     `bson:"dueDate"`
+	}
+	dueDate := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tasks := []task{
+		{ID: bson.ObjectIdHex("1addf533e81549de7696cb04"), Priority: 1, DueDate: dueDate},
+		{ID: bson.ObjectIdHex("2addf533e81549de7696cb04"), Priority: 1, DueDate: dueDate.Add(time.Hour)},
+		{ID: bson.ObjectIdHex("3addf533e81549de7696cb04"), Priority: 2, DueDate: dueDate},
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		*results.(*[]task) = append([]task{}, tasks...)
+		return nil
+	}
+	ascending, descending := true, false
+	cases := []struct {
+		name                   string
+		sortAscending          bool
+		secondarySortAscending *bool
+		nextSort               []string
+		nextOps                []string
+	}{
+		{"both ascending", true, nil, []string{"priority", "dueDate", "_id"}, []string{"$gt", "$gt", "$gt"}},
+		{"both descending", false, &descending, []string{"-priority", "-dueDate", "-_id"}, []string{"$lt", "$lt", "$lt"}},
+		{"mixed", true, &descending, []string{"priority", "-dueDate", "_id"}, []string{"$gt", "$lt", "$gt"}},
+		{"mixed descending", false, &ascending, []string{"-priority", "dueDate", "-_id"}, []string{"$lt", "$gt", "$lt"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			debug := &FindDebug{}
+			p := FindParams{
+				DB:                      &mgo.Database{},
+				CollectionName:          "tasks",
+				Query:                   bson.M{},
+				PaginatedField:          "priority",
+				SecondaryPaginatedField: "dueDate",
+				SecondarySortAscending:  tc.secondarySortAscending,
+				SortAscending:           tc.sortAscending,
+				Limit:                   2,
+				Debug:                   debug,
+			}
+
+			// The cursors hold both fields before the _id
+			var results []task
+			cursor, err := Find(p, &results)
+			require.NoError(t, err)
+			require.Equal(t, tc.nextSort, debug.Sort)
+			cursorData, err := DecodeCursor(cursor.Next)
+			require.NoError(t, err)
+			require.Equal(t, bson.D{
+				{Name: "priority", Value: 1},
+				{Name: "dueDate", Value: dueDate.Add(time.Hour)},
+				{Name: "_id", Value: tasks[1].ID},
+			}, cursorData)
+
+			// The keyset query compares the three fields, each in its own direction
+			values := []interface{}{int64(1), dueDate.Add(time.Hour), tasks[1].ID}
+			fields := []string{"priority", "dueDate", "_id"}
+			p.Next = cursor.Next
+			_, err = Find(p, &results)
+			require.NoError(t, err)
+			expected, err := mcpbson.GenerateCompoundCursorQuery(fields, tc.nextOps, values)
+			require.NoError(t, err)
+			require.Equal(t, []bson.M{{}, expected}, debug.Queries)
+			require.Len(t, expected["$or"], 3)
+
+			// The previous page is queried in the reverse order of each field
+			p.Next, p.Previous = "", cursor.Next
+			_, err = Find(p, &results)
+			require.NoError(t, err)
+			previousOps := make([]string, len(tc.nextOps))
+			previousSort := make([]string, len(tc.nextSort))
+			for i := range tc.nextOps {
+				previousOps[i] = map[string]string{"$gt": "$lt", "$lt": "$gt"}[tc.nextOps[i]]
+				previousSort[i] = strings.TrimPrefix(tc.nextSort[i], "-")
+				if previousSort[i] == tc.nextSort[i] {
+					previousSort[i] = "-" + previousSort[i]
+				}
+			}
+			expected, err = mcpbson.GenerateCompoundCursorQuery(fields, previousOps, values)
+			require.NoError(t, err)
+			require.Equal(t, []bson.M{{}, expected}, debug.Queries)
+			require.Equal(t, previousSort, debug.Sort)
+
+			// A cursor of the single field mode is rejected
+			p.Previous = "LAAAAAJuYW1lAAwAAAB0ZXN0IGl0ZW0gMQAHX2lkABrd9TPoFUnedpbLBAA"
+			_, err = Find(p, &results)
+			require.EqualError(t, err, "previous cursor parse failed: expecting a cursor with three elements")
+		})
+	}
+}
+
+func TestFindUnknownPaginatedField(t *testing.T) {
+	executeCursorQueryOri := executeCursorQuery
+	defer func() {
+		executeCursorQuery = executeCursorQueryOri
+	}()
+	queried := false
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		queried = true
+		return nil
+	}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		PaginatedField: "nmae",
+		Limit:          2,
+	}
+
+	// The field isn't a field of item, so no query is run
+	var results []item
+	_, err := Find(p, &results)
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+	require.EqualError(t, err, "the paginated field isn't a field of the results, got nmae for mgo.item")
+	require.False(t, queried)
+
+	// Nor is a path into a field that isn't a document
+	p.PaginatedField = "createdAt.day"
+	_, err = Find(p, &results)
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+	require.False(t, queried)
+
+	// The fields of maps are dynamic
+	p.PaginatedField = "nmae"
+	var docs []bson.M
+	_, err = Find(p, &docs)
+	require.NoError(t, err)
+	require.True(t, queried)
+}
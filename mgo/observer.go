@@ -0,0 +1,14 @@
+package mgo
+
+import "time"
+
+// Observer is notified of the queries of Find, e.g. to record them in Prometheus histograms. Its
+// methods are called synchronously by Find, and must be safe for concurrent use when the
+// FindParams are shared across goroutines.
+type Observer interface {
+	// ObserveCount is called with the duration of the count query of a Find with CountTotal
+	ObserveCount(d time.Duration)
+	// ObserveFind is called with the duration of the find query and the number of documents of
+	// the page, without the additional document queried to see if there's another page
+	ObserveFind(d time.Duration, returned int)
+}
@@ -0,0 +1,83 @@
+package mgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver records the observations of the queries.
+type recordingObserver struct {
+	counts   []time.Duration
+	finds    []time.Duration
+	returned []int
+}
+
+func (o *recordingObserver) ObserveCount(d time.Duration) {
+	o.counts = append(o.counts, d)
+}
+
+func (o *recordingObserver) ObserveFind(d time.Duration, returned int) {
+	o.finds = append(o.finds, d)
+	o.returned = append(o.returned, returned)
+}
+
+func TestFindObserver(t *testing.T) {
+	executeCountQueryOri, executeCursorQueryOri := executeCountQuery, executeCursorQuery
+	defer func() {
+		executeCountQuery, executeCursorQuery = executeCountQueryOri, executeCursorQueryOri
+	}()
+	var cursorErr error
+	executeCountQuery = func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+		time.Sleep(2 * time.Millisecond)
+		return 3, nil
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		time.Sleep(time.Millisecond)
+		*results.(*[]item) = []item{{ID: bson.NewObjectId(), Name: "a"}, {ID: bson.NewObjectId(), Name: "b"}, {ID: bson.NewObjectId(), Name: "c"}}
+		return cursorErr
+	}
+	observer := &recordingObserver{}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		Limit:          2,
+		CountTotal:     true,
+		Observer:       observer,
+	}
+
+	// The page size leaves out the additional document
+	var results []item
+	_, err := Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, observer.counts, 1)
+	require.GreaterOrEqual(t, observer.counts[0], 2*time.Millisecond)
+	require.Len(t, observer.finds, 1)
+	require.GreaterOrEqual(t, observer.finds[0], time.Millisecond)
+	require.Equal(t, []int{2}, observer.returned)
+
+	p.Limit, p.CountTotal = 5, false
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, observer.counts, 1)
+	require.Equal(t, []int{2, 3}, observer.returned)
+
+	// Failed queries aren't observed
+	cursorErr = errors.New("failed")
+	_, err = Find(p, &results)
+	require.Error(t, err)
+	require.Len(t, observer.finds, 2)
+
+	// No observer is called without one
+	p.Observer = nil
+	cursorErr = nil
+	_, err = Find(p, &results)
+	require.NoError(t, err)
+	require.Len(t, observer.finds, 2)
+}
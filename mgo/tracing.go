@@ -0,0 +1,47 @@
+package mgo
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/qlik-oss/mongocursorpagination/mgo"
+
+// The names of the spans of the queries of Find
+const (
+	countSpanName  = "mongocursorpagination.count"
+	cursorSpanName = "mongocursorpagination.find"
+)
+
+// traced runs the query in a child span of the span carried by the FindParams' Context, created
+// with the tracer provider of that span. It's a no-op when the Context carries no span. The span
+// records the collection name, the limit and whether a Next or Previous cursor was used, along
+// with the error of the query if it failed.
+func traced(p FindParams, name string, query func(ctx context.Context) error) error {
+	parent := trace.SpanFromContext(p.Context)
+	if !parent.SpanContext().IsValid() {
+		return query(p.Context)
+	}
+	tracer := parent.TracerProvider().Tracer(tracerName)
+	ctx, span := tracer.Start(p.Context, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.mongodb.collection", p.CollectionName),
+			attribute.Int("mongocursorpagination.limit", p.Limit),
+			attribute.Bool("mongocursorpagination.next", p.Next != ""),
+			attribute.Bool("mongocursorpagination.previous", p.Previous != ""),
+		),
+	)
+	defer span.End()
+
+	err := query(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package mgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingSpan is a span recording its attributes, status and whether it ended, the rest of the
+// span being a no-op.
+type recordingSpan struct {
+	trace.Span
+	provider   *recordingProvider
+	name       string
+	attributes []attribute.KeyValue
+	status     codes.Code
+	errs       []error
+	ended      bool
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+func (s *recordingSpan) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+func (s *recordingSpan) SetStatus(code codes.Code, _ string)           { s.status = code }
+func (s *recordingSpan) TracerProvider() trace.TracerProvider          { return s.provider }
+
+func (s *recordingSpan) SpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}})
+}
+
+// recordingProvider is a tracer provider whose tracers record the spans they start.
+type recordingProvider struct {
+	trace.TracerProvider
+	spans []*recordingSpan
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return p }
+
+func (p *recordingProvider) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	span := &recordingSpan{
+		Span:       trace.SpanFromContext(context.Background()),
+		provider:   p,
+		name:       name,
+		attributes: config.Attributes(),
+	}
+	p.spans = append(p.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func TestFindTracing(t *testing.T) {
+	executeCountQueryOri, executeCursorQueryOri := executeCountQuery, executeCursorQuery
+	defer func() {
+		executeCountQuery, executeCursorQuery = executeCountQueryOri, executeCursorQueryOri
+	}()
+	provider := &recordingProvider{}
+	var queryCtxs []context.Context
+	executeCountQuery = func(ctx context.Context, db MgoDb, collectionName string, queries []bson.M) (int, error) {
+		queryCtxs = append(queryCtxs, ctx)
+		return 1, nil
+	}
+	executeCursorQuery = func(ctx context.Context, db MgoDb, collectionName string, query []bson.M, sort []string, limit int, collation *mgo.Collation, results interface{}) error {
+		queryCtxs = append(queryCtxs, ctx)
+		return errors.New("cursor error")
+	}
+
+	// The queries are traced in child spans of the span of the context
+	parent := &recordingSpan{Span: trace.SpanFromContext(context.Background()), provider: provider}
+	p := FindParams{
+		DB:             &mgo.Database{},
+		CollectionName: "items",
+		Query:          bson.M{},
+		Limit:          2,
+		CountTotal:     true,
+		Next:           "FgAAAAdfaWQAWt31M-gVSd52lssEAA",
+		Context:        trace.ContextWithSpan(context.Background(), parent),
+	}
+	_, err := Find(p, &[]item{})
+	require.EqualError(t, err, "cursor error")
+	require.Len(t, provider.spans, 2)
+	expectedAttributes := []attribute.KeyValue{
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.mongodb.collection", "items"),
+		attribute.Int("mongocursorpagination.limit", 2),
+		attribute.Bool("mongocursorpagination.next", true),
+		attribute.Bool("mongocursorpagination.previous", false),
+	}
+	for i, name := range []string{countSpanName, cursorSpanName} {
+		span := provider.spans[i]
+		require.Equal(t, name, span.name)
+		require.Equal(t, expectedAttributes, span.attributes)
+		require.True(t, span.ended)
+		require.Equal(t, span, trace.SpanFromContext(queryCtxs[i]))
+	}
+	require.Equal(t, codes.Unset, provider.spans[0].status)
+	require.Empty(t, provider.spans[0].errs)
+	require.Equal(t, codes.Error, provider.spans[1].status)
+	require.Equal(t, []error{errors.New("cursor error")}, provider.spans[1].errs)
+
+	// Nothing is traced without a span in the context
+	provider.spans = nil
+	p.Context = context.Background()
+	_, err = Find(p, &[]item{})
+	require.EqualError(t, err, "cursor error")
+	require.Empty(t, provider.spans)
+}
@@ -0,0 +1,36 @@
+package mgo
+
+import "net/url"
+
+// The names of the URL query parameters holding the cursors
+const (
+	NextQueryParam     = "next"
+	PreviousQueryParam = "previous"
+)
+
+// AppendCursorToURL sets the next and previous query parameters of the URL to the Next and
+// Previous cursors of the Cursor, e.g. to build the links to the pages adjacent to the one returned
+// by Find. A parameter whose cursor is empty is removed, so the parameters the URL already had are
+// always replaced.
+func AppendCursorToURL(u *url.URL, cursor Cursor) {
+	query := u.Query()
+	setQueryParam(query, NextQueryParam, cursor.Next)
+	setQueryParam(query, PreviousQueryParam, cursor.Previous)
+	u.RawQuery = query.Encode()
+}
+
+// ParseCursorFromURL returns the next and previous cursors held by the query parameters of the
+// URL, e.g. to set the Next and Previous of the FindParams of the page a request asks for. A
+// missing parameter yields an empty cursor.
+func ParseCursorFromURL(u *url.URL) (next, previous string) {
+	query := u.Query()
+	return query.Get(NextQueryParam), query.Get(PreviousQueryParam)
+}
+
+func setQueryParam(query url.Values, name, value string) {
+	if value == "" {
+		query.Del(name)
+		return
+	}
+	query.Set(name, value)
+}
@@ -0,0 +1,28 @@
+package mgo
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendCursorToURL(t *testing.T) {
+	u, err := url.Parse("https://example.com/items?limit=2&next=stale&previous=stale")
+	require.NoError(t, err)
+
+	// The cursors replace the parameters the URL already had, the other ones are kept
+	AppendCursorToURL(u, Cursor{Next: "bmV4dA", Previous: "cHJl+dmlvdXM="})
+	require.Equal(t, "2", u.Query().Get("limit"))
+	next, previous := ParseCursorFromURL(u)
+	require.Equal(t, "bmV4dA", next)
+	require.Equal(t, "cHJl+dmlvdXM=", previous)
+	require.Equal(t, []string{"bmV4dA"}, u.Query()["next"])
+
+	// An empty cursor removes its parameter
+	AppendCursorToURL(u, Cursor{Next: "bmV4dA"})
+	require.Equal(t, "https://example.com/items?limit=2&next=bmV4dA", u.String())
+	next, previous = ParseCursorFromURL(u)
+	require.Equal(t, "bmV4dA", next)
+	require.Empty(t, previous)
+}
@@ -0,0 +1,353 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// AggregateCollection is a collection on which aggregation pipelines can be run.
+	AggregateCollection interface {
+		Aggregate(context.Context, interface{}, ...*options.AggregateOptions) (MongoCursor, error)
+	}
+
+	// AggregateParams holds the parameters to be used in a paginated aggregate mongo query that
+	// will return a Cursor.
+	AggregateParams struct {
+		Collection AggregateCollection
+
+		// The aggregation pipeline whose output is paginated. The pagination stages are appended to
+		// it, so the paginated field may be computed by the pipeline, e.g. a rank computed by a
+		// $setWindowFields stage. A computed paginated field must be deterministic: a document must
+		// get the same value on every page query, e.g. by sorting the window on a unique field, or
+		// documents could be skipped or repeated across pages
+		Pipeline []bson.M
+		// The number of results to fetch, should be > 0
+		Limit int64
+		// true, if the results should be sort ascending, false otherwise
+		SortAscending bool
+		// The name of the field of the pipeline output being paginated and sorted on. Documents
+		// sharing the same value are secondarily ordered by the _id
+		PaginatedField string
+		Collation      *options.Collation
+		// The value to start querying the page
+		Next string
+		// The value to start querying previous page
+		Previous string
+		// An optional $project, $addFields or $set stage transforming the documents of the page,
+		// e.g. bson.M{"$addFields": bson.M{"fullName": bson.M{"$concat": bson.A{"$first", " ", "$last"}}}}.
+		// It is applied after the pagination stages. The paginated field and the _id are always
+		// kept by a $project stage since the cursors are generated from them, and they may not be
+		// overwritten by computed values
+		ProjectStage bson.M
+		// When true, the paginated field holds numbers as strings, e.g. "9" and "10", which are
+		// sorted numerically rather than lexicographically. The documents are sorted on the number
+		// converted by a $convert expression, strings which aren't numbers being sorted first, and the
+		// cursors hold the number
+		NumericStringField bool
+		// The field whose value is sorted on for the documents where the paginated field is null or
+		// missing, e.g. "createdAt" to sort documents without an "updatedAt" on their creation time.
+		// The documents are sorted on the value coalesced by an $ifNull expression, which the
+		// cursors hold. FallbackField is only supported by Aggregate, since the coalesced value is
+		// computed by an aggregation stage
+		FallbackField string
+		// Whether or not to include total count of documents output by the pipeline in the cursor
+		// Specifying true runs an additional aggregation, the pipeline followed by a $count stage
+		CountTotal bool
+		// When true, the stages of the aggregations may write temporary files, e.g. when the $sort
+		// stage exceeds the memory limit of in-memory sorts
+		AllowDiskUse bool
+	}
+)
+
+const (
+	// numericKeyField is the field added to the documents of a NumericStringField pagination,
+	// holding the number converted from the paginated field. It is removed from the results.
+	numericKeyField = "_numericKey"
+	// fallbackKeyField is the field added to the documents of a FallbackField pagination, holding
+	// the paginated field coalesced with the fallback field. It is removed from the results.
+	fallbackKeyField = "_fallbackKey"
+)
+
+// Aggregate executes an aggregate mongo query by using the provided AggregateParams, fills the
+// passed in result slice pointer and returns a Cursor.
+func Aggregate(ctx context.Context, p AggregateParams, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+
+	if p.Collection == nil {
+		return Cursor{}, ErrNilCollection
+	}
+
+	if p.Limit <= 0 {
+		return Cursor{}, ErrLimitTooSmall
+	}
+
+	fp, err := withParsedCursor(ensureDefaults(p.findParams()))
+	if err != nil {
+		return Cursor{}, err
+	}
+	cursorQuery, sort, err := cursorQueryAndSort(fp)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	// Append the pagination stages, getting an additional document to see if there's another page
+	pipeline := make([]bson.M, 0, len(p.Pipeline)+5)
+	pipeline = append(pipeline, p.Pipeline...)
+	keyField, keyExpression := p.sortKey()
+	if keyField != "" {
+		pipeline = append(pipeline, bson.M{"$addFields": bson.M{keyField: keyExpression}})
+	}
+	if cursorQuery != nil {
+		pipeline = append(pipeline, bson.M{"$match": cursorQuery})
+	}
+	pipeline = append(pipeline, bson.M{"$sort": sort}, bson.M{"$limit": lookaheadLimit(fp.Limit)})
+	if p.ProjectStage != nil {
+		stage, err := projectStage(p.ProjectStage, sortFields(fp))
+		if err != nil {
+			return Cursor{}, err
+		}
+		pipeline = append(pipeline, stage)
+	}
+
+	opts := options.Aggregate()
+	if fp.Collation != nil {
+		opts.SetCollation(fp.Collation)
+	}
+	if p.AllowDiskUse {
+		opts.SetAllowDiskUse(true)
+	}
+
+	// Compute total count of documents output by the pipeline - only computed if CountTotal is True
+	var count int
+	if p.CountTotal {
+		count, err = executeAggregateCountQuery(ctx, p.Collection, p.Pipeline, opts)
+		if err != nil {
+			return Cursor{}, err
+		}
+	}
+
+	cursor, err := p.Collection.Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if keyField == "" {
+		if err := cursor.All(ctx, results); err != nil {
+			return Cursor{}, err
+		}
+		paged, err := pageCursor(fp, results, int(fp.Limit))
+		if err != nil {
+			return Cursor{}, err
+		}
+		paged.Count = count
+		return paged, nil
+	}
+
+	// Generate the cursors from the sort key before removing it from the results
+	var raws []bson.Raw
+	if err := cursor.All(ctx, &raws); err != nil {
+		return Cursor{}, err
+	}
+	paged, err := pageCursor(fp, &raws, int(fp.Limit))
+	if err != nil {
+		return Cursor{}, err
+	}
+	if err := decodeWithout(raws, []string{keyField}, results); err != nil {
+		return Cursor{}, err
+	}
+	paged.Count = count
+	return paged, nil
+}
+
+// executeAggregateCountQuery counts the documents output by a clone of the pipeline followed by a
+// $count stage.
+func executeAggregateCountQuery(ctx context.Context, c AggregateCollection, pipeline []bson.M, opts *options.AggregateOptions) (int, error) {
+	countPipeline := make([]bson.M, 0, len(pipeline)+1)
+	countPipeline = append(countPipeline, pipeline...)
+	countPipeline = append(countPipeline, bson.M{"$count": "count"})
+	cursor, err := c.Aggregate(ctx, countPipeline, opts)
+	if err != nil {
+		return 0, err
+	}
+	var counts []struct {
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return 0, err
+	}
+	// No document is output when the pipeline outputs no document
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].Count, nil
+}
+
+// decodeWithout decodes the documents without the specified fields into the results.
+func decodeWithout(raws []bson.Raw, fields []string, results interface{}) error {
+	resultsVal := reflect.ValueOf(results).Elem()
+	elemType := resultsVal.Type().Elem()
+	decoded := reflect.MakeSlice(resultsVal.Type(), 0, len(raws))
+	for _, raw := range raws {
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return err
+		}
+		kept := make(bson.D, 0, len(doc))
+		for _, e := range doc {
+			if !contains(fields, e.Key) {
+				kept = append(kept, e)
+			}
+		}
+		data, err := bson.Marshal(kept)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(elemType)
+		if err := bson.Unmarshal(data, elem.Interface()); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, elem.Elem())
+	}
+	resultsVal.Set(decoded)
+	return nil
+}
+
+// projectStage returns the project stage keeping the sorted fields, from which the cursors are
+// generated.
+func projectStage(stage bson.M, fields []string) (bson.M, error) {
+	if len(stage) != 1 {
+		return nil, errors.New("the project stage must have a single $project, $addFields or $set field")
+	}
+	for name, value := range stage {
+		spec, ok := value.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("the %s project stage must be a bson.M, got %T", name, value)
+		}
+		switch name {
+		case "$project":
+			kept, err := projectKeepingFields(spec, fields)
+			if err != nil {
+				return nil, err
+			}
+			return bson.M{name: kept}, nil
+		case "$addFields", "$set":
+			for _, field := range fields {
+				if _, ok := spec[field]; ok {
+					return nil, fmt.Errorf("the %s project stage can't overwrite the sorted field %s", name, field)
+				}
+			}
+			return stage, nil
+		default:
+			return nil, fmt.Errorf("unsupported project stage %s", name)
+		}
+	}
+	return stage, nil
+}
+
+// projectKeepingFields returns a copy of the $project specification which keeps the fields: they
+// are included by an inclusion projection and not excluded by an exclusion projection.
+func projectKeepingFields(spec bson.M, fields []string) (bson.M, error) {
+	kept := make(bson.M, len(spec)+len(fields))
+	inclusion := false
+	for field, value := range spec {
+		kept[field] = value
+		if field != "_id" && !isExclusion(value) {
+			inclusion = true
+		}
+	}
+	for _, field := range fields {
+		if !inclusion {
+			delete(kept, field)
+			continue
+		}
+		if value, ok := kept[field]; ok && !isExclusion(value) && !isInclusion(value) {
+			return nil, fmt.Errorf("the $project project stage can't overwrite the sorted field %s", field)
+		}
+		kept[field] = 1
+	}
+	return kept, nil
+}
+
+// isExclusion returns true if the value of a $project field excludes the field.
+func isExclusion(value interface{}) bool {
+	flag, ok := projectFlag(value)
+	return ok && !flag
+}
+
+// isInclusion returns true if the value of a $project field includes the field as is.
+func isInclusion(value interface{}) bool {
+	flag, ok := projectFlag(value)
+	return ok && flag
+}
+
+// projectFlag returns whether the boolean or numeric value of a $project field includes the field,
+// false if the value is an expression.
+func projectFlag(value interface{}) (include bool, ok bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case int:
+		return v != 0, true
+	case int32:
+		return v != 0, true
+	case int64:
+		return v != 0, true
+	case float64:
+		return v != 0, true
+	}
+	return false, false
+}
+
+// sortKey returns the field added to the documents to sort them on a value computed from the
+// paginated field, along with its expression, or an empty field if the paginated field is sorted
+// on as is.
+func (p AggregateParams) sortKey() (string, interface{}) {
+	var input interface{} = "$" + p.PaginatedField
+	if p.FallbackField != "" {
+		input = bson.M{"$ifNull": bson.A{input, "$" + p.FallbackField}}
+	}
+	switch {
+	case p.NumericStringField:
+		return numericKeyField, bson.M{"$convert": bson.M{
+			"input":   input,
+			"to":      "double",
+			"onError": nil,
+			"onNull":  nil,
+		}}
+	case p.FallbackField != "":
+		return fallbackKeyField, input
+	}
+	return "", nil
+}
+
+// findParams returns the FindParams paginating like the AggregateParams.
+func (p AggregateParams) findParams() FindParams {
+	if keyField, _ := p.sortKey(); keyField != "" {
+		return FindParams{
+			Limit:           p.Limit,
+			SortAscending:   p.SortAscending,
+			PaginatedFields: []SortField{{Name: keyField, Ascending: p.SortAscending}},
+			Collation:       p.Collation,
+			Next:            p.Next,
+			Previous:        p.Previous,
+		}
+	}
+	return FindParams{
+		Limit:          p.Limit,
+		SortAscending:  p.SortAscending,
+		PaginatedField: p.PaginatedField,
+		Collation:      p.Collation,
+		Next:           p.Next,
+		Previous:       p.Previous,
+	}
+}
@@ -0,0 +1,339 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type player struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  string             `bson:"name"`
+	Score int                `bson:"score"`
+	Rank  int                `bson:"rank"`
+}
+
+func TestAggregateByWindowRank(t *testing.T) {
+	b := player{ID: primitive.NewObjectID(), Name: "b", Score: 30, Rank: 1}
+	d := player{ID: primitive.NewObjectID(), Name: "d", Score: 30, Rank: 1}
+	c := player{ID: primitive.NewObjectID(), Name: "c", Score: 20, Rank: 3}
+	col := newFakeCollection(t, b, d, c)
+	windowStage := bson.M{"$setWindowFields": bson.M{
+		"sortBy": bson.M{"score": -1},
+		"output": bson.M{"rank": bson.M{"$rank": bson.M{}}},
+	}}
+	p := AggregateParams{
+		Collection:     col,
+		Pipeline:       []bson.M{windowStage},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "rank",
+	}
+
+	// The pagination stages are appended to the pipeline computing the rank
+	var page []player
+	cursor, err := Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []player{b, d}, page)
+	require.True(t, cursor.HasNext)
+	requireStages(t, []bson.D{
+		stage(t, "$setWindowFields", windowStage["$setWindowFields"]),
+		{{Key: "$sort", Value: bson.D{{Key: "rank", Value: int32(1)}, {Key: "_id", Value: int32(1)}}}},
+		{{Key: "$limit", Value: int64(3)}},
+	}, col.pipelines[0])
+
+	// The pages of the cursors match the documents from the rank and the _id, players sharing a
+	// rank being ordered by _id
+	p.Next = cursor.Next
+	col.queuePage(t, c)
+	cursor, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []player{c}, page)
+	require.False(t, cursor.HasNext)
+	requireStages(t, []bson.D{
+		stage(t, "$setWindowFields", windowStage["$setWindowFields"]),
+		stage(t, "$match", pageCursorQuery(t, p.findParams())),
+		{{Key: "$sort", Value: bson.D{{Key: "rank", Value: int32(1)}, {Key: "_id", Value: int32(1)}}}},
+		{{Key: "$limit", Value: int64(3)}},
+	}, col.pipelines[1])
+
+	// The previous pages are sorted the other way around and returned in the order of the sort
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, d, b)
+	cursor, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []player{b, d}, page)
+	require.False(t, cursor.HasPrevious)
+	last := col.pipelines[len(col.pipelines)-1]
+	require.Len(t, last, 4)
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, p.findParams())), last[1])
+	require.Equal(t, bson.D{{Key: "$sort", Value: bson.D{{Key: "rank", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}}}}, last[2])
+}
+
+func TestAggregateAllowDiskUse(t *testing.T) {
+	col := newFakeCollection(t)
+	for _, name := range []string{"a", "b", "c"} {
+		col.insert(t, player{ID: primitive.NewObjectID(), Name: name})
+	}
+	p := AggregateParams{
+		Collection:     col,
+		Pipeline:       []bson.M{},
+		Limit:          2,
+		PaginatedField: "name",
+		CountTotal:     true,
+		AllowDiskUse:   true,
+	}
+	var page []player
+	_, err := Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	// The count and page aggregations
+	require.Len(t, col.aggOptions, 2)
+	for _, opts := range col.aggOptions {
+		require.True(t, *opts.AllowDiskUse)
+	}
+
+	p.AllowDiskUse = false
+	_, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Nil(t, col.aggOptions[2].AllowDiskUse)
+}
+
+func TestAggregateErrors(t *testing.T) {
+	col := newFakeCollection(t)
+	var results []player
+	_, err := Aggregate(context.Background(), AggregateParams{Limit: 1}, &results)
+	require.EqualError(t, err, "Collection can't be nil")
+	_, err = Aggregate(context.Background(), AggregateParams{Collection: col}, &results)
+	require.EqualError(t, err, "a limit of at least 1 is required")
+	_, err = Aggregate(context.Background(), AggregateParams{Collection: col, Limit: 1}, results)
+	require.True(t, errors.Is(err, ErrInvalidResultsType))
+	_, err = Aggregate(context.Background(), AggregateParams{Collection: col, Limit: 1, Next: "not a cursor"}, &results)
+	var cursorErr *CursorError
+	require.True(t, errors.As(err, &cursorErr))
+}
+
+type person struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	First    string             `bson:"first,omitempty"`
+	Last     string             `bson:"last,omitempty"`
+	FullName string             `bson:"fullName"`
+}
+
+func TestAggregateProjectStage(t *testing.T) {
+	hopper := person{ID: primitive.NewObjectID(), First: "Grace", Last: "Hopper"}
+	lovelace := person{ID: primitive.NewObjectID(), First: "Ada", Last: "Lovelace"}
+	col := newFakeCollection(t, hopper, lovelace)
+	fullName := bson.M{"$concat": bson.A{"$first", " ", "$last"}}
+	for _, tc := range []struct {
+		name          string
+		projectStage  bson.M
+		expectedStage bson.M
+	}{
+		{
+			"computed field added to the documents",
+			bson.M{"$addFields": bson.M{"fullName": fullName}},
+			bson.M{"$addFields": bson.M{"fullName": fullName}},
+		},
+		{
+			"inclusion projection keeping the paginated field and the _id",
+			bson.M{"$project": bson.M{"_id": 0, "fullName": fullName}},
+			bson.M{"$project": bson.M{"_id": 1, "last": 1, "fullName": fullName}},
+		},
+		{
+			"exclusion projection keeping the paginated field",
+			bson.M{"$project": bson.M{"first": 0, "last": 0}},
+			bson.M{"$project": bson.M{"first": 0}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := AggregateParams{
+				Collection:     col,
+				Limit:          2,
+				SortAscending:  true,
+				PaginatedField: "last",
+				ProjectStage:   tc.projectStage,
+			}
+			var results []person
+			cursor, err := Aggregate(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.Equal(t, []person{hopper, lovelace}, results)
+
+			// The project stage follows the pagination stages, the kept paginated field and _id
+			// generating the cursors
+			pipeline := col.pipelines[len(col.pipelines)-1]
+			require.Len(t, pipeline, 3)
+			expected, err := toD(tc.expectedStage)
+			require.NoError(t, err)
+			sent, err := toM(pipeline[2])
+			require.NoError(t, err)
+			expectedM, err := toM(expected)
+			require.NoError(t, err)
+			require.Equal(t, expectedM, sent)
+			require.Equal(t, mustGenerateCursor(t, lovelace, []string{"last", "_id"}), cursor.EndCursor)
+		})
+	}
+}
+
+func TestAggregateProjectStageErrors(t *testing.T) {
+	col := newFakeCollection(t)
+	for _, tc := range []struct {
+		projectStage bson.M
+		expectedErr  string
+	}{
+		{bson.M{"$project": bson.M{}, "$set": bson.M{}}, "the project stage must have a single $project, $addFields or $set field"},
+		{bson.M{"$unwind": bson.M{"path": "$tags"}}, "unsupported project stage $unwind"},
+		{bson.M{"$set": "last"}, "the $set project stage must be a bson.M, got string"},
+		{bson.M{"$set": bson.M{"last": bson.M{"$toUpper": "$last"}}}, "the $set project stage can't overwrite the sorted field last"},
+		{bson.M{"$project": bson.M{"last": bson.M{"$toUpper": "$last"}}}, "the $project project stage can't overwrite the sorted field last"},
+	} {
+		p := AggregateParams{Collection: col, Limit: 2, PaginatedField: "last", ProjectStage: tc.projectStage}
+		var results []person
+		_, err := Aggregate(context.Background(), p, &results)
+		require.EqualError(t, err, tc.expectedErr)
+	}
+}
+
+type version struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Number string             `bson:"number"`
+}
+
+func TestAggregateNumericStringField(t *testing.T) {
+	type keyedVersion struct {
+		ID     primitive.ObjectID `bson:"_id"`
+		Number string             `bson:"number"`
+		Key    interface{}        `bson:"_numericKey"`
+	}
+	notNumber := keyedVersion{ID: primitive.NewObjectID(), Number: "n/a"}
+	nine := keyedVersion{ID: primitive.NewObjectID(), Number: "9", Key: float64(9)}
+	ten := keyedVersion{ID: primitive.NewObjectID(), Number: "10", Key: float64(10)}
+	col := newFakeCollection(t, notNumber, nine, ten)
+	p := AggregateParams{
+		Collection:         col,
+		Limit:              2,
+		SortAscending:      true,
+		PaginatedField:     "number",
+		NumericStringField: true,
+	}
+
+	// The documents are sorted on the number converted from the paginated field, strings which
+	// aren't numbers converting to null
+	var page []version
+	cursor, err := Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []version{{ID: notNumber.ID, Number: "n/a"}, {ID: nine.ID, Number: "9"}}, page)
+	requireStages(t, []bson.D{
+		stage(t, "$addFields", bson.M{numericKeyField: bson.M{"$convert": bson.M{"input": "$number", "to": "double", "onError": nil, "onNull": nil}}}),
+		{{Key: "$sort", Value: bson.D{{Key: numericKeyField, Value: int32(1)}, {Key: "_id", Value: int32(1)}}}},
+		{{Key: "$limit", Value: int64(3)}},
+	}, col.pipelines[0])
+
+	// The cursor holds the numeric value, the pages of the cursors matching the documents from it
+	values, err := parseCursor(ensureDefaults(p.findParams()), cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{float64(9), nine.ID}, values)
+	p.Next = cursor.Next
+	col.queuePage(t, ten)
+	_, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, p.findParams())), col.pipelines[1][1])
+
+	// The numeric key is removed from the results
+	var docs []bson.M
+	p.Next = ""
+	_, err = Aggregate(context.Background(), p, &docs)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"_id": notNumber.ID, "number": "n/a"}, docs[0])
+}
+
+func TestAggregateCountTotal(t *testing.T) {
+	col := newFakeCollection(t, player{ID: primitive.NewObjectID(), Name: "a", Score: 10})
+	p := AggregateParams{
+		Collection:     col,
+		Pipeline:       []bson.M{{"$match": bson.M{"score": bson.M{"$gte": 10}}}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	col.queueCount(3)
+	var page []player
+	cursor, err := Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+
+	// The count ignores the cursor boundary and leaves the pipeline untouched
+	p.Next = mustGenerateCursor(t, page[0], []string{"name", "_id"})
+	col.queueCount(3)
+	cursor, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.Len(t, p.Pipeline, 1)
+	countPipeline := col.pipelines[len(col.pipelines)-2]
+	requireStages(t, []bson.D{
+		stage(t, "$match", p.Pipeline[0]["$match"]),
+		{{Key: "$count", Value: "count"}},
+	}, countPipeline)
+
+	// The $count stage outputs no document when the pipeline outputs none
+	p.Next = ""
+	col.queueCount(0)
+	col.queuePage(t)
+	cursor, err = Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Empty(t, page)
+	require.Equal(t, 0, cursor.Count)
+}
+
+type article struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Title     string             `bson:"title"`
+	UpdatedAt *int               `bson:"updatedAt,omitempty"`
+	CreatedAt int                `bson:"createdAt"`
+}
+
+func TestAggregateFallbackField(t *testing.T) {
+	type keyedArticle struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Title     string             `bson:"title"`
+		CreatedAt int                `bson:"createdAt"`
+		Key       int                `bson:"_fallbackKey"`
+	}
+	c := keyedArticle{ID: primitive.NewObjectID(), Title: "c", CreatedAt: 5, Key: 20}
+	b := keyedArticle{ID: primitive.NewObjectID(), Title: "b", CreatedAt: 30, Key: 30}
+	col := newFakeCollection(t, c, b)
+	p := AggregateParams{
+		Collection:     col,
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "updatedAt",
+		FallbackField:  "createdAt",
+	}
+
+	// The documents are sorted on the paginated field coalesced with the fallback field
+	var page []article
+	cursor, err := Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []article{{ID: c.ID, Title: "c", CreatedAt: 5}, {ID: b.ID, Title: "b", CreatedAt: 30}}, page)
+	requireStages(t, []bson.D{
+		stage(t, "$addFields", bson.M{fallbackKeyField: bson.M{"$ifNull": bson.A{"$updatedAt", "$createdAt"}}}),
+		{{Key: "$sort", Value: bson.D{{Key: fallbackKeyField, Value: int32(1)}, {Key: "_id", Value: int32(1)}}}},
+		{{Key: "$limit", Value: int64(3)}},
+	}, col.pipelines[0])
+
+	// The cursor holds the coalesced value
+	values, err := parseCursor(ensureDefaults(p.findParams()), cursor.EndCursor)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int32(30), b.ID}, values)
+
+	// The sort key is removed from the results
+	var docs []bson.M
+	_, err = Aggregate(context.Background(), p, &docs)
+	require.NoError(t, err)
+	require.NotContains(t, docs[0], fallbackKeyField)
+	require.Equal(t, "c", docs[0]["title"])
+}
@@ -0,0 +1,163 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FindAround executes find mongo queries by using the provided FindParams, fills the passed in
+// result slice pointer with at most before documents sorted before the anchor cursor and at most
+// after documents sorted after it, in the sort order of the FindParams, and returns a Cursor
+// spanning the window, e.g. to show the messages around a given message. When includeAnchor is
+// true, the anchor document is part of the window, between the documents before and after it, if
+// it still matches the query. Every document is queried by Find, so the window is the one Find
+// would return. The Previous and Next cursors of the Cursor are generated from the first and last
+// documents of the window, so passing them to Find gets the documents just outside the window to
+// extend it. When before or after is 0, a single document is queried past the window on that side
+// to tell whether there's a previous or next page. The Next, Previous and Limit of the FindParams
+// are ignored.
+func FindAround(ctx context.Context, p FindParams, anchor string, before, after int64, includeAnchor bool, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+	if p.Collection == nil {
+		return Cursor{}, ErrNilCollection
+	}
+	if before < 0 || after < 0 || (before == 0 && after == 0 && !includeAnchor) {
+		return Cursor{}, ErrLimitTooSmall
+	}
+	if anchor == "" {
+		return Cursor{}, &CursorError{errors.New("anchor cursor parse failed: empty cursor")}
+	}
+	anchorValues, err := parseCursor(cursorParams(p), anchor)
+	if err != nil {
+		return Cursor{}, &CursorError{fmt.Errorf("anchor cursor parse failed: %w", err)}
+	}
+
+	sliceType := reflect.TypeOf(results).Elem()
+	window := reflect.MakeSlice(sliceType, 0, 0)
+	var cursor Cursor
+	countTotal := p.CountTotal
+
+	// appendPage appends the documents of a page to the window, whose start and end cursors are
+	// the ones Find generated for its first and last documents
+	appendPage := func(page reflect.Value, queried Cursor) {
+		if page.Len() == 0 {
+			return
+		}
+		window = reflect.AppendSlice(window, page)
+		if cursor.StartCursor == "" {
+			cursor.StartCursor = queried.StartCursor
+		}
+		cursor.EndCursor = queried.EndCursor
+	}
+
+	// query queries with Find the page of at most limit documents after the anchor, or before it
+	// when next is false, the anchor included when inclusive is true. The first query counts the
+	// documents when CountTotal is true
+	query := func(limit int64, next, inclusive bool) (reflect.Value, Cursor, error) {
+		page := reflect.New(sliceType)
+		pp := p
+		pp.Next, pp.Previous, pp.Limit = "", anchor, limit
+		if next {
+			pp.Next, pp.Previous = anchor, ""
+		}
+		pp.CountTotal, pp.includeBoundary = countTotal, inclusive
+		queried, err := Find(ctx, pp, page.Interface())
+		if err != nil {
+			return reflect.Value{}, Cursor{}, err
+		}
+		if countTotal {
+			cursor.Count, countTotal = queried.Count, false
+		}
+		return page.Elem(), queried, nil
+	}
+
+	// The documents before the anchor, queried as the previous page of the anchor. Without any,
+	// whether there are documents before the window, the anchor being one of them when left out
+	if before > 0 {
+		page, beforeCursor, err := query(before, false, false)
+		if err != nil {
+			return Cursor{}, err
+		}
+		appendPage(page, beforeCursor)
+		cursor.HasPrevious = beforeCursor.HasPrevious
+	} else {
+		page, _, err := query(1, false, !includeAnchor)
+		if err != nil {
+			return Cursor{}, err
+		}
+		cursor.HasPrevious = page.Len() > 0
+	}
+
+	// The anchor, queried as the first document of the next page of the anchor included, which
+	// is another document when the anchor no longer matches the query
+	anchorFound, afterAnchor := false, false
+	var anchorCursor Cursor
+	if includeAnchor {
+		page, queried, err := query(1, true, true)
+		if err != nil {
+			return Cursor{}, err
+		}
+		if page.Len() > 0 && queried.StartCursor != "" {
+			values, err := parseCursor(cursorParams(p), queried.StartCursor)
+			if err != nil {
+				return Cursor{}, err
+			}
+			anchorFound = reflect.DeepEqual(values, anchorValues)
+		}
+		if anchorFound {
+			appendPage(page, queried)
+		}
+		anchorCursor, afterAnchor = queried, page.Len() > 0 && !anchorFound
+	}
+
+	// The documents after the anchor, queried as the next page of the anchor. Without any,
+	// whether there are documents after the window, which the anchor query tells when the anchor
+	// is included, the anchor being one of them when left out
+	if after > 0 {
+		page, afterCursor, err := query(after, true, false)
+		if err != nil {
+			return Cursor{}, err
+		}
+		appendPage(page, afterCursor)
+		cursor.HasNext = afterCursor.HasNext
+	} else if includeAnchor {
+		cursor.HasNext = afterAnchor || (anchorFound && anchorCursor.HasNext)
+	} else {
+		page, _, err := query(1, true, true)
+		if err != nil {
+			return Cursor{}, err
+		}
+		cursor.HasNext = page.Len() > 0
+	}
+	reflect.ValueOf(results).Elem().Set(window)
+
+	p = cursorParams(p)
+	if p.Collation != nil {
+		collation := *p.Collation
+		cursor.Collation = &collation
+	}
+	cursor.Returned = int64(window.Len())
+	if window.Len() == 0 {
+		if cursor.HasPrevious {
+			cursor.Previous = anchor
+		}
+		if cursor.HasNext {
+			cursor.Next = anchor
+		}
+		return cursor, nil
+	}
+	if cursor.HasPrevious {
+		cursor.Previous = cursor.StartCursor
+	}
+	if cursor.HasNext {
+		cursor.Next = cursor.EndCursor
+	}
+	return cursor, nil
+}
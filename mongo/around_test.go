@@ -0,0 +1,187 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindAround(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e", "f", "g", "h", "i", "j")
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	fields := []string{"name", "_id"}
+	anchor := mustGenerateCursor(t, items[4], fields)
+
+	// The documents before the anchor are queried as its previous page, the anchor as the first
+	// document of its next page included, and the documents after it as its next page
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queuePage(t, items[4], items[5])
+	col.queuePage(t, items[5], items[6], items[7])
+	col.queueCount(10)
+	var results []item
+	cursor, err := FindAround(context.Background(), p, anchor, 2, 2, true, &results)
+	require.NoError(t, err)
+	before, anchored, after := p, p, p
+	before.Previous, before.Limit = anchor, 2
+	anchored.Next, anchored.Limit, anchored.includeBoundary = anchor, 1, true
+	after.Next, after.Limit = anchor, 2
+	require.Len(t, col.findFilters, 3)
+	for i, expected := range []FindParams{before, anchored, after} {
+		require.Equal(t, sentFilter(t, expected), col.findFilters[i])
+		require.Equal(t, expected.Limit+1, *col.findOptions[i].Limit)
+	}
+	require.Equal(t, bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: -1}}, col.findOptions[0].Sort)
+	require.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, col.findOptions[2].Sort)
+
+	// The pages are merged in the sort order, the window counting the documents once
+	require.Equal(t, []string{"c", "d", "e", "f", "g"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, int64(5), cursor.Returned)
+	require.Equal(t, 10, cursor.Count)
+	require.Len(t, col.countFilters, 1)
+	require.Equal(t, mustGenerateCursor(t, items[2], fields), cursor.StartCursor)
+	require.Equal(t, mustGenerateCursor(t, items[6], fields), cursor.EndCursor)
+	require.Equal(t, cursor.StartCursor, cursor.Previous)
+	require.Equal(t, cursor.EndCursor, cursor.Next)
+
+	// A left out anchor is excluded from the next page
+	col.findFilters = nil
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queuePage(t, items[5], items[6], items[7])
+	cursor, err = FindAround(context.Background(), p, anchor, 2, 2, false, &results)
+	require.NoError(t, err)
+	require.Len(t, col.findFilters, 2)
+	require.Equal(t, sentFilter(t, after), col.findFilters[1])
+	require.Equal(t, []string{"c", "d", "f", "g"}, itemNames(results))
+	require.Equal(t, mustGenerateCursor(t, items[6], fields), cursor.Next)
+
+	// The window stops at the ends of the results
+	col.queuePage(t, items[0])
+	col.queuePage(t, items[1], items[2])
+	col.queuePage(t, items[2], items[3], items[4], items[5])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[1], fields), 3, 3, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.Empty(t, cursor.Previous)
+	require.True(t, cursor.HasNext)
+	col.queuePage(t, items[7], items[6])
+	col.queuePage(t, items[9])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[8], fields), 1, 3, false, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"h", "j"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.False(t, cursor.HasNext)
+	require.Empty(t, cursor.Next)
+
+	// A side of size 0 is probed for a single document past the window, the anchor included
+	// when it's left out
+	col.findFilters, col.findOptions = nil, nil
+	col.queuePage(t, items[3])
+	col.queuePage(t, items[4], items[5])
+	col.queuePage(t, items[5], items[6])
+	cursor, err = FindAround(context.Background(), p, anchor, 0, 1, true, &results)
+	require.NoError(t, err)
+	probe := before
+	probe.Limit = 1
+	require.Equal(t, sentFilter(t, probe), col.findFilters[0])
+	require.Equal(t, int64(2), *col.findOptions[0].Limit)
+	require.Equal(t, []string{"e", "f"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.Equal(t, anchor, cursor.Previous)
+	col.findFilters = nil
+	col.queuePage(t)
+	col.queuePage(t, items[5], items[6])
+	cursor, err = FindAround(context.Background(), p, anchor, 0, 1, false, &results)
+	require.NoError(t, err)
+	probe.includeBoundary = true
+	require.Equal(t, sentFilter(t, probe), col.findFilters[0])
+	require.Equal(t, []string{"f"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	col.queuePage(t)
+	col.queuePage(t, items[0], items[1])
+	col.queuePage(t, items[1])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[0], fields), 0, 1, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.Empty(t, cursor.Previous)
+	col.queuePage(t, items[8], items[7])
+	col.queuePage(t, items[9])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[9], fields), 1, 0, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"i", "j"}, itemNames(results))
+	require.False(t, cursor.HasNext)
+	col.queuePage(t, items[8], items[7])
+	col.queuePage(t, items[9])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[9], fields), 1, 0, false, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"i"}, itemNames(results))
+	require.True(t, cursor.HasNext)
+	require.Equal(t, mustGenerateCursor(t, items[8], fields), cursor.Next)
+
+	// A deleted anchor still anchors the window, the first document of its next page being
+	// another document
+	col.queuePage(t, items[3], items[2])
+	col.queuePage(t, items[5], items[6])
+	col.queuePage(t, items[5], items[6])
+	cursor, err = FindAround(context.Background(), p, anchor, 1, 1, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "f"}, itemNames(results))
+	require.Equal(t, int64(2), cursor.Returned)
+
+	// The anchor is queried by Find, so the Projection applies to it
+	p.Projection = primitive.M{"name": 0}
+	col.queuePage(t)
+	col.queuePage(t, items[5])
+	cursor, err = FindAround(context.Background(), p, mustGenerateCursor(t, items[5], fields), 0, 0, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, itemNames(results))
+	require.Equal(t, mustGenerateCursor(t, items[5], fields), cursor.StartCursor)
+	require.Empty(t, col.pages)
+}
+
+func TestFindAroundErrors(t *testing.T) {
+	items := newItems("a", "b")
+	p := FindParams{Collection: newFakeCollection(t, items...), Query: primitive.M{}}
+	anchor := mustGenerateCursor(t, items[0], []string{"_id"})
+
+	var results []item
+	_, err := FindAround(context.Background(), p, anchor, 1, 1, true, nil)
+	require.True(t, errors.Is(err, ErrNilResults))
+	_, err = FindAround(context.Background(), p, anchor, 0, 0, false, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+	_, err = FindAround(context.Background(), p, anchor, -1, 1, false, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+	_, err = FindAround(context.Background(), p, "", 1, 1, false, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+	_, err = FindAround(context.Background(), p, "XXXXXaGVsbG8=", 1, 1, false, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+	require.Contains(t, err.Error(), "anchor cursor parse failed")
+	p.Collection = nil
+	_, err = FindAround(context.Background(), p, anchor, 1, 1, false, &results)
+	require.True(t, errors.Is(err, ErrNilCollection))
+
+	// Only the anchor, sorted on the _id descending after b
+	col := newFakeCollection(t)
+	col.queuePage(t, items[1])
+	col.queuePage(t, items[0])
+	p.Collection = col
+	cursor, err := FindAround(context.Background(), p, anchor, 0, 0, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.False(t, cursor.HasNext)
+}
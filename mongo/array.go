@@ -0,0 +1,133 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	mcpbson "github.com/qlik-oss/mongocursorpagination/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// arrayPaginated returns true if the PaginatedField holds arrays, sorted by mongo on their smallest
+// element when sorting ascending and on their largest one when sorting descending.
+func arrayPaginated(p FindParams) bool {
+	return p.ArrayPaginatedField && len(p.PaginatedFields) == 0
+}
+
+// arraySortKey returns the element mongo sorts the array on, its smallest element when sorting
+// ascending and its largest one otherwise. Values other than non empty arrays are their own key.
+func arraySortKey(value interface{}, ascending bool) (interface{}, error) {
+	arr, ok := value.(primitive.A)
+	if !ok || len(arr) == 0 {
+		return value, nil
+	}
+	key := arr[0]
+	for _, elem := range arr[1:] {
+		cmp, comparable := compareBSONValues(elem, key)
+		if !comparable {
+			return nil, fmt.Errorf("can't compare the elements %v and %v of the array", elem, key)
+		}
+		if (ascending && cmp < 0) || (!ascending && cmp > 0) {
+			key = elem
+		}
+	}
+	return key, nil
+}
+
+// arraySortKeyResult returns the result as the values of the sorted fields, the array of the
+// PaginatedField being replaced by its sort key, so the cursors of the result hold the key, or the
+// result itself when the PaginatedField doesn't hold arrays.
+func arraySortKeyResult(p FindParams, result interface{}, fields []string) (interface{}, error) {
+	if !arrayPaginated(p) || len(fields) == 0 || fields[0] != p.PaginatedField {
+		return result, nil
+	}
+	data, err := boundaryData(result, fields)
+	if err != nil {
+		return nil, err
+	}
+	data[0].Value, err = arraySortKey(data[0].Value, p.SortAscending)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// generateArrayCursorQuery is generateCursorQuery when the first field is the PaginatedField holding
+// arrays, whose cursor value is the sort key of an array. An array whose key is its smallest element
+// is before the cursor key when any of its elements is less than the key, and after it when none
+// of its elements is less than or equal to the key, and the other way around when the key is the
+// largest element. Arrays holding the key as their sort key are compared on the remaining fields.
+func generateArrayCursorQuery(p FindParams, fields []string, comparisonOps []string, cursorValues []interface{}) (bson.M, error) {
+	if len(cursorValues) != len(fields) {
+		return nil, errors.New("wrong number of cursor field values specified")
+	}
+	if len(comparisonOps) != len(fields) {
+		return nil, errors.New("wrong number of comparison operators specified")
+	}
+	field, key := fields[0], cursorValues[0]
+	// The elements of the arrays are all on the same side of their sort key
+	keyOp := "$lt"
+	if !p.SortAscending {
+		keyOp = "$gt"
+	}
+	strictOp := comparisonOps[0][:3]
+	past := bson.M{field: bson.M{strictOp: key}}
+	if strictOp != keyOp {
+		past = bson.M{field: bson.M{"$not": bson.M{keyOp + "e": key}}}
+	}
+	atKey := []bson.M{
+		{field: bson.M{"$eq": key}},
+		{field: bson.M{"$not": bson.M{keyOp: key}}},
+	}
+
+	if len(fields) == 1 {
+		if comparisonOps[0] == strictOp {
+			return past, nil
+		}
+		return bson.M{"$or": []bson.M{past, {"$and": atKey}}}, nil
+	}
+	rest, err := mcpbson.GenerateCustomCursorQuery(fields[1:], comparisonOps[1:], cursorValues[1:], p.Comparison)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"$or": []bson.M{past, {"$and": append(atKey, rest)}}}, nil
+}
+
+// executeArrayPreviousPageQuery executes the find query of the page of a Previous cursor like
+// executePageQuery, when the PaginatedField holds arrays. Sorting the arrays in the reverse order
+// sorts them on another element, so the documents before the cursor are counted instead and the
+// last of them are queried in the sort order, along with the one before them telling whether
+// there's a previous page, then reversed like the reverse query would have returned them.
+func executeArrayPreviousPageQuery(ctx context.Context, p FindParams, queries []bson.M, opts *options.FindOptions, results interface{}) (int, error) {
+	before, err := executeCountQuery(ctx, p.Collection, countFilter(p, queries), countOptions(p))
+	if err != nil {
+		return 0, err
+	}
+	forward := p
+	forward.Previous = ""
+	_, sort, err := cursorQueryAndSort(forward)
+	if err != nil {
+		return 0, err
+	}
+	skip := int64(before) - lookaheadLimit(p.Limit)
+	if skip < 0 {
+		skip = 0
+	}
+	forwardOpts := *opts
+	forwardOpts.SetSort(sort)
+	forwardOpts.SetSkip(skip)
+	if err := executeCursorQuery(ctx, p.Collection, queries, &forwardOpts, results); err != nil {
+		return 0, err
+	}
+
+	resultsVal := reflect.ValueOf(results).Elem()
+	swap := reflect.Swapper(resultsVal.Interface())
+	for left, right := 0, resultsVal.Len()-1; left < right; left, right = left+1, right-1 {
+		swap(left, right)
+	}
+	return int(p.Limit), nil
+}
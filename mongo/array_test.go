@@ -0,0 +1,151 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	mcpbson "github.com/qlik-oss/mongocursorpagination/bson"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type taggedDocument struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+	Tags []string           `bson:"tags"`
+}
+
+// newTaggedDocuments returns the documents a, b, c, d, e and f, holding the tags m c, b z, d, c e,
+// a x and d f.
+func newTaggedDocuments() map[string]taggedDocument {
+	docs := map[string]taggedDocument{}
+	for _, doc := range []struct {
+		name string
+		tags []string
+	}{
+		{"a", []string{"m", "c"}},
+		{"b", []string{"b", "z"}},
+		{"c", []string{"d"}},
+		{"d", []string{"c", "e"}},
+		{"e", []string{"a", "x"}},
+		{"f", []string{"d", "f"}},
+	} {
+		docs[doc.name] = taggedDocument{ID: primitive.NewObjectID(), Name: doc.name, Tags: doc.tags}
+	}
+	return docs
+}
+
+func TestFindArrayPaginatedField(t *testing.T) {
+	docs := newTaggedDocuments()
+	d := docs["d"]
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:          col,
+		Query:               primitive.M{},
+		Limit:               2,
+		SortAscending:       true,
+		PaginatedField:      "tags",
+		ArrayPaginatedField: true,
+	}
+	cursorOf := func(key string) string {
+		return mustGenerateCursor(t, bson.D{{Key: "tags", Value: key}, {Key: "_id", Value: d.ID}}, []string{"tags", "_id"})
+	}
+	idQuery := func(op string) bson.M {
+		query, err := mcpbson.GenerateCustomCursorQuery([]string{"_id"}, []string{op}, []interface{}{d.ID}, p.Comparison)
+		require.NoError(t, err)
+		return query
+	}
+
+	// Sorting ascending, the arrays are after the key when none of their elements is less than or
+	// equal to it
+	p.Next = cursorOf("c")
+	var results []taggedDocument
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query, bson.M{"$or": []bson.M{
+		{"tags": bson.M{"$not": bson.M{"$lte": "c"}}},
+		{"$and": []bson.M{{"tags": bson.M{"$eq": "c"}}, {"tags": bson.M{"$not": bson.M{"$lt": "c"}}}, idQuery("$gt")}},
+	}}}}), col.findFilters[0])
+	require.Equal(t, bson.D{{Key: "tags", Value: 1}, {Key: "_id", Value: 1}}, col.findOptions[0].Sort)
+
+	// Sorting descending, when none of their elements is greater than or equal to it
+	p.SortAscending = false
+	p.Next = cursorOf("m")
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query, bson.M{"$or": []bson.M{
+		{"tags": bson.M{"$not": bson.M{"$gte": "m"}}},
+		{"$and": []bson.M{{"tags": bson.M{"$eq": "m"}}, {"tags": bson.M{"$not": bson.M{"$gt": "m"}}}, idQuery("$lt")}},
+	}}}}), col.findFilters[1])
+
+	// The documents before the cursor of a Previous page are counted, and the last of them queried
+	// in the sort order, along with the one before them
+	p.SortAscending = true
+	p.Next, p.Previous = "", cursorOf("d")
+	col.queueCount(4)
+	col.queuePage(t, docs["b"], docs["a"], docs["d"])
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, sentFilter(t, p), col.countFilters[0])
+	require.Equal(t, sentFilter(t, p), col.findFilters[2])
+	require.Equal(t, bson.D{{Key: "tags", Value: 1}, {Key: "_id", Value: 1}}, col.findOptions[2].Sort)
+	require.Equal(t, int64(1), *col.findOptions[2].Skip)
+	require.Equal(t, []string{"a", "d"}, taggedNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+}
+
+func TestFindArrayPaginatedFieldCursor(t *testing.T) {
+	docs := newTaggedDocuments()
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:          col,
+		Query:               primitive.M{},
+		Limit:               3,
+		SortAscending:       true,
+		PaginatedField:      "tags",
+		ArrayPaginatedField: true,
+	}
+
+	// The cursors hold the sort key of the arrays rather than the arrays
+	col.queuePage(t, docs["e"], docs["b"], docs["a"], docs["d"])
+	var results []taggedDocument
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	values, err := DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, "c", values["tags"])
+	values, err = DecodeCursorToMap(cursor.StartCursor)
+	require.NoError(t, err)
+	require.Equal(t, "a", values["tags"])
+
+	// Sorting descending, the key is the largest element
+	p.SortAscending = false
+	col.queuePage(t, docs["b"], docs["e"], docs["a"], docs["f"])
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	values, err = DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, "m", values["tags"])
+
+	// The page before the page of a Previous cursor is told apart from the first page, skipping no
+	// document when fewer than the lookahead limit are before the cursor
+	p.Next, p.Previous = "", cursor.Next
+	col.queueCount(3)
+	col.queuePage(t, docs["b"], docs["e"], docs["a"])
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), *col.findOptions[2].Skip)
+	require.Equal(t, []string{"b", "e", "a"}, taggedNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+}
+
+func taggedNames(docs []taggedDocument) []string {
+	names := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		names = append(names, doc.Name)
+	}
+	return names
+}
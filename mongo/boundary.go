@@ -0,0 +1,17 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// NextBoundaryBytes returns the boundary of the page after the last result as the BSON bytes of a
+// document holding the values of the sorted fields of the result, in sort order. Unlike a cursor,
+// it holds no metadata and isn't encoded, e.g. to store checkpoints in an external system
+// independently of the cursor format.
+func NextBoundaryBytes(p FindParams, lastResult interface{}) ([]byte, error) {
+	data, err := boundaryData(lastResult, sortFields(ensureDefaults(p)))
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(data)
+}
@@ -0,0 +1,35 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNextBoundaryBytes(t *testing.T) {
+	last := item{ID: primitive.NewObjectID(), Name: "b", Region: "emea", Seq: 2}
+	p := FindParams{
+		PaginatedField:   "name",
+		TieBreakerFields: []string{"region", "seq"},
+		// The boundary holds no cursor metadata
+		CursorTTL:            time.Hour,
+		IncludeFirstBoundary: true,
+	}
+
+	data, err := NextBoundaryBytes(p, &last)
+	require.NoError(t, err)
+	var boundary bson.D
+	require.NoError(t, bson.Unmarshal(data, &boundary))
+	require.Equal(t, bson.D{{Key: "name", Value: "b"}, {Key: "region", Value: "emea"}, {Key: "seq", Value: int32(2)}}, boundary)
+
+	data, err = NextBoundaryBytes(FindParams{}, last)
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(data, &boundary))
+	require.Equal(t, bson.D{{Key: "_id", Value: last.ID}}, boundary)
+
+	_, err = NextBoundaryBytes(p, nil)
+	require.EqualError(t, err, "the specified result must be a non nil value")
+}
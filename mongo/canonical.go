@@ -0,0 +1,230 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// canonicalCursorV1 is the version byte starting the data of the cursors in the first version of
+// the canonical encoding. Version bytes have their high bit set.
+const canonicalCursorV1 byte = 0x81
+
+// isCanonicalCursor returns true if the cursor data is in a version of the canonical encoding,
+// i.e. if it starts with a version byte and isn't a BSON document, which starts with its length.
+func isCanonicalCursor(data []byte) bool {
+	return len(data) > 0 && data[0]&0x80 != 0 && bson.Raw(data).Validate() != nil
+}
+
+// generateCanonicalCursor generates a cursor holding the values of the specified fields of the
+// result, using the canonical cursor encoding.
+func generateCanonicalCursor(result interface{}, fields []string, metadata ...bson.E) (string, error) {
+	cursorData, err := boundaryData(result, fields)
+	if err != nil {
+		return "", err
+	}
+	cursorData = append(cursorData, metadata...)
+	cursor, err := encodeCanonicalCursor(cursorData)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor using %v: %s", cursorData, err)
+	}
+	return cursor, nil
+}
+
+// encodeCanonicalCursor encodes and returns cursor data that is url safe, pinning the byte layout
+// of the values rather than relying on the BSON marshaler. The data is the version byte followed
+// by the elements, each being the uvarint length of its key, the key, the BSON type of its value
+// and the value:
+//   - double: the 8 bytes of its IEEE 754 binary representation
+//   - string: the uvarint length of the string followed by its UTF-8 bytes
+//   - ObjectID: its 12 bytes
+//   - boolean: 1 for true, 0 for false
+//   - UTC datetime and int64: 8 bytes
+//   - null: no bytes
+//   - int32: 4 bytes
+//   - timestamp: the 4 bytes of its time followed by the 4 bytes of its increment
+//   - decimal128: the 8 bytes of its high bits followed by the 8 bytes of its low bits
+//
+// Integers are big endian.
+func encodeCanonicalCursor(cursorData bson.D) (string, error) {
+	data := []byte{canonicalCursorV1}
+	for _, e := range cursorData {
+		data = appendUvarint(data, uint64(len(e.Key)))
+		data = append(data, e.Key...)
+		switch v := e.Value.(type) {
+		case float64:
+			data = append(data, byte(bsontype.Double))
+			data = appendUint64(data, math.Float64bits(v))
+		case string:
+			data = append(data, byte(bsontype.String))
+			data = appendUvarint(data, uint64(len(v)))
+			data = append(data, v...)
+		case primitive.ObjectID:
+			data = append(data, byte(bsontype.ObjectID))
+			data = append(data, v[:]...)
+		case bool:
+			data = append(data, byte(bsontype.Boolean))
+			if v {
+				data = append(data, 1)
+			} else {
+				data = append(data, 0)
+			}
+		case primitive.DateTime:
+			data = append(data, byte(bsontype.DateTime))
+			data = appendUint64(data, uint64(v))
+		case nil:
+			data = append(data, byte(bsontype.Null))
+		case int32:
+			data = append(data, byte(bsontype.Int32))
+			data = appendUint32(data, uint32(v))
+		case primitive.Timestamp:
+			data = append(data, byte(bsontype.Timestamp))
+			data = appendUint32(data, v.T)
+			data = appendUint32(data, v.I)
+		case int64:
+			data = append(data, byte(bsontype.Int64))
+			data = appendUint64(data, uint64(v))
+		case primitive.Decimal128:
+			high, low := v.GetBytes()
+			data = append(data, byte(bsontype.Decimal128))
+			data = appendUint64(data, high)
+			data = appendUint64(data, low)
+		default:
+			return "", fmt.Errorf("unsupported canonical cursor value type %T of %s", e.Value, e.Key)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// appendUvarint appends the uvarint encoding of n to the data.
+func appendUvarint(data []byte, n uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(data, b[:binary.PutUvarint(b[:], n)]...)
+}
+
+// appendUint64 appends the big endian encoding of n to the data.
+func appendUint64(data []byte, n uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return append(data, b[:]...)
+}
+
+// appendUint32 appends the big endian encoding of n to the data.
+func appendUint32(data []byte, n uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	return append(data, b[:]...)
+}
+
+// decodeCanonicalCursor decodes the data of a cursor that was encoded with encodeCanonicalCursor,
+// dispatching on its version byte.
+func decodeCanonicalCursor(data []byte) (bson.D, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty canonical cursor")
+	}
+	switch data[0] {
+	case canonicalCursorV1:
+		return decodeCanonicalCursorV1(data[1:])
+	}
+	return nil, fmt.Errorf("unsupported canonical cursor version %d", data[0]&^0x80)
+}
+
+// decodeCanonicalCursorV1 decodes the elements of a cursor in the first version of the canonical
+// encoding.
+func decodeCanonicalCursorV1(data []byte) (bson.D, error) {
+	var cursorData bson.D
+	errTruncated := errors.New("truncated canonical cursor")
+	next := func(n uint64) ([]byte, error) {
+		if uint64(len(data)) < n {
+			return nil, errTruncated
+		}
+		b := data[:n]
+		data = data[n:]
+		return b, nil
+	}
+	uvarint := func() (uint64, error) {
+		n, size := binary.Uvarint(data)
+		if size <= 0 {
+			return 0, errTruncated
+		}
+		data = data[size:]
+		return n, nil
+	}
+	for len(data) > 0 {
+		keyLength, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		key, err := next(keyLength)
+		if err != nil {
+			return nil, err
+		}
+		valueType, err := next(1)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		var b []byte
+		switch bsontype.Type(valueType[0]) {
+		case bsontype.Double:
+			if b, err = next(8); err == nil {
+				value = math.Float64frombits(binary.BigEndian.Uint64(b))
+			}
+		case bsontype.String:
+			var length uint64
+			if length, err = uvarint(); err == nil {
+				if b, err = next(length); err == nil {
+					value = string(b)
+				}
+			}
+		case bsontype.ObjectID:
+			if b, err = next(12); err == nil {
+				var id primitive.ObjectID
+				copy(id[:], b)
+				value = id
+			}
+		case bsontype.Boolean:
+			if b, err = next(1); err == nil {
+				if b[0] > 1 {
+					return nil, fmt.Errorf("invalid canonical cursor boolean of %s", key)
+				}
+				value = b[0] == 1
+			}
+		case bsontype.DateTime:
+			if b, err = next(8); err == nil {
+				value = primitive.DateTime(binary.BigEndian.Uint64(b))
+			}
+		case bsontype.Null:
+			value = nil
+		case bsontype.Int32:
+			if b, err = next(4); err == nil {
+				value = int32(binary.BigEndian.Uint32(b))
+			}
+		case bsontype.Timestamp:
+			if b, err = next(8); err == nil {
+				value = primitive.Timestamp{T: binary.BigEndian.Uint32(b), I: binary.BigEndian.Uint32(b[4:])}
+			}
+		case bsontype.Int64:
+			if b, err = next(8); err == nil {
+				value = int64(binary.BigEndian.Uint64(b))
+			}
+		case bsontype.Decimal128:
+			if b, err = next(16); err == nil {
+				value = primitive.NewDecimal128(binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:]))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported canonical cursor value type %d of %s", valueType[0], key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cursorData = append(cursorData, bson.E{Key: string(key), Value: value})
+	}
+	return cursorData, nil
+}
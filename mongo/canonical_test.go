@@ -0,0 +1,106 @@
+package mongo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCanonicalCursorGolden(t *testing.T) {
+	id, err := primitive.ObjectIDFromHex("5f1b2c3d4e5f607182939495")
+	require.NoError(t, err)
+	decimal, err := primitive.ParseDecimal128("1.5")
+	require.NoError(t, err)
+	cases := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"double", 1.5, "810166013ff8000000000000"},
+		{"string", "héllo", "810166020668c3a96c6c6f"},
+		{"ObjectID", id, "810166075f1b2c3d4e5f607182939495"},
+		{"true", true, "8101660801"},
+		{"false", false, "8101660800"},
+		{"datetime", primitive.NewDateTimeFromTime(time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC)), "810166090000016f6435cc8e"},
+		{"null", nil, "8101660a"},
+		{"int32", int32(-2), "81016610fffffffe"},
+		{"timestamp", primitive.Timestamp{T: 1, I: 2}, "810166110000000100000002"},
+		{"int64", int64(1) << 40, "810166120000010000000000"},
+		{"decimal128", decimal, "81016613303e000000000000000000000000000f"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursorData := bson.D{{Key: "f", Value: tc.value}}
+			cursor, err := encodeCanonicalCursor(cursorData)
+			require.NoError(t, err)
+			data, err := base64.RawURLEncoding.DecodeString(cursor)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hex.EncodeToString(data))
+
+			decoded, err := decodeCursor(cursor)
+			require.NoError(t, err)
+			require.Equal(t, cursorData, decoded)
+		})
+	}
+}
+
+func TestCanonicalCursorErrors(t *testing.T) {
+	_, err := encodeCanonicalCursor(bson.D{{Key: "f", Value: bson.A{1}}})
+	require.EqualError(t, err, "unsupported canonical cursor value type primitive.A of f")
+
+	for _, tc := range []struct {
+		data        string
+		expectedErr string
+	}{
+		{"82016610fffffffe", "unsupported canonical cursor version 2"},
+		{"81016610fffe", "truncated canonical cursor"},
+		{"810166ff", "unsupported canonical cursor value type 255 of f"},
+		{"8101660802", "invalid canonical cursor boolean of f"},
+	} {
+		data, err := hex.DecodeString(tc.data)
+		require.NoError(t, err)
+		_, err = decodeCursor(base64.RawURLEncoding.EncodeToString(data))
+		require.EqualError(t, err, tc.expectedErr)
+	}
+}
+
+func TestFindCanonicalCursor(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:      col,
+		Query:           primitive.M{},
+		Limit:           2,
+		SortAscending:   true,
+		PaginatedField:  "name",
+		CursorTTL:       time.Hour,
+		CanonicalCursor: true,
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	for _, c := range []string{cursor.Next, cursor.StartCursor, cursor.EndCursor} {
+		data, err := base64.RawURLEncoding.DecodeString(c)
+		require.NoError(t, err)
+		require.Equal(t, canonicalCursorV1, data[0])
+	}
+
+	// The BSON cursors are still accepted, and the other way around, querying the same page
+	bsonCursor, err := generateCursor(results[1], []string{"name", "_id"})
+	require.NoError(t, err)
+	p.Next = bsonCursor
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, sentFilter(t, p), col.findFilters[1])
+
+	p.CanonicalCursor = false
+	p.Next = cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, col.findFilters[1], col.findFilters[2])
+}
@@ -0,0 +1,45 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// advanceOperationTime advances the operation time of the session of the context to the one held
+// by the Next or Previous cursor, so the queries of the page read at or after it when the session
+// is causally consistent. Nothing is done without a session, or if the cursor doesn't hold an
+// operation time or doesn't parse, in which case building the page queries reports the error.
+func advanceOperationTime(ctx context.Context, p FindParams) error {
+	session := driver.SessionFromContext(ctx)
+	if session == nil {
+		return nil
+	}
+	token := p.Next
+	if token == "" {
+		token = p.Previous
+	}
+	if token == "" {
+		return nil
+	}
+	_, metadata, err := parseCursorData(ensureDefaults(p), token)
+	if err != nil {
+		return nil
+	}
+	for _, e := range metadata {
+		if operationTime, ok := e.Value.(primitive.Timestamp); ok && e.Key == cursorOperationTimeKey {
+			return session.AdvanceOperationTime(&operationTime)
+		}
+	}
+	return nil
+}
+
+// withOperationTime returns the FindParams holding the operation time of the session of the
+// context, to add to the cursors of the page once its query was executed.
+func withOperationTime(ctx context.Context, p FindParams) FindParams {
+	if session := driver.SessionFromContext(ctx); session != nil {
+		p.operationTime = session.OperationTime()
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"context"
+)
+
+// FindChanBuffered streams the documents of all the pages starting at the page of the provided
+// FindParams into the returned channel, whose buffer holds bufSize documents. The pages are
+// fetched one at a time and a page is only fetched once all the documents of the previous page are
+// in the channel, so a slow consumer holds back the fetching rather than having documents pile up.
+// The channels are closed once the last page has been streamed, after an error is sent on the
+// error channel, or when the context is cancelled, the context error being sent then.
+func FindChanBuffered[T any](ctx context.Context, p FindParams, bufSize int) (<-chan T, <-chan error) {
+	docs := make(chan T, bufSize)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(docs)
+		for {
+			var page []T
+			cursor, err := Find(ctx, p, &page)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for _, doc := range page {
+				// Stop as soon as the context is cancelled, even when the channel has room
+				if err := ctx.Err(); err != nil {
+					errs <- err
+					return
+				}
+				select {
+				case docs <- doc:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if !cursor.HasNext {
+				return
+			}
+			p.Next, p.Previous = cursor.Next, ""
+		}
+	}()
+	return docs, errs
+}
@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// countingCollection counts the find queries made on the collection from any goroutine.
+type countingCollection struct {
+	*fakeCollection
+	finds int32
+}
+
+func (c *countingCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	atomic.AddInt32(&c.finds, 1)
+	return c.fakeCollection.Find(ctx, filter, opts...)
+}
+
+func TestFindChanBuffered(t *testing.T) {
+	col := &countingCollection{fakeCollection: newFakeCollection(t)}
+	col.queuePages(t, 2, newItems("a", "b", "c", "d", "e", "f")...)
+	p := FindParams{
+		Collection:    col,
+		Query:         primitive.M{},
+		Limit:         2,
+		SortAscending: true,
+	}
+	docs, errs := FindChanBuffered[item](context.Background(), p, 2)
+
+	// The first page fills the buffer, the producer then blocks sending the second page
+	require.Eventually(t, func() bool { return len(docs) == 2 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, int32(2), atomic.LoadInt32(&col.finds))
+	require.Len(t, docs, 2)
+
+	var names []string
+	for doc := range docs {
+		names = append(names, doc.Name)
+	}
+	require.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, names)
+	require.NoError(t, <-errs)
+	require.Equal(t, int32(3), atomic.LoadInt32(&col.finds))
+}
+
+func TestFindChanBufferedCancellation(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c", "d")...)
+	p := FindParams{
+		Collection: col,
+		Query:      primitive.M{},
+		Limit:      2,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	docs, errs := FindChanBuffered[item](ctx, p, 1)
+	<-docs
+	cancel()
+	for range docs {
+	}
+	require.Equal(t, context.Canceled, <-errs)
+
+	// The error of a page query is sent before the channels are closed
+	p.Limit = 0
+	docs, errs = FindChanBuffered[item](context.Background(), p, 1)
+	_, ok := <-docs
+	require.False(t, ok)
+	require.EqualError(t, <-errs, "a limit of at least 1 is required")
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"encoding/base64"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// countToken returns the CountToken holding the count of the documents matching the query of the
+// FindParams along with the hash of the query, signed when the FindParams have a CursorSecret.
+func countToken(p FindParams, count int) (string, error) {
+	hash, err := filterHash(p)
+	if err != nil {
+		return "", err
+	}
+	data, err := bson.Marshal(bson.D{{Key: "count", Value: int64(count)}, {Key: "fh", Value: hash}})
+	if err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(data)
+	if len(p.CursorSecret) > 0 {
+		return signCursor(token, p.CursorSecret)
+	}
+	return token, nil
+}
+
+// reusedCount returns the count held by the CountToken of the FindParams, and false if there's
+// none, if it's invalid or if it was computed for another query, the documents having to be
+// counted then.
+func reusedCount(p FindParams) (int, bool) {
+	if p.CountToken == "" {
+		return 0, false
+	}
+	token := p.CountToken
+	if len(p.CursorSecret) > 0 {
+		var err error
+		if token, err = verifyCursor(token, p.CursorSecret); err != nil {
+			return 0, false
+		}
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, false
+	}
+	var decoded struct {
+		Count int64  `bson:"count"`
+		Hash  string `bson:"fh"`
+	}
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		return 0, false
+	}
+	hash, err := filterHash(p)
+	if err != nil || hash != decoded.Hash {
+		return 0, false
+	}
+	return int(decoded.Count), true
+}
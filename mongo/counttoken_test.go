@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindReuseCount(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c", "d", "e")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"name": primitive.M{"$ne": "z"}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ReuseCount:     true,
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 5, cursor.Count)
+	require.NotEmpty(t, cursor.CountToken)
+	require.Len(t, col.countFilters, 1)
+
+	// The count of the token is reused on the next pages, even when it's stale
+	col.insert(t, item{ID: primitive.NewObjectID(), Name: "f"})
+	p.Next, p.CountToken = cursor.Next, cursor.CountToken
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 5, cursor.Count)
+	require.Equal(t, p.CountToken, cursor.CountToken)
+	require.Len(t, col.countFilters, 1)
+
+	// The documents are counted again when the query changed or the token is invalid
+	for i, tc := range []struct {
+		change        func(*FindParams)
+		expectedCount int
+	}{
+		{func(p *FindParams) { p.Query = primitive.M{"name": primitive.M{"$ne": "a"}} }, 4},
+		{func(p *FindParams) { p.CountToken = "XXXXXaGVsbG8=" }, 6},
+		{func(p *FindParams) { p.Next, p.CursorSecret = "", []byte("s3cr3t") }, 6},
+	} {
+		q := p
+		tc.change(&q)
+		col.queueCount(int64(tc.expectedCount))
+		cursor, err = Find(context.Background(), q, &results)
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedCount, cursor.Count)
+		require.Len(t, col.countFilters, i+2)
+		require.Equal(t, mustToM(t, countFilter(q, totalCountQueries(q))), col.countFilters[i+1])
+	}
+
+	// No token is returned without ReuseCount
+	p.ReuseCount = false
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Empty(t, cursor.CountToken)
+}
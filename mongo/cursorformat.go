@@ -0,0 +1,61 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CursorFormat is the encoding of the cursors generated by Find. Whatever the format, the cursors
+// of all the formats are accepted.
+type CursorFormat int
+
+const (
+	// CursorFormatBSON encodes the cursors as BSON documents, it's the default
+	CursorFormatBSON CursorFormat = iota
+	// CursorFormatJSON encodes the cursors as canonical Extended JSON objects of the field values,
+	// e.g. {"name":"a","_id":{"$oid":"5f1b2c3d4e5f607182939495"}}, preceded by the jsonCursorPrefix
+	// byte, so non Go clients can inspect and construct cursors
+	CursorFormatJSON
+)
+
+// jsonCursorPrefix is the byte starting the data of the cursors in the JSON format.
+const jsonCursorPrefix byte = 'j'
+
+// isJSONCursor returns true if the cursor data is in the JSON format, i.e. if it starts with the
+// jsonCursorPrefix and isn't a BSON document, whose length could start with the same byte.
+func isJSONCursor(data []byte) bool {
+	return len(data) > 0 && data[0] == jsonCursorPrefix && bson.Raw(data).Validate() != nil
+}
+
+// generateJSONCursor generates a cursor holding the values of the specified fields of the result,
+// in the JSON format.
+func generateJSONCursor(result interface{}, fields []string, metadata ...bson.E) (string, error) {
+	cursorData, err := boundaryData(result, fields)
+	if err != nil {
+		return "", err
+	}
+	cursorData = append(cursorData, metadata...)
+	cursor, err := encodeJSONCursor(cursorData)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor using %v: %s", cursorData, err)
+	}
+	return cursor, nil
+}
+
+// encodeJSONCursor encodes and returns cursor data in the JSON format that is url safe.
+func encodeJSONCursor(cursorData bson.D) (string, error) {
+	data, err := bson.MarshalExtJSON(cursorData, true, false)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append([]byte{jsonCursorPrefix}, data...)), nil
+}
+
+// decodeJSONCursor decodes the data of a cursor in the JSON format.
+func decodeJSONCursor(data []byte) (bson.D, error) {
+	var cursorData bson.D
+	err := bson.UnmarshalExtJSON(data[1:], true, &cursorData)
+	return cursorData, err
+}
@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindJSONCursorFormat(t *testing.T) {
+	items := newItems("a", "b", "c")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CursorFormat:   CursorFormatJSON,
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	data, err := base64.RawURLEncoding.DecodeString(cursor.Next)
+	require.NoError(t, err)
+	id := items[1].(item).ID.Hex()
+	require.Equal(t, fmt.Sprintf(`j{"name":"b","_id":{"$oid":"%s"}}`, id), string(data))
+
+	// The cursors constructed by clients and the BSON cursors are accepted, the other way around
+	// too, all of them querying the page after b
+	p.Next = mustGenerateCursor(t, items[1], []string{"name", "_id"})
+	expected := sentFilter(t, p)
+	for _, next := range []string{
+		base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`j{"name":"b","_id":{"$oid":"%s"}}`, id))),
+		p.Next,
+	} {
+		p.Next = next
+		_, err = Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, expected, col.findFilters[len(col.findFilters)-1])
+	}
+
+	p.CursorFormat = CursorFormatBSON
+	p.Next = cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, expected, col.findFilters[len(col.findFilters)-1])
+
+	p.Next = base64.RawURLEncoding.EncodeToString([]byte(`j{"name":`))
+	_, err = Find(context.Background(), p, &results)
+	require.IsType(t, &CursorError{}, err)
+}
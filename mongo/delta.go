@@ -0,0 +1,106 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deltaCursorPrefix is the byte starting the data of the delta cursors, followed by the zigzag
+// varint difference of the value of each sorted field from the one of the boundary the cursor is
+// relative to.
+const deltaCursorPrefix byte = 'd'
+
+// errDeltaCursorWithoutBase is the error returned when decoding a delta cursor without the
+// boundary it's relative to
+var errDeltaCursorWithoutBase = errors.New("a delta cursor can only be decoded by the PageIterator which generated it")
+
+// isDeltaCursor returns true if the cursor data is a delta cursor, i.e. if it starts with the
+// deltaCursorPrefix and isn't a BSON document, whose length could start with the same byte.
+func isDeltaCursor(data []byte) bool {
+	return len(data) > 0 && data[0] == deltaCursorPrefix && bson.Raw(data).Validate() != nil
+}
+
+// generateDeltaCursor generates a delta cursor holding the differences of the values of the
+// specified fields of the result from the base values, and returns false if a field or its base
+// value isn't an integer or a datetime, or if their types differ.
+func generateDeltaCursor(result interface{}, fields []string, base []interface{}) (string, bool, error) {
+	cursorData, err := boundaryData(result, fields)
+	if err != nil {
+		return "", false, err
+	}
+	if len(base) != len(cursorData) {
+		return "", false, nil
+	}
+	data := []byte{deltaCursorPrefix}
+	var b [binary.MaxVarintLen64]byte
+	for i, e := range cursorData {
+		var delta int64
+		switch v := e.Value.(type) {
+		case int32:
+			baseValue, ok := base[i].(int32)
+			if !ok {
+				return "", false, nil
+			}
+			delta = int64(v) - int64(baseValue)
+		case int64:
+			baseValue, ok := base[i].(int64)
+			if !ok {
+				return "", false, nil
+			}
+			delta = v - baseValue
+		case primitive.DateTime:
+			baseValue, ok := base[i].(primitive.DateTime)
+			if !ok {
+				return "", false, nil
+			}
+			delta = int64(v) - int64(baseValue)
+		default:
+			return "", false, nil
+		}
+		data = append(data, b[:binary.PutVarint(b[:], delta)]...)
+	}
+	if !isDeltaCursor(data) {
+		return "", false, nil
+	}
+	return base64.RawURLEncoding.EncodeToString(data), true, nil
+}
+
+// decodeDeltaCursor decodes the values of the specified fields held by a delta cursor, adding its
+// differences to the base values.
+func decodeDeltaCursor(data []byte, fields []string, base []interface{}) (bson.D, error) {
+	if base == nil {
+		return nil, errDeltaCursorWithoutBase
+	}
+	if len(base) != len(fields) {
+		return nil, errors.New("invalid delta cursor base")
+	}
+	data = data[1:]
+	cursorData := make(bson.D, 0, len(fields))
+	for i, baseValue := range base {
+		delta, size := binary.Varint(data)
+		if size <= 0 {
+			return nil, errors.New("truncated delta cursor")
+		}
+		data = data[size:]
+		var value interface{}
+		switch v := baseValue.(type) {
+		case int32:
+			value = int32(int64(v) + delta)
+		case int64:
+			value = v + delta
+		case primitive.DateTime:
+			value = primitive.DateTime(int64(v) + delta)
+		default:
+			return nil, errors.New("invalid delta cursor base")
+		}
+		cursorData = append(cursorData, bson.E{Key: fields[i], Value: value})
+	}
+	if len(data) > 0 {
+		return nil, errors.New("delta cursor holding too many values")
+	}
+	return cursorData, nil
+}
@@ -0,0 +1,83 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPageIteratorDeltaCursors(t *testing.T) {
+	var items []interface{}
+	var names []string
+	for i, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"} {
+		items = append(items, item{ID: primitive.NewObjectID(), Name: name, Seq: 1000000 + 37*i})
+		names = append(names, name)
+	}
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	p := FindParams{
+		Collection:       col,
+		Query:            primitive.M{},
+		Limit:            2,
+		SortAscending:    true,
+		PaginatedField:   "seq",
+		TieBreakerFields: []string{"seq"},
+		DeltaCursors:     true,
+	}
+
+	// The cursors after the first page are delta encoded, the traversal is the same
+	it := NewPageIterator(p, 0)
+	var visited, cursors []string
+	for {
+		var page []item
+		if !it.Next(context.Background(), &page) {
+			break
+		}
+		visited = append(visited, itemNames(page)...)
+		if it.Cursor().HasNext {
+			cursors = append(cursors, it.Cursor().Next)
+		}
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, names, visited)
+	require.Len(t, cursors, 4)
+	full := mustGenerateCursor(t, items[1], []string{"seq"})
+	require.Equal(t, full, cursors[0])
+	for _, cursor := range cursors[1:] {
+		require.Less(t, len(cursor), len(full)/3)
+	}
+
+	// A delta cursor is rejected outside of the iterator, whose resume cursor is encoded in full
+	var results []item
+	p.Next = cursors[2]
+	_, err := Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, errDeltaCursorWithoutBase))
+	p.Next = cursors[0]
+	col.queuePage(t, items[2:5]...)
+	col.queuePage(t, items[4:7]...)
+	it = NewPageIterator(p, 0)
+	require.True(t, it.Next(context.Background(), &results))
+	require.True(t, it.Next(context.Background(), &results))
+	require.Equal(t, []string{"e", "f"}, itemNames(results))
+	require.Equal(t, mustGenerateCursor(t, items[5], []string{"seq"}), it.ResumeCursor())
+	p.Next = it.ResumeCursor()
+	col.queuePage(t, items[6:9]...)
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"g", "h"}, itemNames(results))
+
+	// The cursors are encoded in full when a sorted field isn't an integer
+	p.Next = ""
+	p.TieBreakerFields = nil
+	col.queuePages(t, 2, items...)
+	it = NewPageIterator(p, 0)
+	for it.Next(context.Background(), &results) {
+		if it.Cursor().HasNext {
+			require.Equal(t, mustGenerateCursor(t, results[1], []string{"seq", "_id"}), it.Cursor().Next)
+		}
+	}
+	require.NoError(t, it.Err())
+}
@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// findDiscriminated is Find decoding each document into the type registered for the value of its
+// DiscriminatorField. The documents are fetched as bson.Raw, from which the cursors are generated
+// whatever the type of the documents.
+func findDiscriminated(ctx context.Context, p FindParams, results interface{}) (Cursor, error) {
+	typed, ok := results.(*[]interface{})
+	if !ok || typed == nil {
+		return Cursor{}, fmt.Errorf("%w, got %T: a *[]interface{} is required with a DiscriminatorField", ErrInvalidResultsType, results)
+	}
+
+	var raws []bson.Raw
+	cursor, err := find(ctx, p, &raws, nil)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	decoded := make([]interface{}, 0, len(raws))
+	for _, raw := range raws {
+		discriminator, ok := raw.Lookup(p.DiscriminatorField).StringValueOK()
+		if !ok {
+			return Cursor{}, fmt.Errorf("the %s discriminator field of a result isn't a string", p.DiscriminatorField)
+		}
+		t, ok := p.DiscriminatorTypes[discriminator]
+		if !ok {
+			return Cursor{}, fmt.Errorf("no type registered for the %s discriminator %q", p.DiscriminatorField, discriminator)
+		}
+		doc := reflect.New(t)
+		if err := unmarshalResult(p, raw, doc.Interface()); err != nil {
+			return Cursor{}, fmt.Errorf("could not decode a %s result: %s", discriminator, err)
+		}
+		decoded = append(decoded, doc.Elem().Interface())
+	}
+	*typed = decoded
+	return cursor, nil
+}
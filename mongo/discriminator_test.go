@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type circle struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Type   string             `bson:"type"`
+	Name   string             `bson:"name"`
+	Radius float64            `bson:"radius"`
+}
+
+type rectangle struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Type   string             `bson:"type"`
+	Name   string             `bson:"name"`
+	Width  float64            `bson:"width"`
+	Height float64            `bson:"height"`
+}
+
+func TestFindDiscriminated(t *testing.T) {
+	c1 := circle{ID: primitive.NewObjectID(), Type: "circle", Name: "a", Radius: 1}
+	r1 := rectangle{ID: primitive.NewObjectID(), Type: "rectangle", Name: "b", Width: 2, Height: 3}
+	c2 := circle{ID: primitive.NewObjectID(), Type: "circle", Name: "c", Radius: 4}
+	col := newFakeCollection(t, c1, r1, c2)
+	p := FindParams{
+		Collection:         col,
+		Query:              primitive.M{},
+		Limit:              2,
+		SortAscending:      true,
+		PaginatedField:     "name",
+		DiscriminatorField: "type",
+		DiscriminatorTypes: map[string]reflect.Type{
+			"circle":    reflect.TypeOf(circle{}),
+			"rectangle": reflect.TypeOf(rectangle{}),
+		},
+	}
+
+	var results []interface{}
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{c1, r1}, results)
+	require.True(t, cursor.HasNext)
+
+	// The cursors are generated from the paginated field whatever the type of the documents
+	require.Equal(t, mustGenerateCursor(t, r1, []string{"name", "_id"}), cursor.Next)
+	p.Next = cursor.Next
+	col.queuePage(t, c2)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{c2}, results)
+	require.False(t, cursor.HasNext)
+	require.Equal(t, mustGenerateCursor(t, c2, []string{"name", "_id"}), cursor.Previous)
+
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, r1, c1)
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{c1, r1}, results)
+}
+
+func TestFindDiscriminatedErrors(t *testing.T) {
+	col := newFakeCollection(t, bson.M{"_id": primitive.NewObjectID(), "type": "triangle"})
+	p := FindParams{
+		Collection:         col,
+		Query:              primitive.M{},
+		Limit:              2,
+		DiscriminatorField: "type",
+		DiscriminatorTypes: map[string]reflect.Type{"circle": reflect.TypeOf(circle{})},
+	}
+	var shapes []circle
+	_, err := Find(context.Background(), p, &shapes)
+	require.True(t, errors.Is(err, ErrInvalidResultsType))
+
+	var results []interface{}
+	_, err = Find(context.Background(), p, &results)
+	require.EqualError(t, err, `no type registered for the type discriminator "triangle"`)
+
+	col.docs = nil
+	col.insert(t, bson.M{"_id": primitive.NewObjectID(), "type": 1})
+	_, err = Find(context.Background(), p, &results)
+	require.EqualError(t, err, "the type discriminator field of a result isn't a string")
+}
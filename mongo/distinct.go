@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DistinctParams holds the parameters to be used in a paginated query of the distinct values of a
+// field that will return a Cursor.
+type DistinctParams struct {
+	Collection AggregateCollection
+
+	// The field whose distinct values are paginated over. Like with the distinct command, each
+	// element of an array value is a distinct value of its own, arrays being unwound one level
+	// only, and the documents where the field is missing, null or an empty array hold no value.
+	// Unwinding the arrays keeps the values orderable: mongo sorts an array on its smallest or
+	// largest element, so distinct arrays couldn't be paginated over consistently
+	Field string
+	// The query selecting the documents whose values are paginated over, all of them if nil
+	Query primitive.M
+	// The number of values to fetch, should be > 0
+	Limit int64
+	// true, if the values should be sort ascending, false otherwise
+	SortAscending bool
+	// The value to start querying the page
+	Next string
+	// The value to start querying previous page
+	Previous string
+	// Whether or not to include total count of distinct values in the cursor
+	// Specifying true runs an additional aggregation
+	CountTotal bool
+}
+
+// ErrNoDistinctField is the error returned when the DistinctParams passed to Distinct have no Field
+var ErrNoDistinctField = errors.New("a distinct field is required")
+
+// Distinct paginates over the distinct values of the Field of the documents matching the query of
+// the provided DistinctParams, fills the passed in result slice pointer with the values, e.g. a
+// *[]string, and returns a Cursor. The values are grouped by an aggregation pipeline and paginated
+// on as the _id of the groups, so the cursors hold the last value of a page and the next page
+// continues after it.
+func Distinct(ctx context.Context, p DistinctParams, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+	if p.Field == "" {
+		return Cursor{}, ErrNoDistinctField
+	}
+
+	query := p.Query
+	if query == nil {
+		query = primitive.M{}
+	}
+	var groups []bson.Raw
+	cursor, err := Aggregate(ctx, AggregateParams{
+		Collection: p.Collection,
+		Pipeline: []bson.M{
+			{"$match": query},
+			{"$unwind": "$" + p.Field},
+			{"$group": bson.M{"_id": "$" + p.Field}},
+		},
+		Limit:         p.Limit,
+		SortAscending: p.SortAscending,
+		Next:          p.Next,
+		Previous:      p.Previous,
+		CountTotal:    p.CountTotal,
+	}, &groups)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if err := decodeGroupIDs(groups, results); err != nil {
+		return Cursor{}, err
+	}
+	return cursor, nil
+}
+
+// decodeGroupIDs decodes the _id of the groups into the results.
+func decodeGroupIDs(groups []bson.Raw, results interface{}) error {
+	resultsVal := reflect.ValueOf(results).Elem()
+	elemType := resultsVal.Type().Elem()
+	decoded := reflect.MakeSlice(resultsVal.Type(), 0, len(groups))
+	for _, group := range groups {
+		elem := reflect.New(elemType)
+		if err := group.Lookup("_id").Unmarshal(elem.Interface()); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, elem.Elem())
+	}
+	resultsVal.Set(decoded)
+	return nil
+}
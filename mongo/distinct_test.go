@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDistinct(t *testing.T) {
+	col := newFakeCollection(t)
+	p := DistinctParams{
+		Collection:    col,
+		Field:         "category",
+		Limit:         2,
+		SortAscending: true,
+		CountTotal:    true,
+	}
+	ctx := context.Background()
+
+	// The values are grouped by an aggregation, paginated on as the _id of the groups
+	col.queueCount(4)
+	col.queuePage(t, bson.M{"_id": "books"}, bson.M{"_id": "films"}, bson.M{"_id": "games"})
+	var categories []string
+	cursor, err := Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"books", "films"}, categories)
+	require.Equal(t, 4, cursor.Count)
+	require.True(t, cursor.HasNext)
+	groupStages := []bson.D{
+		stage(t, "$match", bson.M{}),
+		stage(t, "$unwind", "$category"),
+		stage(t, "$group", bson.M{"_id": "$category"}),
+	}
+	requireStages(t, append(groupStages[:3:3], stage(t, "$count", "count")), col.pipelines[0])
+	requireStages(t, append(groupStages[:3:3],
+		stage(t, "$sort", bson.D{{Key: "_id", Value: 1}}),
+		stage(t, "$limit", int64(3)),
+	), col.pipelines[1])
+
+	// The next page continues after the last value of the page
+	values, err := DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"_id": "films"}, values)
+	p.Next = cursor.Next
+	col.queueCount(4)
+	col.queuePage(t, bson.M{"_id": "games"}, bson.M{"_id": "music"})
+	cursor, err = Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"games", "music"}, categories)
+	require.False(t, cursor.HasNext)
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, FindParams{PaginatedField: "_id", SortAscending: true, Next: p.Next})), col.pipelines[3][3])
+
+	// The groups of the previous page are returned by mongo in the reverse order
+	p.Next, p.Previous = "", cursor.Previous
+	col.queueCount(4)
+	col.queuePage(t, bson.M{"_id": "films"}, bson.M{"_id": "books"})
+	cursor, err = Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"books", "films"}, categories)
+	require.False(t, cursor.HasPrevious)
+	requireStage(t, stage(t, "$sort", bson.D{{Key: "_id", Value: -1}}), col.pipelines[5][4])
+
+	// The query selects the documents whose values are paginated over
+	p = DistinctParams{Collection: col, Field: "tags", Query: primitive.M{"category": "games"}, Limit: 10}
+	col.queuePage(t, bson.M{"_id": "c"}, bson.M{"_id": "b"})
+	var tags []string
+	_, err = Distinct(ctx, p, &tags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, tags)
+	last := col.pipelines[len(col.pipelines)-1]
+	requireStages(t, []bson.D{
+		stage(t, "$match", p.Query),
+		stage(t, "$unwind", "$tags"),
+		stage(t, "$group", bson.M{"_id": "$tags"}),
+	}, last[:3])
+
+	_, err = Distinct(ctx, DistinctParams{Collection: col, Limit: 2}, &tags)
+	require.Equal(t, ErrNoDistinctField, err)
+}
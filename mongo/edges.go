@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Edge pairs a result document with its own cursor, so clients can paginate from any document of
+// a page, e.g. as the edges of a GraphQL Relay connection.
+type Edge[T any] struct {
+	// The result document
+	Node T
+	// The cursor of the document, to pass as the Next (or Previous) of a FindParams to get the
+	// documents after (or before) it
+	Cursor string
+}
+
+// PageInfo holds the pagination data of a page in the shape of the PageInfo of a GraphQL Relay
+// connection.
+type PageInfo struct {
+	HasNextPage     bool   `json:"hasNextPage"`
+	HasPreviousPage bool   `json:"hasPreviousPage"`
+	StartCursor     string `json:"startCursor"`
+	EndCursor       string `json:"endCursor"`
+}
+
+// ToPageInfo returns the PageInfo of the page of the Cursor: its start cursor is the Previous
+// cursor and its end cursor is the Next cursor, each of them being empty when there's no page in
+// that direction. The cursors of the individual documents are returned by FindEdges.
+func (c Cursor) ToPageInfo() PageInfo {
+	return PageInfo{
+		HasNextPage:     c.HasNext,
+		HasPreviousPage: c.HasPrevious,
+		StartCursor:     c.Previous,
+		EndCursor:       c.Next,
+	}
+}
+
+// FindEdges executes a find mongo query by using the provided FindParams and returns the results
+// as edges, along with the Cursor of the page. The cursors of the nodes are generated by Find from
+// the documents it queried, so they hold the sorted fields even when the Projection leaves them
+// out of the results.
+func FindEdges[T any](ctx context.Context, p FindParams) ([]Edge[T], Cursor, error) {
+	var nodes []T
+	var nodeCursors []string
+	p.nodeCursors = &nodeCursors
+	cursor, err := Find(ctx, p, &nodes)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	edges := make([]Edge[T], 0, len(nodes))
+	for i, node := range nodes {
+		edges = append(edges, Edge[T]{Node: node, Cursor: nodeCursors[i]})
+	}
+	return edges, cursor, nil
+}
+
+// documentCursors returns the cursor of each of the documents of the page, in order.
+func documentCursors(p FindParams, resultsVal reflect.Value) ([]string, error) {
+	fields := sortFields(p)
+	metadata := cursorMetadata(p)
+	cursors := make([]string, 0, resultsVal.Len())
+	for i := 0; i < resultsVal.Len(); i++ {
+		cursor, err := generatePageCursor(p, resultsVal.Index(i).Interface(), fields, metadata)
+		if err != nil {
+			return nil, fmt.Errorf("could not create a cursor: %s", err)
+		}
+		cursors = append(cursors, cursor)
+	}
+	return cursors, nil
+}
@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindEdges(t *testing.T) {
+	col := newFakeCollection(t, newItems("test item 1", "test item 2", "test item 3", "test item 4")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          3,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	edges, cursor, err := FindEdges[*item](context.Background(), p)
+	require.NoError(t, err)
+	require.Len(t, edges, 3)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, edges[2].Cursor, cursor.Next)
+
+	// The cursors hold the values of the nodes
+	for _, edge := range edges {
+		values, err := parseCursor(ensureDefaults(p), edge.Cursor)
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{edge.Node.Name, edge.Node.ID}, values)
+	}
+}
+
+func TestFindEdgesProjection(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		Projection:     bson.M{"name": 0},
+	}
+
+	// The cursors of the nodes hold the names the projection leaves out of the nodes
+	edges, cursor, err := FindEdges[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Len(t, edges, 2)
+	require.Empty(t, edges[0].Node.Name)
+	require.Equal(t, cursor.Next, edges[1].Cursor)
+	values, err := parseCursor(ensureDefaults(p), edges[0].Cursor)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"a", edges[0].Node.ID}, values)
+}
+
+func TestCursorToPageInfo(t *testing.T) {
+	col := newFakeCollection(t, newItems("test item 1", "test item 2", "test item 3", "test item 4", "test item 5")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+	edges, cursor, err := FindEdges[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, PageInfo{HasNextPage: true, EndCursor: edges[1].Cursor}, cursor.ToPageInfo())
+
+	p.Next = cursor.Next
+	edges, cursor, err = FindEdges[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, PageInfo{
+		HasNextPage:     true,
+		HasPreviousPage: true,
+		StartCursor:     edges[0].Cursor,
+		EndCursor:       edges[1].Cursor,
+	}, cursor.ToPageInfo())
+}
@@ -0,0 +1,52 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EstimatedCountCollection is a Collection which can estimate its number of documents from its
+// metadata, e.g. a wrapper of a *mongo.Collection calling its EstimatedDocumentCount method. The
+// count of FindParams with EstimatedCount is only estimated when the Collection implements it.
+type EstimatedCountCollection interface {
+	Collection
+	EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error)
+}
+
+// estimatedCount returns the estimated number of documents of the Collection of the FindParams,
+// and false if it can't estimate it.
+func estimatedCount(ctx context.Context, p FindParams) (int, bool, error) {
+	col, err := estimatedCountCollection(withReadOptions(p))
+	if err != nil || col == nil {
+		return 0, false, err
+	}
+	opts := options.EstimatedDocumentCount()
+	if maxTime := queryMaxTime(p); maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	count, err := col.EstimatedDocumentCount(ctx, opts)
+	if err != nil {
+		return 0, false, maxTimeError(err)
+	}
+	return int(count), true, nil
+}
+
+// estimatedCountCollection returns the EstimatedCountCollection the wrapper Collections execute
+// the queries on, the clone with the read options of a readOptionsCollection, or nil if the
+// Collection doesn't implement it.
+func estimatedCountCollection(col Collection) (EstimatedCountCollection, error) {
+	switch wrapper := col.(type) {
+	case *registryCollection:
+		return estimatedCountCollection(wrapper.Collection)
+	case *readOptionsCollection:
+		clone, err := wrapper.clone()
+		if err != nil {
+			return nil, err
+		}
+		return estimatedCountCollection(clone)
+	case EstimatedCountCollection:
+		return wrapper, nil
+	}
+	return nil, nil
+}
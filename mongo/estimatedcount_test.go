@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// estimatingCollection estimates its number of documents as the number it holds plus an error,
+// telling the estimates apart from the exact counts.
+type estimatingCollection struct {
+	*fakeCollection
+	estimates int
+}
+
+func (c *estimatingCollection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	c.estimates++
+	return int64(len(c.docs) + 10), nil
+}
+
+func (c *estimatingCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	return c, nil
+}
+
+func TestFindEstimatedCount(t *testing.T) {
+	col := &estimatingCollection{fakeCollection: newFakeCollection(t, newItems("a", "b", "c")...)}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		CountTotal:     true,
+		EstimatedCount: true,
+	}
+
+	// The count of an empty query is estimated
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 13, cursor.Count)
+	require.True(t, cursor.Estimated)
+	require.Equal(t, 1, col.estimates)
+	require.Empty(t, col.countFilters)
+	data, err := json.Marshal(cursor)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"estimated":true`)
+
+	// As it is with read options
+	p.ReadPreference = readpref.Secondary()
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.True(t, cursor.Estimated)
+	require.Equal(t, 2, col.estimates)
+	p.ReadPreference = nil
+
+	// The documents of a filtered query are counted exactly
+	for _, filtered := range []FindParams{
+		{Query: primitive.M{"name": primitive.M{"$ne": "c"}}},
+		{Query: primitive.M{}, ExcludeIDs: []interface{}{col.docs[2]["_id"]}},
+		{Query: primitive.M{}, CountQuery: primitive.M{"name": primitive.M{"$ne": "c"}}},
+	} {
+		filtered.Collection, filtered.Limit, filtered.CountTotal, filtered.EstimatedCount = col, 2, true, true
+		col.queueCount(2)
+		cursor, err = Find(context.Background(), filtered, &results)
+		require.NoError(t, err)
+		require.Equal(t, 2, cursor.Count)
+		require.False(t, cursor.Estimated)
+		require.Equal(t, mustToM(t, countFilter(filtered, totalCountQueries(filtered))), col.countFilters[len(col.countFilters)-1])
+	}
+	require.Equal(t, 2, col.estimates)
+
+	// As are the ones of a collection which can't estimate its count, and without EstimatedCount
+	p.Collection = col.fakeCollection
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.False(t, cursor.Estimated)
+	p.Collection, p.EstimatedCount = col, false
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.False(t, cursor.Estimated)
+	require.Equal(t, 2, col.estimates)
+}
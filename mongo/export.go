@@ -0,0 +1,56 @@
+package mongo
+
+import (
+	"context"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ExportNDJSON writes the documents of all the pages starting at the page of the provided
+// FindParams to w as newline-delimited JSON, each document being a line of canonical Extended
+// JSON, and returns the number of documents written. The pages are fetched one at a time and w is
+// flushed after each page when it has a Flush method, like a *bufio.Writer or an
+// http.ResponseWriter, so the documents are streamed rather than buffered. The export stops with
+// the context error when the context is cancelled.
+func ExportNDJSON(ctx context.Context, p FindParams, w io.Writer) (int64, error) {
+	var written int64
+	for {
+		var page []bson.Raw
+		cursor, err := Find(ctx, p, &page)
+		if err != nil {
+			return written, err
+		}
+		for _, doc := range page {
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			line, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				return written, err
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if err := flush(w); err != nil {
+			return written, err
+		}
+		if !cursor.HasNext {
+			return written, nil
+		}
+		p.Next, p.Previous = cursor.Next, ""
+	}
+}
+
+// flush flushes the writer if it has a Flush method, returning an error or not.
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
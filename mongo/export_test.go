@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// flushRecorder records the content of the buffer each time it's flushed.
+type flushRecorder struct {
+	bytes.Buffer
+	flushes []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes = append(r.flushes, r.String())
+}
+
+func TestExportNDJSON(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+	var expected []string
+	for _, i := range items {
+		expected = append(expected, fmt.Sprintf(`{"_id":{"$oid":"%s"},"name":"%s"}`, i.(item).ID.Hex(), i.(item).Name))
+	}
+	// The canned documents don't keep the order of their fields
+	requireNDJSON := func(t *testing.T, expected []string, ndjson string) {
+		t.Helper()
+		require.True(t, strings.HasSuffix(ndjson, "\n"))
+		lines := strings.Split(strings.TrimSuffix(ndjson, "\n"), "\n")
+		require.Len(t, lines, len(expected))
+		for i, line := range lines {
+			require.JSONEq(t, expected[i], line)
+		}
+	}
+
+	// The documents are written in order, w being flushed after each page
+	col.queuePages(t, 2, items...)
+	var w flushRecorder
+	written, err := ExportNDJSON(context.Background(), p, &w)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), written)
+	requireNDJSON(t, expected, w.String())
+	require.Len(t, w.flushes, 3)
+	for i, size := range []int{2, 4, 5} {
+		requireNDJSON(t, expected[:size], w.flushes[i])
+	}
+
+	var out bytes.Buffer
+	bw := bufio.NewWriter(&out)
+	col.queuePages(t, 2, items...)
+	written, err = ExportNDJSON(context.Background(), p, bw)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), written)
+	requireNDJSON(t, expected, out.String())
+
+	// The export stops when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	written, err = ExportNDJSON(ctx, p, &out)
+	require.Equal(t, context.Canceled, err)
+	require.Zero(t, written)
+}
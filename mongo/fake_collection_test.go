@@ -0,0 +1,286 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// fakeCollection is a Collection returning canned documents. It records the filter and the
+	// options of every call so tests can assert on the queries sent to mongo, the results mongo
+	// returns for them being checked by the integration tests.
+	fakeCollection struct {
+		// The documents returned by Find and Aggregate, at most the limit of the query, when no
+		// page is queued
+		docs []bson.M
+		// The documents returned by the next calls to Find and Aggregate, in order
+		pages [][]bson.M
+		// The counts returned by the next calls to CountDocuments and the aggregates ending with a
+		// $count stage, the number of docs when none is queued. As mongo, the aggregates output no
+		// document for a count of 0
+		counts []int64
+
+		findFilters  []bson.M
+		findOptions  []*options.FindOptions
+		countFilters []bson.M
+		countOptions []*options.CountOptions
+		pipelines    [][]bson.D
+		aggOptions   []*options.AggregateOptions
+	}
+
+	fakeCursor struct {
+		docs    []bson.Raw
+		current int
+		err     error
+	}
+)
+
+func newFakeCollection(t testingT, docs ...interface{}) *fakeCollection {
+	c := &fakeCollection{}
+	for _, doc := range docs {
+		c.insert(t, doc)
+	}
+	return c
+}
+
+type testingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+func (c *fakeCollection) insert(t testingT, doc interface{}) {
+	t.Helper()
+	c.docs = append(c.docs, mustToM(t, doc))
+}
+
+// queuePage queues the documents returned by the next call to Find or Aggregate not answered by a
+// page queued before.
+func (c *fakeCollection) queuePage(t testingT, docs ...interface{}) {
+	t.Helper()
+	page := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		page = append(page, mustToM(t, doc))
+	}
+	c.pages = append(c.pages, page)
+}
+
+// queuePages queues the pages returned when walking the documents forward by pages of limit
+// documents, each of them followed by the first document of the next page.
+func (c *fakeCollection) queuePages(t testingT, limit int, docs ...interface{}) {
+	t.Helper()
+	for start := 0; start < len(docs); start += limit {
+		end := start + limit + 1
+		if end > len(docs) {
+			end = len(docs)
+		}
+		c.queuePage(t, docs[start:end]...)
+	}
+}
+
+// queueCount queues the counts returned by the next calls to CountDocuments and the aggregates
+// ending with a $count stage.
+func (c *fakeCollection) queueCount(counts ...int64) {
+	c.counts = append(c.counts, counts...)
+}
+
+func (c *fakeCollection) nextPage(limit int64) []bson.M {
+	if len(c.pages) > 0 {
+		page := c.pages[0]
+		c.pages = c.pages[1:]
+		return page
+	}
+	if limit > 0 && int(limit) < len(c.docs) {
+		return c.docs[:limit]
+	}
+	return c.docs
+}
+
+func (c *fakeCollection) nextCount() int64 {
+	if len(c.counts) > 0 {
+		count := c.counts[0]
+		c.counts = c.counts[1:]
+		return count
+	}
+	return int64(len(c.docs))
+}
+
+func (c *fakeCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f, err := toM(filter)
+	if err != nil {
+		return 0, err
+	}
+	c.countFilters = append(c.countFilters, f)
+	c.countOptions = append(c.countOptions, options.MergeCountOptions(opts...))
+	return c.nextCount(), nil
+}
+
+func (c *fakeCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := toM(filter)
+	if err != nil {
+		return nil, err
+	}
+	c.findFilters = append(c.findFilters, f)
+	o := options.MergeFindOptions(opts...)
+	c.findOptions = append(c.findOptions, o)
+	var limit int64
+	if o.Limit != nil {
+		limit = *o.Limit
+	}
+	return newFakeCursor(c.nextPage(limit))
+}
+
+func (c *fakeCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (MongoCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Decode the stages as ordered documents to keep the order of the sort fields
+	var wrapped struct{ Pipeline []bson.D }
+	data, err := bson.Marshal(bson.M{"pipeline": pipeline})
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	stages := wrapped.Pipeline
+	c.pipelines = append(c.pipelines, stages)
+	c.aggOptions = append(c.aggOptions, options.MergeAggregateOptions(opts...))
+
+	var limit int64
+	if len(stages) > 0 {
+		last := stages[len(stages)-1][0]
+		switch last.Key {
+		case "$count":
+			if count := c.nextCount(); count > 0 {
+				return newFakeCursor([]bson.M{{last.Value.(string): count}})
+			}
+			return newFakeCursor(nil)
+		case "$limit":
+			limit = reflect.ValueOf(last.Value).Int()
+		}
+	}
+	return newFakeCursor(c.nextPage(limit))
+}
+
+func newFakeCursor(docs []bson.M) (*fakeCursor, error) {
+	cursor := &fakeCursor{current: -1}
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		cursor.docs = append(cursor.docs, raw)
+	}
+	return cursor, nil
+}
+
+func (c *fakeCursor) Close(context.Context) error { return nil }
+
+func (c *fakeCursor) Decode(v interface{}) error {
+	if c.current < 0 || c.current >= len(c.docs) {
+		return errors.New("no current document")
+	}
+	return bson.Unmarshal(c.docs[c.current], v)
+}
+
+func (c *fakeCursor) ID() int64 { return 0 }
+
+func (c *fakeCursor) Next(ctx context.Context) bool {
+	if c.err = ctx.Err(); c.err != nil {
+		return false
+	}
+	c.current++
+	return c.current < len(c.docs)
+}
+
+func (c *fakeCursor) TryNext(ctx context.Context) bool { return c.Next(ctx) }
+
+func (c *fakeCursor) Err() error { return c.err }
+
+func (c *fakeCursor) All(ctx context.Context, results interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	sliceVal := reflect.ValueOf(results).Elem()
+	elemType := sliceVal.Type().Elem()
+	sliceVal = sliceVal.Slice(0, 0)
+	for _, raw := range c.docs {
+		elem := reflect.New(elemType)
+		if err := bson.Unmarshal(raw, elem.Interface()); err != nil {
+			return err
+		}
+		sliceVal = reflect.Append(sliceVal, elem.Elem())
+	}
+	reflect.ValueOf(results).Elem().Set(sliceVal)
+	return nil
+}
+
+func (c *fakeCursor) RemainingBatchLength() int { return len(c.docs) - c.current - 1 }
+
+// stage returns the stage of a recorded pipeline.
+func stage(t *testing.T, name string, spec interface{}) bson.D {
+	t.Helper()
+	d, err := toD(bson.M{name: spec})
+	require.NoError(t, err)
+	return d
+}
+
+// requireStages asserts the stages of a recorded pipeline are equal. The keys of the stages are
+// compared regardless of their order but for the ones of the $sort stages.
+func requireStages(t *testing.T, expected, actual []bson.D) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		requireStage(t, expected[i], actual[i])
+	}
+}
+
+func requireStage(t *testing.T, expected, actual bson.D) {
+	t.Helper()
+	if len(expected) == 1 && expected[0].Key == "$sort" {
+		require.Equal(t, expected, actual)
+		return
+	}
+	require.Equal(t, mustToM(t, expected), mustToM(t, actual))
+}
+
+func mustToM(t testingT, doc interface{}) bson.M {
+	t.Helper()
+	m, err := toM(doc)
+	if err != nil {
+		t.Fatalf("could not convert document %v: %s", doc, err)
+	}
+	return m
+}
+
+func toM(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m bson.M
+	err = bson.Unmarshal(data, &m)
+	return m, err
+}
+
+func toD(v interface{}) (bson.D, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var d bson.D
+	err = bson.Unmarshal(data, &d)
+	return d, err
+}
@@ -4,18 +4,30 @@ package mongo
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	mcpbson "github.com/qlik-oss/mongocursorpagination/bson"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 type (
+	// MongoCursor is the cursor over the documents returned by the queries of a Collection, e.g. a
+	// *mongo.Cursor of the driver.
 	MongoCursor interface {
 		Close(context.Context) error
 		Decode(interface{}) error
@@ -26,6 +38,12 @@ type (
 		All(context.Context, interface{}) error
 		RemainingBatchLength() int
 	}
+	// Collection executes the count and find queries of Find, e.g. a *mongo.Collection of the
+	// driver wrapped to return its *mongo.Cursor as a MongoCursor. It's the supported seam to
+	// unit test the handlers paginating with Find: a Collection returning canned documents and
+	// counts needs no mongo database. Find passes the limit of the query in the FindOptions, one
+	// more than the Limit of the FindParams to see if there's another page, and the Collection
+	// must not add to it.
 	Collection interface {
 		CountDocuments(context.Context, interface{}, ...*options.CountOptions) (int64, error)
 		Find(context.Context, interface{}, ...*options.FindOptions) (MongoCursor, error)
@@ -37,8 +55,33 @@ type (
 
 		// The find query to augment with pagination
 		Query primitive.M
+		// When set, the query counting the documents when CountTotal is true, in place of Query,
+		// e.g. a cheaper query using an index when Query holds a regex. The count is then
+		// approximate if the queries don't match the same documents
+		CountQuery primitive.M
+		// The _id of documents to leave out of the results, e.g. already seen or blocked documents.
+		// This is ANDed with the query and the cursor boundary, so excluding documents doesn't
+		// cause other documents to be skipped across pages
+		ExcludeIDs []interface{}
+		// When set, only the documents created at or before the snapshot time are paginated over, so
+		// documents inserted while a client traverses the pages are consistently left out. The
+		// creation time is read from SnapshotField
+		SnapshotTime time.Time
+		// The field holding the creation time of the documents, compared against SnapshotTime.
+		// Defaults to _id, whose ObjectID timestamp has a precision of one second
+		SnapshotField string
+		// The field of a TTL index, holding the time at which documents expire. When set along with
+		// SnapshotTime, documents which had expired at the snapshot time are left out of every page,
+		// whether or not the TTL monitor has removed them yet. Documents expiring after the snapshot
+		// time are included until they are removed: pages may then have fewer documents than the
+		// limit and a page reported as having a next page may be followed by an empty one, but no
+		// remaining document is skipped
+		ExpiryField string
 		// The number of results to fetch, should be > 0
 		Limit int64
+		// When set, the largest Limit, a greater Limit being clamped to it rather than returning an
+		// error, e.g. when the Limit is passed by clients. The Cursor holds the EffectiveLimit
+		MaxLimit int64
 		// true, if the results should be sort ascending, false otherwise
 		SortAscending bool
 		// The name of the mongo collection field being paginated and sorted on. This field must:
@@ -46,7 +89,8 @@ type (
 		//    exist, the results will be secondarily ordered by the _id
 		// 2. Be indexed. For large collections, this should be indexed for query performance
 		// 3. Be immutable. If the value changes between paged queries, it could appear twice
-		// 4. Match the bson field name the result struct. e.g.:
+		// 4. Match the bson field name the result struct, Find returning ErrUnknownPaginatedField
+		//    otherwise when the results are structs. e.g.:
 		//
 		//    PaginatedField would be "name" when paginating employees by name
 		//
@@ -55,8 +99,55 @@ type (
 		//        Name        string        `bson:"name"`
 		//    }
 		//
+		// This is the same as a PaginatedFields holding this field sorted according to SortAscending.
+		// The field may hold null or missing values, which sort before any other value like they do
+		// in MongoDB, unless TreatMissingAsLast is true. A time.Time is stored, and held by the
+		// cursors, at the millisecond precision of a BSON datetime, so documents created within the
+		// same millisecond are ordered by the TieBreakerFields. TextScore paginates the documents
+		// of a $text query on their text score
 		PaginatedField string
-		Collation      *options.Collation
+		// The fields being paginated and sorted on, in order, each with its own sort direction. When
+		// set, this takes precedence over PaginatedField. The fields may hold null or missing
+		// values, which sort before any other value like they do in MongoDB. The tie-breaker fields
+		// are sorted after these fields according to SortAscending
+		PaginatedFields []SortField
+		// When true, the documents whose PaginatedField, or first PaginatedFields field, is null or
+		// missing come after the other documents, sorted on the tie-breaker fields, whatever the
+		// sort direction, e.g. so undated documents come last. The page queries then query the
+		// documents holding the field and the ones missing it separately. When the field is sorted
+		// ascending, it can't be used along with ComputeRanks, BidirectionalProbe and
+		// SkipFallbackThreshold, Find returning an error matching ErrIncompatibleOptions
+		TreatMissingAsLast bool
+		// When true, the PaginatedField holds arrays, e.g. tags, which mongo sorts on their smallest
+		// element when sorting ascending and on their largest element when sorting descending. The
+		// cursors then hold that element rather than the whole array, and the cursor queries
+		// compare the arrays with it the way the sort does. The arrays must be non empty and their
+		// elements of a single BSON type, compared without the Collation to find the sort key. The
+		// page of a Previous cursor is queried in the sort order after counting the documents
+		// before the cursor, which takes an additional count query. It can't be used along with
+		// PaginatedFields, TreatMissingAsLast, MaxBytes and SkipFallbackThreshold, Find returning an
+		// error matching ErrIncompatibleOptions
+		ArrayPaginatedField bool
+		// The fields used, in order, to sort documents sharing the same PaginatedField value. Together
+		// with PaginatedField they must uniquely identify a document. The fields may be of different
+		// BSON types, e.g. a string region followed by an integer sequence number.
+		// Defaults to _id
+		TieBreakerFields []string
+		// When set, the TieBreakerFields are sorted in this direction rather than according to
+		// SortAscending, e.g. to sort ascending by score with the newest _id first among ties
+		IDSortAscending *bool
+		// When set, it returns the query comparing a sorted field to its cursor value in the cursor
+		// queries, e.g. to compare a computed key with an $expr query. It must order the documents
+		// the same way as the sort of the page query. Defaults to mcpbson.NullableComparison,
+		// comparing the values with $gt, $gte, $lt, $lte and $eq, null and missing values being
+		// less than any other value
+		Comparison mcpbson.Comparison
+		// The collation of the sort, e.g. a strength of 2 to paginate case-insensitively. It's set
+		// on the find and count queries, so the server evaluates the comparisons of the cursor
+		// queries with it: documents whose values are equal under the collation are ordered, and
+		// split between pages, by the TieBreakerFields, and none is repeated or skipped.
+		// Ignored when paginating by the TieBreakerFields only
+		Collation *options.Collation
 		// The value to start querying the page
 		Next string
 		// The value to start querying previous page
@@ -64,22 +155,257 @@ type (
 		// Whether or not to include total count of documents matching filter in the cursor
 		// Specifying true makes an additional query
 		CountTotal bool
+		// When true, the count of an empty query is estimated from the metadata of the collection
+		// rather than by scanning it, which requires the Collection to implement
+		// EstimatedCountCollection. The documents are counted exactly when the query filters them,
+		// the estimate ignoring filters, or when the Collection doesn't implement it
+		EstimatedCount bool
+		// When set, the generated cursors expire after this duration and are then rejected with
+		// ErrCursorExpired
+		CursorTTL time.Duration
+		// When true, the boundary document of a Next or Previous cursor that wasn't generated by Find
+		// with this option, e.g. a checkpoint cursor of the next document to process, is included in
+		// the page. The cursors generated by Find exclude their boundary document as usual, so when
+		// resuming a processing from a checkpoint the boundary document is processed exactly once
+		IncludeFirstBoundary bool
+		// When set, each returned document holds its sort key in this field, which the result type
+		// must map with a bson tag. The sort key is an array holding a key for each of the sorted
+		// fields, in order. When a collation is applied the key of a string is its collation key,
+		// so clients merging several paginated streams can order documents consistently with the
+		// server by comparing the keys element by element, strings being compared bytewise.
+		// Requires MongoDB 4.4 or later
+		SortKeyField string
+		// When set, the find and count queries are tagged with this comment, which shows up in the
+		// database profiler and the slow query logs, e.g. to correlate a query with a trace
+		QueryComment string
+		// When set, documents stop being added to the page once their cumulative BSON size would
+		// exceed this number of bytes, capping the size of the page whatever the number of
+		// documents. The page holds at least one document, even one larger than MaxBytes, and its
+		// cursors resume right after the documents it holds
+		MaxBytes int64
+		// When set, pages of a Next or Previous cursor are queried by skipping the documents before
+		// the cursor, rather than with the keyset query, when fewer documents than this threshold
+		// match the query. This takes an additional count query, even when CountTotal is false, and
+		// the cursors are the same either way
+		SkipFallbackThreshold int64
+		// When set, the page is queried by skipping this number of documents rather than with a
+		// cursor, e.g. for numbered pages of bounded admin data. The Cursor then has no cursors,
+		// HasPrevious being true and HasNext telling whether there are documents after the page.
+		// It can't be used along with a Next or Previous cursor
+		Offset int64
+		// When set, the results passed to Find must be a *[]interface{} and each document is decoded
+		// into the type registered in DiscriminatorTypes for the string value of this field, e.g.
+		// "type" in a collection of documents of different shapes
+		DiscriminatorField string
+		// The types to decode the documents into, by value of the DiscriminatorField
+		DiscriminatorTypes map[string]reflect.Type
+		// When set, the cursors which can't be decoded are decoded with this function instead, e.g.
+		// to keep accepting the cursors of a previous format during a migration. The error of the
+		// cursor decoding is returned when both decodings fail
+		LegacyCursorDecoder func(string) (bson.D, error)
+		// When set, the cursors hold the depth of their page, the first page having a depth of 1,
+		// and the pages deeper than this limit are rejected with ErrMaxDepthExceeded, e.g. to make
+		// scrapers query again rather than walk millions of pages
+		MaxPageDepth int
+		// When true, the end of the page opposite to the cursor is probed so HasPrevious is accurate
+		// for the page of a Next cursor and HasNext for the page of a Previous cursor, which are
+		// otherwise assumed to be true, e.g. when the documents before the page were deleted since
+		// the cursor was generated. This takes an additional find query of at most one _id
+		BidirectionalProbe bool
+		// When true along with CountTotal, the rank of each document of the page within all the
+		// documents matching the query is returned in the Cursor, e.g. for "item N of M" displays.
+		// This takes an additional count of the documents before the page
+		ComputeRanks bool
+		// When true, the cursors are generated with a canonical encoding which pins the byte layout
+		// of each supported value type, rather than with the BSON marshaler of the driver, so the
+		// outstanding cursors stay valid across driver upgrades. The cursors start with a version
+		// byte and both encodings are accepted whatever this option. Only the double, string,
+		// ObjectID, boolean, datetime, null, int32, timestamp, int64 and decimal128 value types are
+		// supported
+		CanonicalCursor bool
+		// The encoding of the generated cursors, CursorFormatBSON by default. CanonicalCursor takes
+		// precedence over it
+		CursorFormat CursorFormat
+		// When true along with CountTotal, the filter of the count query is returned in the Cursor,
+		// e.g. to diagnose a count differing from expectations
+		ReturnCountFilter bool
+		// When set, the cursors are signed with an HMAC-SHA256 using this secret, and the cursors
+		// whose signature doesn't match are rejected with ErrCursorTampered, so cursors can be safely
+		// accepted from untrusted clients. The signature is part of the cursor, which stays a single
+		// url safe string
+		CursorSecret []byte
+		// When set, only the fields of this projection are returned, e.g. bson.M{"name": 1} or
+		// bson.M{"body": 0}. The sorted fields and the _id are always returned by mongo since the
+		// cursors are generated from them, and are removed from the results when the projection
+		// leaves them out
+		Projection bson.M
+		// The index to use for the find and count queries, either the name of the index or its key
+		// document, e.g. bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, so the index
+		// matching the sort is used whatever the query planner would pick
+		Hint interface{}
+		// When set, the Cursor holds the PageSignature of the page computed from the _id and the
+		// value of this field of each of its documents, e.g. "updatedAt" or a version number
+		// incremented on every update, so polling clients can tell whether the page changed
+		VersionField string
+		// When true, the cursors hold a hash of the query, and the cursors generated under another
+		// query are rejected with ErrCursorFilterMismatch, e.g. when paging the same collection in
+		// tabs with different filters. Cursors without a hash, e.g. generated without this option,
+		// are accepted
+		BindCursorsToFilter bool
+		// When true, the cursors generated by Find hold the operation time of the session of the
+		// context, and the queries of the page of such a cursor read at or after that time, giving
+		// read-your-writes consistency across pages, even when reading from secondaries. This
+		// requires the context to be the context of a causally consistent session, e.g. started with
+		// options.Session().SetCausalConsistency(true); the cursors don't hold any operation time
+		// without a session
+		CausalCursors bool
+		// When true, the Next cursors generated by a PageIterator hold only the differences of the
+		// integer and datetime values of the sorted fields from the ones of the Next cursor of the
+		// page, making tiny cursors for deep traversals. A delta cursor can only be decoded by the
+		// PageIterator, whose ResumeCursor is always encoded in full. The cursors are encoded in
+		// full when a sorted field holds another type, or with a CursorTTL, a CursorSecret or any
+		// option adding metadata to the cursors
+		DeltaCursors bool
+		// When true along with CountTotal, the Cursor holds a CountToken of the count, and the count
+		// held by the CountToken passed back is reused rather than counting the documents again,
+		// e.g. on large collections which rarely change. The CountToken is ignored when it was
+		// computed for another query
+		ReuseCount bool
+		// The CountToken of the Cursor of a previous page, used when ReuseCount is true
+		CountToken string
+		// The read preference and read concern of the find and count queries, e.g. to read from
+		// secondaries. When nil, the ones of the Collection are used. The Collection must implement
+		// CloneableCollection when either is set
+		ReadPreference *readpref.ReadPref
+		ReadConcern    *readconcern.ReadConcern
+		// When set, the maximum execution time of each of the find and count queries, past which
+		// mongo aborts the query and Find returns an error wrapping ErrMaxTimeExceeded, so a bad
+		// index choice can't tie up the server
+		MaxTime time.Duration
+		// When set, the number of documents of each batch of the find query's cursor, trading
+		// network round trips for memory on large pages. The driver's default when 0
+		BatchSize int32
+		// When true and BatchSize is 0, the batch size of the find query is its limit, the Limit
+		// plus the additional document telling whether there's a next page, so the page and that
+		// document are returned in a single batch
+		LookaheadBatch bool
+		// When true, mongo may write temporary files to sort the documents of the find query, e.g.
+		// when sorting on a field which isn't indexed exceeds the memory limit of in-memory sorts
+		AllowDiskUse bool
+		// When set, the base options of the find query, e.g. to set options FindParams has no field
+		// for. The sort and limit of the query take precedence over the ones of the base options, as
+		// do the skip, projection, collation, comment, hint, max time and batch size the FindParams
+		// set
+		FindOptions *options.FindOptions
+		// When set, the registry decoding the documents of the page into the results, and encoding
+		// the results to generate their cursors, e.g. the registry of the client when it holds the
+		// codecs of custom types. The default registry when nil
+		Registry *bsoncodec.Registry
+		// When true, the page query is sorted in natural order, {$natural: 1} or {$natural: -1}
+		// when SortAscending is false, rather than on the _id, e.g. to page through a capped
+		// collection in insertion order. The cursors hold the _id of their document as when
+		// paginating on the _id, so the natural order must match the order of the _id: the _id of
+		// each document must be greater than the ones of the documents inserted before it, which
+		// is the case of ObjectIDs generated by a single process, or of increasing sequence
+		// numbers. Documents of a capped collection removed since a cursor was generated are
+		// skipped without error. The PaginatedField, when set, and the TieBreakerFields must be
+		// _id, and the queries of BidirectionalProbe, ComputeRanks and SkipFallbackThreshold are
+		// still sorted on the _id
+		NaturalOrder bool
+		// When true, Find returns ErrNoSupportingIndex rather than querying the page unless an
+		// index of the Collection starts with the sorted fields in the sort order or its reverse,
+		// e.g. {name: 1, _id: 1} when paginating by name, so a new sort field can't silently turn
+		// into collection scans. The Collection must implement IndexedCollection. A supported sort
+		// is cached for a minute, so the indexes aren't listed on every page query
+		RequireIndex bool
+
+		// The maximum execution time of the queries, set by a PageIterator from its budget
+		maxTime time.Duration
+		// The operation time of the session once the page query was executed, when CausalCursors
+		// is true
+		operationTime *primitive.Timestamp
+		// Whether the count of the page was estimated, when EstimatedCount is true
+		estimatedCount bool
+		// The values of the boundary the Next delta cursor is relative to, set by a PageIterator
+		deltaBase []interface{}
+		// The Next or Previous cursor of the page, parsed when building the page queries
+		parsed *parsedCursor
+		// When set, receives the cursor of each document of the page, in order, generated from
+		// the documents as queried, before the fields hidden by the Projection are removed. Set
+		// by FindEdges
+		nodeCursors *[]string
+		// Whether the page of the cursor includes its boundary document, whatever the
+		// IncludeFirstBoundary, set by FindAround to query the anchor
+		includeBoundary bool
+	}
+
+	// SortField is a field being paginated and sorted on, with its sort direction.
+	SortField struct {
+		// The name of the field
+		Name string
+		// true if the results are sorted ascending on the field, false if descending
+		Ascending bool
+	}
+
+	// parsedCursor holds the field values and the metadata elements of a parsed cursor, both
+	// empty when the FindParams have neither a Next nor a Previous cursor, along with the hash
+	// of the filter the cursors are bound to when BindCursorsToFilter is true.
+	parsedCursor struct {
+		values     []interface{}
+		metadata   bson.D
+		filterHash string
 	}
 
-	// Cursor holds the pagination data about the find mongo query that was performed.
+	// Cursor holds the pagination data about the find mongo query that was performed. It marshals
+	// to JSON with camelCase field names, leaving out the empty cursors and the fields which weren't
+	// computed.
 	Cursor struct {
 		// The URL safe previous page cursor to pass in a Find call to get the previous page.
-		// This is set to the empty string if there is no previous page.
-		Previous string
+		// This is set to the empty string if there is no previous page. It is generated from the
+		// first document of the page, whether the page was queried with a Next or a Previous
+		// cursor, so clients can navigate both ways from any page.
+		Previous string `json:"previous,omitempty"`
 		// The URL safe next page cursor to pass in a Find call to get the next page.
-		// This is set to the empty string if there is no next page.
-		Next string
+		// This is set to the empty string if there is no next page. It is generated from the last
+		// document of the page, whether the page was queried with a Next or a Previous cursor.
+		Next string `json:"next,omitempty"`
 		// true if there is a previous page, false otherwise
-		HasPrevious bool
+		HasPrevious bool `json:"hasPrevious"`
 		// true if there is a next page, false otherwise
-		HasNext bool
-		// Total count of documents matching filter - only computed if CountTotal is True
-		Count int
+		HasNext bool `json:"hasNext"`
+		// The cursors of the first and last documents of the page, e.g. to bookmark the page. Unlike
+		// Previous and Next, they're set even when there's no page before or after this one, and
+		// are only empty when the page is empty or when their document misses a field the cursors
+		// hold, which fails Find only when the cursor is also the Previous or Next one
+		StartCursor string `json:"startCursor,omitempty"`
+		EndCursor   string `json:"endCursor,omitempty"`
+		// The number of documents of the page, at most the Limit, e.g. to tell how many documents
+		// FindStream streamed
+		Returned int64 `json:"returned"`
+		// The Limit the page was queried with, the MaxLimit of the FindParams when their Limit
+		// exceeded it
+		EffectiveLimit int64 `json:"effectiveLimit"`
+		// Total count of documents matching filter - only computed if CountTotal is True. It's the
+		// count of all the pages, independent of the Limit and of the cursor of the page
+		Count int `json:"count"`
+		// true if the Count is the estimate of the number of documents of the collection, which is
+		// only the case with EstimatedCount
+		Estimated bool `json:"estimated,omitempty"`
+		// The collation that was applied to the query, nil if none was. Clients mirroring the
+		// ordering of the results should compare values using this collation.
+		Collation *options.Collation `json:"collation,omitempty"`
+		// The 1-based rank of each document of the page within all the documents matching the query,
+		// in order - only computed if CountTotal and ComputeRanks are true
+		Ranks []int `json:"ranks,omitempty"`
+		// The filter passed to CountDocuments to compute Count, which selects the documents matching
+		// the query regardless of the page - only set if CountTotal and ReturnCountFilter are true
+		CountFilter bson.M `json:"countFilter,omitempty"`
+		// A signature of the documents of the page, which changes when a document is inserted in,
+		// removed from or updated within the page - only computed if VersionField is set
+		PageSignature string `json:"pageSignature,omitempty"`
+		// An opaque token holding Count and the hash of the query, to pass in the next Find call to
+		// reuse the count - only set if CountTotal and ReuseCount are true
+		CountToken string `json:"countToken,omitempty"`
 	}
 
 	CursorError struct {
@@ -87,121 +413,698 @@ type (
 	}
 )
 
+// ErrCursorShapeMismatch matches, using errors.Is, the errors returned when a cursor passed to Find
+// doesn't hold a value for each of the sorted fields, e.g. a cursor generated before the sort of the
+// FindParams changed, so the pagination can be reset rather than failing
+var ErrCursorShapeMismatch = errors.New("the cursor doesn't match the sort")
+
+// cursorShapeError is the error of a cursor not holding a value for each of the sorted fields. It
+// matches ErrCursorShapeMismatch while keeping the message telling the expected shape.
+type cursorShapeError struct {
+	msg string
+}
+
+func (e *cursorShapeError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the target is ErrCursorShapeMismatch
+func (e *cursorShapeError) Is(target error) bool {
+	return target == ErrCursorShapeMismatch
+}
+
+// ErrCursorExpired is the error returned when parsing a cursor whose TTL has elapsed
+var ErrCursorExpired = errors.New("cursor expired")
+
+// The keys of the cursor metadata elements. Cursor metadata keys are prefixed with $ so they can't
+// clash with field names.
+const (
+	// The cursor's expiry time
+	cursorExpiryKey = "$exp"
+	// Set on the cursors generated by Find when IncludeFirstBoundary is true
+	cursorGeneratedKey = "$gen"
+	// The depth of the page the cursor was generated from, when MaxPageDepth is set
+	cursorDepthKey = "$depth"
+	// The hash of the query the cursor was generated under, when BindCursorsToFilter is set
+	cursorFilterHashKey = "$fh"
+	// The operation time of the session the cursor was generated in, when CausalCursors is set
+	cursorOperationTimeKey = "$ot"
+)
+
+// ErrCursorFilterMismatch is the error returned when parsing a cursor generated under another query
+// than the one of the FindParams, when BindCursorsToFilter is set
+var ErrCursorFilterMismatch = errors.New("cursor was generated for another filter")
+
+// ErrMaxDepthExceeded is the error returned when querying a page deeper than the MaxPageDepth
+var ErrMaxDepthExceeded = errors.New("maximum page depth exceeded")
+
+// timeNow returns the current time, it is a variable so tests can control the clock
+var timeNow = time.Now
+
+// ErrInvalidResultsType is the error returned when the results passed to Find aren't a non nil
+// pointer to a slice
+var ErrInvalidResultsType = errors.New("results must be a non nil pointer to a slice")
+
+// The errors returned when the parameters passed to Find are invalid
+var (
+	ErrNilResults    = errors.New("results can't be nil")
+	ErrNilCollection = errors.New("Collection can't be nil")
+	ErrLimitTooSmall = errors.New("a limit of at least 1 is required")
+)
+
+// ErrUnknownPaginatedField is the error returned when the PaginatedField passed to Find isn't a
+// field of the struct type of the results
+var ErrUnknownPaginatedField = errors.New("the paginated field isn't a field of the results")
+
+// The errors returned when the Offset passed to Find is invalid
+var (
+	ErrNegativeOffset   = errors.New("the offset can't be negative")
+	ErrOffsetWithCursor = errors.New("an offset can't be used along with a Next or Previous cursor")
+)
+
+// ErrNaturalOrderSort is the error returned when the FindParams passed to Find with NaturalOrder
+// are sorted on another field than the _id
+var ErrNaturalOrderSort = errors.New("NaturalOrder requires paginating on the _id only")
+
+// ErrIncompatibleOptions matches, using errors.Is, the errors returned when the FindParams passed
+// to Find set options which can't be used together, e.g. a Projection along with the TextScore
+// PaginatedField
+var ErrIncompatibleOptions = errors.New("incompatible options")
+
+// findOption is an option of the FindParams, along with whether it is set.
+type findOption struct {
+	name string
+	set  bool
+}
+
+// incompatibleOptions returns an error matching ErrIncompatibleOptions naming the first of the
+// options which is set, none of them being usable along with the feature, or nil if none is set.
+func incompatibleOptions(feature string, options ...findOption) error {
+	for _, option := range options {
+		if option.set {
+			return fmt.Errorf("%w: %s can't be used along with %s", ErrIncompatibleOptions, option.name, feature)
+		}
+	}
+	return nil
+}
+
+// ErrBadCursor matches, using errors.Is, the errors returned when a cursor passed to Find is
+// malformed or can't be used, i.e. all the CursorErrors
+var ErrBadCursor = errors.New("bad cursor")
+
 func (e *CursorError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the cursor error
+func (e *CursorError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is ErrBadCursor, so errors.Is can tell the cursor errors apart
+func (e *CursorError) Is(target error) bool {
+	return target == ErrBadCursor
+}
+
+// RedactedID returns a short non reversible hash of the Previous and Next cursors, identifying the
+// cursor in logs without revealing the field values it holds. Identical cursors have the same
+// RedactedID.
+func (c Cursor) RedactedID() string {
+	h := sha256.New()
+	h.Write([]byte(c.Previous))
+	// Separate the cursors so moving characters from one to the other changes the hash
+	h.Write([]byte{0})
+	h.Write([]byte(c.Next))
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
 // BuildQueries builds the queries without executing them
 func BuildQueries(ctx context.Context, p FindParams) (queries []bson.M, sort bson.D, err error) {
-	if p.PaginatedField == "" {
-		p.PaginatedField = "_id"
-		p.Collation = nil
-	}
-	shouldSecondarySortOnID := p.PaginatedField != "_id"
+	queries, sort, _, err = buildQueries(ctx, p)
+	return queries, sort, err
+}
+
+// buildQueries is BuildQueries also returning the Next or Previous cursor it parsed, from which
+// the cursors of the page are generated.
+func buildQueries(ctx context.Context, p FindParams) ([]bson.M, bson.D, *parsedCursor, error) {
+	p = ensureDefaults(p)
 
 	if p.Collection == nil {
-		return []bson.M{}, nil, errors.New("Collection can't be nil")
+		return []bson.M{}, nil, nil, ErrNilCollection
 	}
 
 	if p.Limit <= 0 {
-		return []bson.M{}, nil, errors.New("a limit of at least 1 is required")
+		return []bson.M{}, nil, nil, ErrLimitTooSmall
 	}
 
-	nextCursorValues, err := parseCursor(p.Next, shouldSecondarySortOnID)
-	if err != nil {
-		return []bson.M{}, nil, &CursorError{fmt.Errorf("next cursor parse failed: %s", err)}
+	if p.Offset < 0 {
+		return []bson.M{}, nil, nil, ErrNegativeOffset
 	}
 
-	previousCursorValues, err := parseCursor(p.Previous, shouldSecondarySortOnID)
-	if err != nil {
-		return []bson.M{}, nil, &CursorError{fmt.Errorf("previous cursor parse failed: %s", err)}
+	if p.Offset > 0 && (p.Next != "" || p.Previous != "") {
+		return []bson.M{}, nil, nil, ErrOffsetWithCursor
 	}
 
-	// Figure out the sort direction and comparison operator that will be used in the augmented query
-	sortAsc := (!p.SortAscending && p.Previous != "") || (p.SortAscending && p.Previous == "")
-	comparisonOp := "$gt"
-	sortDir := 1
-	if !sortAsc {
-		comparisonOp = "$lt"
-		sortDir = -1
+	if spec := sortSpec(p); p.NaturalOrder && (len(spec) != 1 || spec[0].Name != "_id") {
+		return []bson.M{}, nil, nil, ErrNaturalOrderSort
+	}
+
+	if err := validateOptions(p); err != nil {
+		return []bson.M{}, nil, nil, err
 	}
 
 	// Augment the specified find query with cursor data
-	queries = []bson.M{p.Query}
+	p, err := withParsedCursor(p)
+	if err != nil {
+		return []bson.M{}, nil, nil, err
+	}
+	queries := baseQueries(p)
+	cursorQuery, sort, err := cursorQueryAndSort(p)
+	if err != nil {
+		return []bson.M{}, nil, nil, err
+	}
+	if cursorQuery != nil {
+		queries = append(queries, cursorQuery)
+	}
+
+	return queries, sort, p.parsed, nil
+}
+
+// validateOptions returns an error matching ErrIncompatibleOptions if the FindParams set options
+// which can't be used together.
+func validateOptions(p FindParams) error {
+	if p.ArrayPaginatedField {
+		if err := incompatibleOptions("ArrayPaginatedField",
+			findOption{"PaginatedFields", len(p.PaginatedFields) > 0},
+			findOption{"TreatMissingAsLast", p.TreatMissingAsLast},
+			findOption{"MaxBytes", p.MaxBytes > 0},
+			findOption{"SkipFallbackThreshold", p.SkipFallbackThreshold > 0},
+		); err != nil {
+			return err
+		}
+	}
+	if missingLast(p) {
+		// The documents missing the field are counted and probed as if they came first
+		return incompatibleOptions("TreatMissingAsLast",
+			findOption{"ComputeRanks", p.ComputeRanks},
+			findOption{"BidirectionalProbe", p.BidirectionalProbe},
+			findOption{"SkipFallbackThreshold", p.SkipFallbackThreshold > 0},
+		)
+	}
+	return nil
+}
+
+// cursorQueryAndSort returns the query selecting the documents of the page after the Next cursor
+// or before the Previous cursor, nil when neither is set, and the sort of the page query.
+func cursorQueryAndSort(p FindParams) (cursorQuery bson.M, sort bson.D, err error) {
+	fields := sortFields(p)
+
+	p, err = withParsedCursor(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Figure out the sort direction and comparison operator of each field that will be used in the
+	// augmented query, the previous page being queried in the reverse order
+	spec := sortSpec(p)
+	comparisonOps := make([]string, len(spec))
+	sort = make(bson.D, 0, len(spec))
+	for i, field := range spec {
+		sortAsc := field.Ascending == (p.Previous == "")
+		comparisonOps[i] = "$gt"
+		sortDir := 1
+		if !sortAsc {
+			comparisonOps[i] = "$lt"
+			sortDir = -1
+		}
+		sort = append(sort, bson.E{Key: field.Name, Value: sortDir})
+	}
+	if p.NaturalOrder && len(sort) == 1 {
+		// The insertion order matches the order of the _id the cursors hold
+		sort = bson.D{{Key: "$natural", Value: sort[0].Value}}
+	}
 
 	// Setup the pagination query
 	if p.Next != "" || p.Previous != "" {
-		var cursorValues []interface{}
-		if p.Next != "" {
-			cursorValues = nextCursorValues
-		} else if p.Previous != "" {
-			cursorValues = previousCursorValues
-		}
-		var cursorQuery bson.M
-		cursorQuery, err = mcpbson.GenerateCursorQuery(shouldSecondarySortOnID, p.PaginatedField, comparisonOp, cursorValues)
+		if p.MaxPageDepth > 0 && pageDepth(p) > p.MaxPageDepth {
+			return nil, nil, ErrMaxDepthExceeded
+		}
+		if includesBoundary(p, p.parsed.metadata) {
+			// Include the boundary document itself by including equality on the last field
+			comparisonOps[len(comparisonOps)-1] += "e"
+		}
+		cursorQuery, err = generateCursorQuery(p, fields, comparisonOps, p.parsed.values)
 		if err != nil {
-			return []bson.M{}, nil, err
+			return nil, nil, err
 		}
-		queries = append(queries, cursorQuery)
 	}
 
-	// Setup the sort query
-	if shouldSecondarySortOnID {
-		sort = bson.D{{Key: p.PaginatedField, Value: sortDir}, {Key: "_id", Value: sortDir}}
-	} else {
-		sort = bson.D{{Key: "_id", Value: sortDir}}
+	return cursorQuery, sort, nil
+}
+
+// baseQueries returns the queries selecting the documents to paginate over, regardless of the
+// page.
+func baseQueries(p FindParams) []bson.M {
+	queries := []bson.M{p.Query}
+	if len(p.ExcludeIDs) > 0 {
+		queries = append(queries, bson.M{"_id": bson.M{"$nin": p.ExcludeIDs}})
+	}
+	if !p.SnapshotTime.IsZero() {
+		if p.SnapshotField == "" || p.SnapshotField == "_id" {
+			// ObjectIDs only hold the creation time in seconds, include the whole snapshot second
+			upperBound := primitive.NewObjectIDFromTimestamp(p.SnapshotTime.Truncate(time.Second).Add(time.Second))
+			queries = append(queries, bson.M{"_id": bson.M{"$lt": upperBound}})
+		} else {
+			queries = append(queries, bson.M{p.SnapshotField: bson.M{"$lte": p.SnapshotTime}})
+		}
+		if p.ExpiryField != "" {
+			queries = append(queries, bson.M{p.ExpiryField: bson.M{"$gt": p.SnapshotTime}})
+		}
+	}
+	return queries
+}
+
+// totalCountQueries returns the queries counting all the documents to paginate over, like
+// baseQueries, the CountQuery replacing the Query when set.
+func totalCountQueries(p FindParams) []bson.M {
+	if p.CountQuery != nil {
+		p.Query = p.CountQuery
+	}
+	return baseQueries(p)
+}
+
+// ensureDefaults returns the FindParams with the defaults of the unset optional fields filled in.
+func ensureDefaults(p FindParams) FindParams {
+	if len(p.TieBreakerFields) == 0 {
+		p.TieBreakerFields = []string{"_id"}
+	}
+	if p.PaginatedField == "" && len(p.PaginatedFields) == 0 {
+		p.PaginatedField = p.TieBreakerFields[0]
+		p.Collation = nil
+	}
+	if p.MaxLimit > 0 && p.Limit > p.MaxLimit {
+		p.Limit = p.MaxLimit
+	}
+	p.Collection = withRegistry(p)
+	return p
+}
+
+// cursorParams returns the FindParams with their defaults filled in, as their cursors are generated
+// and parsed: the cursors of the TextScore PaginatedField hold the TextScoreField.
+func cursorParams(p FindParams) FindParams {
+	if p.PaginatedField == TextScore {
+		return textScoreParams(p)
 	}
+	return ensureDefaults(p)
+}
+
+// ValidateCursors decodes and validates each of the cursors without executing any query, returning
+// the error Find would return for each cursor, or nil if the cursor is valid for the FindParams.
+func (p FindParams) ValidateCursors(cursors []string) []error {
+	p = cursorParams(p)
+	errs := make([]error, len(cursors))
+	for i, cursor := range cursors {
+		if cursor == "" {
+			errs[i] = &CursorError{errors.New("cursor parse failed: empty cursor")}
+			continue
+		}
+		if _, err := parseCursor(p, cursor); err != nil {
+			errs[i] = &CursorError{fmt.Errorf("cursor parse failed: %w", err)}
+		}
+	}
+	return errs
+}
+
+// sortFields returns the fields the results are sorted on, in order: the paginated fields followed
+// by the tie-breaker fields. A cursor holds a value for each of these fields.
+func sortFields(p FindParams) []string {
+	spec := sortSpec(p)
+	fields := make([]string, 0, len(spec))
+	for _, field := range spec {
+		fields = append(fields, field.Name)
+	}
+	return fields
+}
 
-	return queries, sort, nil
+// sortSpec returns the fields the results are sorted on, in order, with their sort direction when
+// querying the next page.
+func sortSpec(p FindParams) []SortField {
+	spec := p.PaginatedFields
+	if len(spec) == 0 {
+		spec = []SortField{{Name: p.PaginatedField, Ascending: p.SortAscending}}
+	}
+	spec = append([]SortField{}, spec...)
+	for _, field := range p.TieBreakerFields {
+		if !hasSortField(spec, field) {
+			ascending := p.SortAscending
+			if p.IDSortAscending != nil {
+				ascending = *p.IDSortAscending
+			}
+			spec = append(spec, SortField{Name: field, Ascending: ascending})
+		}
+	}
+	return spec
+}
+
+func hasSortField(spec []SortField, name string) bool {
+	for _, field := range spec {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateCursorQuery returns the query selecting the documents past the cursor values, comparing
+// each field with the Comparison of the FindParams, which by default accounts for null and missing
+// values, sorted before any other value.
+func generateCursorQuery(p FindParams, fields []string, comparisonOps []string, cursorValues []interface{}) (bson.M, error) {
+	cursorValues = derefCursorValues(cursorValues)
+	if arrayPaginated(p) && len(fields) > 0 && fields[0] == p.PaginatedField {
+		return generateArrayCursorQuery(p, fields, comparisonOps, cursorValues)
+	}
+	return mcpbson.GenerateCustomCursorQuery(fields, comparisonOps, cursorValues, p.Comparison)
+}
+
+// derefCursorValues returns the cursor values with their pointers replaced by the values they
+// point to, and the nil pointers by nil, e.g. the values of a *time.Time field decoded by a
+// LegacyCursorDecoder, so the queries compare a nil boundary as null. The values are returned as is
+// when none is a pointer.
+func derefCursorValues(values []interface{}) []interface{} {
+	var derefed []interface{}
+	for i, value := range values {
+		val := reflect.ValueOf(value)
+		if val.Kind() != reflect.Ptr {
+			continue
+		}
+		if derefed == nil {
+			derefed = append([]interface{}{}, values...)
+		}
+		for val.Kind() == reflect.Ptr && !val.IsNil() {
+			val = val.Elem()
+		}
+		if val.Kind() == reflect.Ptr {
+			derefed[i] = nil
+		} else {
+			derefed[i] = val.Interface()
+		}
+	}
+	if derefed == nil {
+		return values
+	}
+	return derefed
 }
 
 // Find executes a find mongo query by using the provided FindParams, fills the passed in result
 // slice pointer and returns a Cursor.
+//
+// Every query of Find is executed with ctx, so passing a mongo.SessionContext, e.g. the one of a
+// mongo.Session.WithTransaction callback, makes the count and the page queries part of the session
+// and read from the snapshot of its transaction. The cursors don't depend on the session: cursors
+// generated inside a transaction are valid for subsequent calls made outside of it.
 func Find(ctx context.Context, p FindParams, results interface{}) (Cursor, error) {
-	var err error
+	if p.PaginatedField == TextScore {
+		return findTextScore(ctx, p, results)
+	}
+	if p.DiscriminatorField != "" {
+		return findDiscriminated(ctx, p, results)
+	}
+	if p.Projection != nil {
+		return findProjected(ctx, p, results)
+	}
+	return find(ctx, p, results, nil)
+}
+
+// find is Find returning only the fields of the projection, or all of them if the projection is
+// nil.
+func find(ctx context.Context, p FindParams, results interface{}, projection bson.M) (Cursor, error) {
 	if results == nil {
-		return Cursor{}, errors.New("results can't be nil")
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+	if err := validatePaginatedField(p.PaginatedField, results); err != nil {
+		return Cursor{}, err
+	}
+
+	p, queries, opts, count, err := pageQueries(ctx, p, projection)
+	if err != nil {
+		return Cursor{}, err
+	}
+	pageSize, err := executePageQuery(ctx, p, queries, opts, results)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if p.CausalCursors {
+		p = withOperationTime(ctx, p)
+	}
+	cursor, err := pageCursor(p, results, pageSize)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return completeCursor(ctx, p, cursor, results, count)
+}
+
+// pageQueries returns the FindParams with its defaults filled in, and the queries and options of
+// the find query of its page along with the count of the documents matching the query, which is
+// only computed if CountTotal is true or for the skip fallback.
+func pageQueries(ctx context.Context, p FindParams, projection bson.M) (FindParams, []bson.M, *options.FindOptions, int, error) {
+	if p.RequireIndex {
+		if err := requireIndex(ctx, p); err != nil {
+			return p, nil, nil, 0, err
+		}
+	}
+	if p.CausalCursors {
+		if err := advanceOperationTime(ctx, p); err != nil {
+			return p, nil, nil, 0, err
+		}
 	}
 
 	// Compute total count of documents matching filter - only computed if CountTotal is True
 	var count int
-	if p.CountTotal {
-		count, err = executeCountQuery(ctx, p.Collection, []bson.M{p.Query})
+	var err error
+	if p.CountTotal || p.SkipFallbackThreshold > 0 {
+		count, p.estimatedCount, err = totalCount(ctx, p)
+		if err != nil {
+			return p, nil, nil, 0, err
+		}
+	}
+
+	queries, sort, parsed, err := buildQueries(ctx, p)
+	if err != nil {
+		return p, nil, nil, 0, err
+	}
+
+	p = ensureDefaults(p)
+	p.parsed = parsed
+	opts := findOptions(p, sort, projection)
+	if int64(count) < p.SkipFallbackThreshold && (p.Next != "" || p.Previous != "") {
+		queries, err = skipPageQueries(ctx, p, opts)
+		if err != nil {
+			return p, nil, nil, 0, err
+		}
+	}
+	if !p.CountTotal {
+		count = 0
+	}
+	return p, queries, opts, count, nil
+}
+
+// totalCount returns the count of the documents matching the query, the one held by the
+// CountToken when ReuseCount is true and the token is valid for the query, along with whether the
+// count was estimated.
+func totalCount(ctx context.Context, p FindParams) (int, bool, error) {
+	if p.ReuseCount {
+		if count, reused := reusedCount(p); reused {
+			return count, false, nil
+		}
+	}
+	count, estimated, _, err := countDocuments(ctx, p)
+	return count, estimated, err
+}
+
+// executePageQuery executes the find query of the page, getting an additional element to see if
+// there's another page, and returns the number of documents of the page.
+func executePageQuery(ctx context.Context, p FindParams, queries []bson.M, opts *options.FindOptions, results interface{}) (int, error) {
+	if arrayPaginated(p) && p.Previous != "" {
+		return executeArrayPreviousPageQuery(ctx, p, queries, opts, results)
+	}
+	if missingLast(p) {
+		return executeMissingLastPageQuery(ctx, p, opts, results)
+	}
+	if p.MaxBytes > 0 {
+		return executeBudgetedCursorQuery(ctx, p.Collection, queries, opts, p.MaxBytes, results)
+	}
+	if err := executeCursorQuery(ctx, p.Collection, queries, opts, results); err != nil {
+		return 0, err
+	}
+	return int(p.Limit), nil
+}
+
+// completeCursor completes the Cursor of the page holding the results with the optional
+// information requested by the FindParams.
+func completeCursor(ctx context.Context, p FindParams, cursor Cursor, results interface{}, count int) (Cursor, error) {
+	var err error
+	if p.Offset > 0 {
+		// The pages of an Offset are queried by skipping documents only
+		cursor.Previous, cursor.Next, cursor.HasPrevious = "", "", true
+		cursor.StartCursor, cursor.EndCursor = "", ""
+	}
+	if p.BidirectionalProbe && (p.Next != "" || p.Previous != "") {
+		exists, err := probeOppositeEnd(ctx, p, results)
 		if err != nil {
 			return Cursor{}, err
 		}
+		if p.Next != "" && !exists {
+			cursor.HasPrevious, cursor.Previous = false, ""
+		} else if p.Previous != "" && !exists {
+			cursor.HasNext, cursor.Next = false, ""
+		}
+	}
+	if p.CountTotal && p.ComputeRanks {
+		cursor.Ranks, err = pageRanks(ctx, p, results)
+		if err != nil {
+			return Cursor{}, err
+		}
+	}
+	if p.VersionField != "" {
+		cursor.PageSignature, err = pageSignature(results, p.VersionField)
+		if err != nil {
+			return Cursor{}, err
+		}
+	}
+	cursor.Count = count
+	cursor.Estimated = p.estimatedCount
+	cursor.EffectiveLimit = p.Limit
+	if p.CountTotal && p.ReuseCount {
+		cursor.CountToken, err = countToken(p, count)
+		if err != nil {
+			return Cursor{}, fmt.Errorf("could not create a count token: %s", err)
+		}
+	}
+	if p.CountTotal && p.ReturnCountFilter {
+		cursor.CountFilter = countFilter(p, totalCountQueries(p))
+	}
+	return cursor, nil
+}
+
+// pageSignature returns the hex encoded SHA-256 of the _id and the version field value of each of
+// the results, in order.
+func pageSignature(results interface{}, versionField string) (string, error) {
+	resultsVal := reflect.ValueOf(results).Elem()
+	h := sha256.New()
+	for i := 0; i < resultsVal.Len(); i++ {
+		data, err := boundaryData(resultsVal.Index(i).Interface(), []string{"_id", versionField})
+		if err != nil {
+			return "", fmt.Errorf("could not compute the page signature: %s", err)
+		}
+		// Each BSON document starts with its length, so documents can't run into each other
+		encoded, err := bson.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("could not compute the page signature: %s", err)
+		}
+		h.Write(encoded)
 	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	queries, sort, err := BuildQueries(ctx, p)
+// pageRanks returns the rank of each document of the page by counting the documents before its
+// first document.
+func pageRanks(ctx context.Context, p FindParams, results interface{}) ([]int, error) {
+	resultsVal := reflect.ValueOf(results).Elem()
+	if resultsVal.Len() == 0 {
+		return nil, nil
+	}
+	data, err := resultData(p, resultsVal.Index(0).Interface(), sortFields(p))
 	if err != nil {
-		return Cursor{}, err
+		return nil, err
 	}
+	values := make([]interface{}, 0, len(data))
+	for _, e := range data {
+		values = append(values, e.Value)
+	}
+	before, err := countBeside(ctx, p, values, true, false)
+	if err != nil {
+		return nil, err
+	}
+	ranks := make([]int, resultsVal.Len())
+	for i := range ranks {
+		ranks[i] = before + i + 1
+	}
+	return ranks, nil
+}
 
-	shouldSecondarySortOnID := p.PaginatedField != "_id"
+// probeOppositeEnd returns true if there are documents before the page of a Next cursor, or after
+// the page of a Previous cursor. The page is empty when its cursor is past the end, the documents
+// are then probed from the cursor, its boundary document included.
+func probeOppositeEnd(ctx context.Context, p FindParams, results interface{}) (bool, error) {
+	fields := sortFields(p)
+	resultsVal := reflect.ValueOf(results).Elem()
+	var values []interface{}
+	inclusive := resultsVal.Len() == 0
+	if inclusive {
+		token := p.Next
+		if token == "" {
+			token = p.Previous
+		}
+		var err error
+		if values, _, err = parseCursorData(p, token); err != nil {
+			return false, err
+		}
+	} else {
+		boundary := resultsVal.Index(0).Interface()
+		if p.Previous != "" {
+			boundary = resultsVal.Index(resultsVal.Len() - 1).Interface()
+		}
+		data, err := resultData(p, boundary, fields)
+		if err != nil {
+			return false, err
+		}
+		for _, e := range data {
+			values = append(values, e.Value)
+		}
+	}
 
-	// Execute the augmented query, get an additional element to see if there's another page
-	err = executeCursorQuery(ctx, p.Collection, queries, sort, p.Limit, p.Collation, results)
+	// Look for documents before the page of a Next cursor, after the page of a Previous cursor
+	probeQuery, err := besideQuery(p, values, p.Next != "", inclusive)
 	if err != nil {
-		return Cursor{}, err
+		return false, err
+	}
+	queries := append(baseQueries(p), probeQuery)
+	opts := options.Find().SetLimit(1).SetProjection(bson.M{"_id": 1})
+	if p.Collation != nil {
+		opts.SetCollation(p.Collation)
+	}
+	if p.Hint != nil {
+		opts.SetHint(p.Hint)
 	}
+	if maxTime := queryMaxTime(p); maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	var found []bson.Raw
+	if err := executeCursorQuery(ctx, p.Collection, queries, opts, &found); err != nil {
+		return false, err
+	}
+	return len(found) > 0, nil
+}
 
+// pageCursor removes the additional document fetched to see if there's another page from the
+// results of a page query, restores the sort order of a previous page and returns the Cursor of
+// the page.
+func pageCursor(p FindParams, results interface{}, pageSize int) (Cursor, error) {
 	// Get the results slice's pointer and value
 	resultsPtr := reflect.ValueOf(results)
 	resultsVal := resultsPtr.Elem()
 
-	hasMore := resultsVal.Len() > int(p.Limit)
+	hasMore := resultsVal.Len() > pageSize
 
 	// Remove the extra element that we added to see if there was another page
 	if hasMore {
-		resultsVal = resultsVal.Slice(0, resultsVal.Len()-1)
+		resultsVal = resultsVal.Slice(0, pageSize)
 	}
 
-	hasPrevious := p.Next != "" || (p.Previous != "" && hasMore)
-	hasNext := p.Previous != "" || hasMore
-
-	var previousCursor string
-	var nextCursor string
-
+	var first, last interface{}
 	if resultsVal.Len() > 0 {
 		// If we sorted reverse to get the previous page, correct the sort order
 		if p.Previous != "" {
@@ -211,11 +1114,43 @@ func Find(ctx context.Context, p FindParams, results interface{}) (Cursor, error
 				resultsVal.Index(right).Set(reflect.ValueOf(leftValue))
 			}
 		}
+		first, last = resultsVal.Index(0).Interface(), resultsVal.Index(resultsVal.Len()-1).Interface()
+	}
+
+	cursor, err := boundaryCursor(p, resultsVal.Len(), first, last, hasMore)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if p.nodeCursors != nil {
+		if *p.nodeCursors, err = documentCursors(p, resultsVal); err != nil {
+			return Cursor{}, err
+		}
+	}
+
+	// Save the modified result slice in the result pointer
+	resultsPtr.Elem().Set(resultsVal)
+
+	return cursor, nil
+}
+
+// boundaryCursor returns the Cursor of a page of the specified number of returned results, whose
+// first and last results are specified when the page isn't empty, hasMore telling whether there are
+// documents past the page in the order it was queried.
+func boundaryCursor(p FindParams, returned int, first, last interface{}, hasMore bool) (Cursor, error) {
+	var err error
+	nonEmpty := returned > 0
+	fields := sortFields(p)
+
+	hasPrevious := p.Next != "" || (p.Previous != "" && hasMore)
+	hasNext := p.Previous != "" || hasMore
+
+	var previousCursor string
+	var nextCursor string
 
+	if nonEmpty {
 		// Generate the previous cursor
 		if hasPrevious {
-			firstResult := resultsVal.Index(0).Interface()
-			previousCursor, err = generateCursor(firstResult, p.PaginatedField, shouldSecondarySortOnID)
+			previousCursor, err = generatePageCursor(p, first, fields, cursorMetadata(p))
 			if err != nil {
 				return Cursor{}, fmt.Errorf("could not create a previous cursor: %s", err)
 			}
@@ -223,8 +1158,7 @@ func Find(ctx context.Context, p FindParams, results interface{}) (Cursor, error
 
 		// Generate the next cursor
 		if hasNext {
-			lastResult := resultsVal.Index(resultsVal.Len() - 1).Interface()
-			nextCursor, err = generateCursor(lastResult, p.PaginatedField, shouldSecondarySortOnID)
+			nextCursor, err = generateNextCursor(p, last, fields)
 			if err != nil {
 				return Cursor{}, fmt.Errorf("could not create a next cursor: %s", err)
 			}
@@ -237,88 +1171,730 @@ func Find(ctx context.Context, p FindParams, results interface{}) (Cursor, error
 		HasPrevious: hasPrevious,
 		Next:        nextCursor,
 		HasNext:     hasNext,
-		Count:       count,
+		Returned:    int64(returned),
 	}
+	if nonEmpty {
+		// Unlike the Previous and Next cursors, these are set whatever the pages around this one,
+		// and are left empty rather than failing the query when their document misses a field
+		// the cursors hold
+		if startCursor, err := generatePageCursor(p, first, fields, cursorMetadata(p)); err == nil {
+			cursor.StartCursor = startCursor
+		}
+		if endCursor, err := generatePageCursor(p, last, fields, cursorMetadata(p)); err == nil {
+			cursor.EndCursor = endCursor
+		}
+	}
+	if p.Collation != nil {
+		collation := *p.Collation
+		cursor.Collation = &collation
+	}
+	return cursor, nil
+}
 
-	// Save the modified result slice in the result pointer
-	resultsPtr.Elem().Set(resultsVal)
+// validateResults returns ErrInvalidResultsType if the results aren't a non nil pointer to a
+// slice, which Find fills in using reflection.
+func validateResults(results interface{}) error {
+	resultsPtr := reflect.ValueOf(results)
+	if resultsPtr.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T", ErrInvalidResultsType, results)
+	}
+	if resultsPtr.IsNil() {
+		return fmt.Errorf("%w, got a nil %T", ErrInvalidResultsType, results)
+	}
+	if resultsPtr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w, got %T", ErrInvalidResultsType, results)
+	}
+	return nil
+}
 
-	return cursor, nil
+// The types of the values decoding any document
+var (
+	unmarshalerType = reflect.TypeOf((*bson.Unmarshaler)(nil)).Elem()
+	documentType    = reflect.TypeOf(bson.D{})
+	rawType         = reflect.TypeOf(bson.Raw{})
+)
+
+// validatePaginatedField returns ErrUnknownPaginatedField if the results are a slice of structs, or
+// of pointers to structs, with no field the paginated field resolves to, so the cursors would hold
+// no value. The fields of other results, e.g. bson.M, are dynamic and aren't checked.
+func validatePaginatedField(field string, results interface{}) error {
+	if field == "" {
+		return nil
+	}
+	elemType := reflect.TypeOf(results).Elem().Elem()
+	if !hasBSONField(elemType, strings.Split(field, ".")) {
+		return fmt.Errorf("%w, got %s for %s", ErrUnknownPaginatedField, field, elemType)
+	}
+	return nil
+}
+
+// hasBSONField reports whether the type may hold the field of the dotted path, by matching the keys
+// of the path with the fields of the struct types along it as the bson codec names them. The maps,
+// interfaces, bson.D, bson.Raw and types decoding themselves may hold any field.
+func hasBSONField(t reflect.Type, path []string) bool {
+	for t != documentType && t != rawType && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if len(path) == 0 || t == documentType || t == rawType || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+	default:
+		// Values other than documents have no fields
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("bson"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		// The fields of an inlined struct are fields of the document, and an embedded struct may
+		// be inlined by the codec too
+		inline := contains(tag[1:], "inline")
+		if (inline || (field.Anonymous && name == "")) && hasBSONField(field.Type, path) {
+			return true
+		}
+		if inline {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == path[0] && hasBSONField(field.Type, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+var parseCursor = func(p FindParams, cursor string) ([]interface{}, error) {
+	cursorValues, _, err := parseCursorData(p, cursor)
+	return cursorValues, err
+}
+
+// withParsedCursor returns the FindParams, whose defaults must be filled in, holding their parsed
+// Next or Previous cursor, both cursors being validated. The FindParams are returned as is when
+// they already hold it, so the cursor is only parsed once.
+func withParsedCursor(p FindParams) (FindParams, error) {
+	if p.parsed != nil {
+		return p, nil
+	}
+	nextCursorValues, nextCursorMetadata, err := parseCursorData(p, p.Next)
+	if err != nil {
+		return p, &CursorError{fmt.Errorf("next cursor parse failed: %w", err)}
+	}
+	previousCursorValues, previousCursorMetadata, err := parseCursorData(p, p.Previous)
+	if err != nil {
+		return p, &CursorError{fmt.Errorf("previous cursor parse failed: %w", err)}
+	}
+	parsed := &parsedCursor{values: nextCursorValues, metadata: nextCursorMetadata}
+	if p.Next == "" {
+		parsed = &parsedCursor{values: previousCursorValues, metadata: previousCursorMetadata}
+	}
+	if p.BindCursorsToFilter {
+		if parsed.filterHash, err = filterHash(p); err != nil {
+			return p, err
+		}
+	}
+	p.parsed = parsed
+	return p, nil
 }
 
-var parseCursor = func(cursor string, shouldSecondarySortOnID bool) ([]interface{}, error) {
-	cursorValues := make([]interface{}, 0, 2)
+// parseCursorData parses a cursor of the FindParams, whose defaults must be filled in, returning
+// the field values it holds and its metadata elements separately.
+func parseCursorData(p FindParams, cursor string) ([]interface{}, bson.D, error) {
+	fieldCount := len(sortFields(p))
+	cursorValues := make([]interface{}, 0, fieldCount)
+	var metadata bson.D
 	if cursor != "" {
-		parsedCursor, err := decodeCursor(cursor)
+		parsedCursor, err := decodeSignedCursor(p, cursor)
+		if err != nil && p.LegacyCursorDecoder != nil {
+			if legacyCursor, legacyErr := p.LegacyCursorDecoder(cursor); legacyErr == nil {
+				parsedCursor, err = legacyCursor, nil
+			}
+		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		var id interface{}
-		if shouldSecondarySortOnID {
-			if len(parsedCursor) != 2 {
-				return nil, errors.New("expecting a cursor with two elements")
+		values := make(bson.D, 0, len(parsedCursor))
+		for _, element := range parsedCursor {
+			if strings.HasPrefix(element.Key, "$") {
+				metadata = append(metadata, element)
+			} else {
+				values = append(values, element)
 			}
-			paginatedFieldValue := parsedCursor[0].Value
-			id = parsedCursor[1].Value
-			cursorValues = append(cursorValues, paginatedFieldValue)
-		} else {
-			if len(parsedCursor) != 1 {
-				return nil, errors.New("expecting a cursor with a single element")
+			if element.Key == cursorFilterHashKey && p.BindCursorsToFilter {
+				hash, err := filterHash(p)
+				if err != nil {
+					return nil, nil, err
+				}
+				if element.Value != hash {
+					return nil, nil, ErrCursorFilterMismatch
+				}
+			}
+			if element.Key != cursorExpiryKey {
+				continue
+			}
+			expiry, ok := element.Value.(primitive.DateTime)
+			if !ok {
+				return nil, nil, errors.New("invalid cursor expiry")
 			}
-			id = parsedCursor[0].Value
+			if !timeNow().Before(expiry.Time()) {
+				return nil, nil, ErrCursorExpired
+			}
+		}
+		if len(values) != fieldCount {
+			switch fieldCount {
+			case 1:
+				return nil, nil, &cursorShapeError{"expecting a cursor with a single element"}
+			case 2:
+				return nil, nil, &cursorShapeError{"expecting a cursor with two elements"}
+			default:
+				return nil, nil, &cursorShapeError{fmt.Sprintf("expecting a cursor with %d elements", fieldCount)}
+			}
+		}
+		for _, element := range values {
+			cursorValues = append(cursorValues, element.Value)
+		}
+	}
+	return cursorValues, metadata, nil
+}
+
+// decodeSignedCursor decodes a cursor after verifying its signature when the FindParams have a
+// CursorSecret. The delta cursors, which are never signed, are decoded relative to the deltaBase.
+func decodeSignedCursor(p FindParams, cursor string) (bson.D, error) {
+	if len(p.CursorSecret) == 0 {
+		if data, err := decodeCursorBytes(cursor); err == nil && isDeltaCursor(data) {
+			return decodeDeltaCursor(data, sortFields(p), p.deltaBase)
+		}
+	}
+	if len(p.CursorSecret) > 0 {
+		var err error
+		if cursor, err = verifyCursor(cursor, p.CursorSecret); err != nil {
+			return nil, err
 		}
-		cursorValues = append(cursorValues, id)
 	}
-	return cursorValues, nil
+	return decodeCursor(cursor)
 }
 
-// decodeCursor decodes cursor data that was previously encoded with createCursor
+// cursorAlphabet maps the characters of the standard base64 alphabet which aren't url safe to the
+// ones of the url safe alphabet
+var cursorAlphabet = strings.NewReplacer("+", "-", "/", "_")
+
+// decodeCursorBytes decodes the base64 of a cursor. The cursors are encoded url safe and unpadded,
+// but the ones encoded with the standard alphabet, with or without padding, are accepted too, e.g.
+// the cursors of earlier versions: the alphabets only differ by the characters of the last two
+// values, so these are mapped to the url safe ones, and a padded cursor is decoded as such.
+func decodeCursorBytes(cursor string) ([]byte, error) {
+	cursor = cursorAlphabet.Replace(cursor)
+	if strings.HasSuffix(cursor, "=") {
+		return base64.URLEncoding.DecodeString(cursor)
+	}
+	return base64.RawURLEncoding.DecodeString(cursor)
+}
+
+// decodeCursor decodes cursor data that was previously encoded with encodeCursor,
+// encodeCanonicalCursor or encodeJSONCursor
 func decodeCursor(cursor string) (bson.D, error) {
 	var cursorData bson.D
-	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	data, err := decodeCursorBytes(cursor)
 	if err != nil {
 		return cursorData, err
 	}
+	if isJSONCursor(data) {
+		return decodeJSONCursor(data)
+	}
+	if isCanonicalCursor(data) {
+		return decodeCanonicalCursor(data)
+	}
 
 	err = bson.Unmarshal(data, &cursorData)
 	return cursorData, err
 }
 
-var executeCountQuery = func(ctx context.Context, c Collection, queries []bson.M) (int, error) {
-	count, err := c.CountDocuments(ctx, bson.M{"$and": queries})
+// DecodeCursorToMap decodes a Next or Previous cursor into a map of the field names to the values
+// it holds, leaving out the cursor metadata, e.g. so tests can assert on the contents of a cursor
+// regardless of its encoding. Cursors generated with a CursorSecret aren't supported.
+func DecodeCursorToMap(token string) (map[string]interface{}, error) {
+	cursorData, err := decodeCursor(token)
 	if err != nil {
-		return 0, err
+		return nil, &CursorError{fmt.Errorf("cursor parse failed: %w", err)}
+	}
+	values := make(map[string]interface{}, len(cursorData))
+	for _, e := range cursorData {
+		if !strings.HasPrefix(e.Key, "$") {
+			values[e.Key] = e.Value
+		}
+	}
+	return values, nil
+}
+
+// ReverseCursor returns the cursor querying the page on the other side of the document of the
+// cursor, e.g. to query the page before the document a Next cursor was generated from. A cursor
+// holds the values of the sorted fields of a document rather than a direction, the direction being
+// given by passing it as the Next or as the Previous of the FindParams, so the cursor is returned
+// unchanged: a Next cursor passed as Previous returns the page ending before its document, which
+// overlaps the page the cursor was generated from.
+func ReverseCursor(cursor string) string {
+	return cursor
+}
+
+// Count counts the documents matching the CountQuery of the FindParams, or their Query when it's
+// unset, regardless of their cursors, and returns the count along with the filter passed to
+// CountDocuments when ReturnCountFilter is true.
+func Count(ctx context.Context, p FindParams) (int, bson.M, error) {
+	count, _, filter, err := countDocuments(ctx, p)
+	return count, filter, err
+}
+
+// countDocuments is Count, also returning whether the count was estimated.
+func countDocuments(ctx context.Context, p FindParams) (int, bool, bson.M, error) {
+	if p.Collection == nil {
+		return 0, false, nil, ErrNilCollection
+	}
+	queries := totalCountQueries(p)
+	filter := countFilter(p, queries)
+	var count int
+	var estimated bool
+	var err error
+	if p.EstimatedCount && len(queries) == 1 && len(queries[0]) == 0 {
+		count, estimated, err = estimatedCount(ctx, p)
+		if err != nil {
+			return 0, false, nil, err
+		}
+	}
+	if !estimated {
+		opts := options.Count()
+		if p.Hint != nil {
+			opts.SetHint(p.Hint)
+		}
+		if collation := ensureDefaults(p).Collation; collation != nil {
+			opts.SetCollation(collation)
+		}
+		if maxTime := queryMaxTime(p); maxTime > 0 {
+			opts.SetMaxTime(maxTime)
+		}
+		count, err = executeCountQuery(ctx, withReadOptions(p), filter, opts)
+		if err != nil {
+			return 0, false, nil, err
+		}
+	}
+	if !p.ReturnCountFilter {
+		filter = nil
+	}
+	return count, estimated, filter, nil
+}
+
+// countFilter returns the filter of the count query of the documents matching the queries. The
+// count options can't hold a comment, so the comment is set with the $comment query operator.
+func countFilter(p FindParams, queries []bson.M) bson.M {
+	filter := bson.M{"$and": queries}
+	if p.QueryComment != "" {
+		filter["$comment"] = p.QueryComment
+	}
+	return filter
+}
+
+var executeCountQuery = func(ctx context.Context, c Collection, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := c.CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, maxTimeError(err)
 	}
 	return int(count), nil
 }
 
-func executeCursorQuery(ctx context.Context, c Collection, query []bson.M, sort bson.D, limit int64, collation *options.Collation, results interface{}) error {
-	options := options.Find()
-	options.SetSort(sort)
-	options.SetLimit(limit + 1)
+// lookaheadLimit returns the limit of the find query of a page of the specified limit, getting an
+// additional document to see if there's another page. No document can follow a page of the largest
+// limit, which is then kept rather than overflowing.
+func lookaheadLimit(limit int64) int64 {
+	if limit == math.MaxInt64 {
+		return limit
+	}
+	return limit + 1
+}
+
+// findOptions returns the options of the find query of a page sorted with the specified sort and
+// returning the fields of the projection, getting an additional document to see if there's another
+// page.
+func findOptions(p FindParams, sort bson.D, projection bson.M) *options.FindOptions {
+	opts := options.Find()
+	if p.FindOptions != nil {
+		opts = options.MergeFindOptions(p.FindOptions)
+	}
+	opts.SetSort(sort)
+	opts.SetLimit(lookaheadLimit(p.Limit))
 
-	if collation != nil {
-		options.SetCollation(collation)
+	if p.Collation != nil {
+		opts.SetCollation(p.Collation)
+	}
+	if p.SortKeyField != "" {
+		withSortKey := bson.M{p.SortKeyField: bson.M{"$meta": "sortKey"}}
+		for field, value := range projection {
+			withSortKey[field] = value
+		}
+		projection = withSortKey
+	}
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	if p.QueryComment != "" {
+		opts.SetComment(p.QueryComment)
+	}
+	if p.Hint != nil {
+		opts.SetHint(p.Hint)
+	}
+	if maxTime := queryMaxTime(p); maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	if p.Offset > 0 {
+		opts.SetSkip(p.Offset)
 	}
-	cursor, err := c.Find(ctx, bson.M{"$and": query}, options)
+	if p.AllowDiskUse {
+		opts.SetAllowDiskUse(true)
+	}
+	if p.BatchSize > 0 {
+		opts.SetBatchSize(p.BatchSize)
+	} else if p.LookaheadBatch {
+		batchSize := int32(math.MaxInt32)
+		if limit := lookaheadLimit(p.Limit); limit < math.MaxInt32 {
+			batchSize = int32(limit)
+		}
+		opts.SetBatchSize(batchSize)
+	}
+	return opts
+}
+
+func executeCursorQuery(ctx context.Context, c Collection, query []bson.M, opts *options.FindOptions, results interface{}) error {
+	cursor, err := c.Find(ctx, bson.M{"$and": query}, opts)
 	if err != nil {
-		return err
+		return maxTimeError(err)
 	}
 	err = cursor.All(ctx, results)
 
 	if err != nil {
-		return err
+		return maxTimeError(err)
 	}
 	return nil
 }
 
-func generateCursor(result interface{}, paginatedField string, shouldSecondarySortOnID bool) (string, error) {
+// skipPageQueries returns the queries of the page of the Next or Previous cursor for a skip based
+// pagination, setting the options to skip the documents up to the boundary document of the cursor
+// in the sort order of the page query.
+func skipPageQueries(ctx context.Context, p FindParams, opts *options.FindOptions) ([]bson.M, error) {
+	token := p.Next
+	if token == "" {
+		token = p.Previous
+	}
+	cursorValues, cursorMetadata, err := parseCursorData(p, token)
+	if err != nil {
+		return nil, &CursorError{fmt.Errorf("cursor parse failed: %w", err)}
+	}
+
+	// Count the documents which aren't after the cursor in the sort order of the page query
+	includeBoundary := !includesBoundary(p, cursorMetadata)
+	skip, err := countBeside(ctx, p, cursorValues, p.Next != "", includeBoundary)
+	if err != nil {
+		return nil, err
+	}
+	opts.SetSkip(int64(skip))
+	return baseQueries(p), nil
+}
+
+// besideQuery returns the query selecting the documents before the values in the sort order of
+// the FindParams, or after them if before is false, including the document holding the values if
+// inclusive is true.
+func besideQuery(p FindParams, values []interface{}, before bool, inclusive bool) (bson.M, error) {
+	spec := sortSpec(p)
+	fields := make([]string, len(spec))
+	comparisonOps := make([]string, len(spec))
+	for i, field := range spec {
+		fields[i] = field.Name
+		comparisonOps[i] = "$gt"
+		if field.Ascending == before {
+			comparisonOps[i] = "$lt"
+		}
+	}
+	return boundaryQuery(p, fields, comparisonOps, values, inclusive)
+}
+
+// countBeside counts the documents to paginate over which are before the values in the sort
+// order of the FindParams, or after them if before is false, including the document holding the
+// values if inclusive is true.
+func countBeside(ctx context.Context, p FindParams, values []interface{}, before bool, inclusive bool) (int, error) {
+	query, err := besideQuery(p, values, before, inclusive)
+	if err != nil {
+		return 0, err
+	}
+	return executeCountQuery(ctx, p.Collection, countFilter(p, append(baseQueries(p), query)), countOptions(p))
+}
+
+// countOptions returns the options of a count query relative to the sort order of the FindParams,
+// whose defaults must be filled in.
+func countOptions(p FindParams) *options.CountOptions {
+	opts := options.Count()
+	if p.Collation != nil {
+		opts.SetCollation(p.Collation)
+	}
+	if p.Hint != nil {
+		opts.SetHint(p.Hint)
+	}
+	if maxTime := queryMaxTime(p); maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	return opts
+}
+
+// executeBudgetedCursorQuery executes the query, decoding the documents one at a time into the
+// results until their cumulative BSON size would exceed maxBytes or the limit of the options is
+// reached. It returns the number of documents of the page, the results holding an additional
+// document when there's another page.
+func executeBudgetedCursorQuery(ctx context.Context, c Collection, query []bson.M, opts *options.FindOptions, maxBytes int64, results interface{}) (int, error) {
+	cursor, err := c.Find(ctx, bson.M{"$and": query}, opts)
+	if err != nil {
+		return 0, maxTimeError(err)
+	}
+	defer cursor.Close(ctx)
+
+	resultsVal := reflect.ValueOf(results).Elem()
+	elemType := resultsVal.Type().Elem()
+	resultsVal.Set(resultsVal.Slice(0, 0))
+	pageSize := int(*opts.Limit) - 1
+	var size int64
+	for resultsVal.Len() <= pageSize && cursor.Next(ctx) {
+		var raw bson.Raw
+		if err := cursor.Decode(&raw); err != nil {
+			return 0, err
+		}
+		elem := reflect.New(elemType)
+		if err := cursor.Decode(elem.Interface()); err != nil {
+			return 0, err
+		}
+		resultsVal.Set(reflect.Append(resultsVal, elem.Elem()))
+		size += int64(len(raw))
+		if size > maxBytes && resultsVal.Len() > 1 {
+			// The document doesn't fit in the page, keep it to tell there's another page
+			pageSize = resultsVal.Len() - 1
+			break
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, maxTimeError(err)
+	}
+	return pageSize, nil
+}
+
+// cursorMetadata returns the metadata elements to add to the cursors generated for the FindParams
+func cursorMetadata(p FindParams) []bson.E {
+	var metadata []bson.E
+	if p.CursorTTL > 0 {
+		metadata = append(metadata, bson.E{Key: cursorExpiryKey, Value: primitive.NewDateTimeFromTime(timeNow().Add(p.CursorTTL))})
+	}
+	if p.IncludeFirstBoundary {
+		metadata = append(metadata, bson.E{Key: cursorGeneratedKey, Value: true})
+	}
+	if p.BindCursorsToFilter {
+		// The hash was computed when parsing the cursor of the page
+		metadata = append(metadata, bson.E{Key: cursorFilterHashKey, Value: p.parsed.filterHash})
+	}
+	if p.CausalCursors && p.operationTime != nil {
+		metadata = append(metadata, bson.E{Key: cursorOperationTimeKey, Value: *p.operationTime})
+	}
+	if p.MaxPageDepth > 0 {
+		metadata = append(metadata, bson.E{Key: cursorDepthKey, Value: int32(pageDepth(p))})
+	}
+	return metadata
+}
+
+// filterHash returns the hex encoded first 8 bytes of the SHA-256 of the queries selecting the
+// documents to paginate over, whatever the order of the fields of their documents.
+func filterHash(p FindParams) (string, error) {
+	data, err := bson.Marshal(bson.D{{Key: "queries", Value: sortedKeys(baseQueries(p))}})
+	if err != nil {
+		return "", fmt.Errorf("could not hash the filter: %s", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// sortedKeys returns the value with its documents converted to documents whose fields are sorted
+// by name, so the value is always marshaled to the same bytes.
+func sortedKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		return sortedDocument(v)
+	case map[string]interface{}:
+		return sortedDocument(v)
+	case bson.D:
+		d := make(bson.D, 0, len(v))
+		for _, e := range v {
+			d = append(d, bson.E{Key: e.Key, Value: sortedKeys(e.Value)})
+		}
+		return d
+	case []bson.M:
+		a := make(bson.A, 0, len(v))
+		for _, e := range v {
+			a = append(a, sortedDocument(e))
+		}
+		return a
+	case bson.A:
+		a := make(bson.A, 0, len(v))
+		for _, e := range v {
+			a = append(a, sortedKeys(e))
+		}
+		return a
+	case []interface{}:
+		return sortedKeys(bson.A(v))
+	}
+	return value
+}
+
+func sortedDocument(m map[string]interface{}) bson.D {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	d := make(bson.D, 0, len(m))
+	for _, key := range keys {
+		d = append(d, bson.E{Key: key, Value: sortedKeys(m[key])})
+	}
+	return d
+}
+
+// pageDepth returns the depth of the page queried with the Next or Previous cursor of the
+// FindParams, which hold the parsed cursor: one more than the depth of the page of a Next cursor,
+// one less than the depth of the page of a Previous cursor, and 1 for the first page. Cursors
+// without a depth are considered to be from the first page.
+func pageDepth(p FindParams) int {
+	if p.Next == "" && p.Previous == "" {
+		return 1
+	}
+	depth := 1
+	for _, e := range p.parsed.metadata {
+		if d, ok := e.Value.(int32); ok && e.Key == cursorDepthKey {
+			depth = int(d)
+		}
+	}
+	if p.Next != "" {
+		return depth + 1
+	}
+	if depth > 1 {
+		return depth - 1
+	}
+	return 1
+}
+
+// isGeneratedCursor returns true if the cursor metadata marks the cursor as generated by Find
+func isGeneratedCursor(metadata bson.D) bool {
+	for _, e := range metadata {
+		if e.Key == cursorGeneratedKey {
+			return true
+		}
+	}
+	return false
+}
+
+// includesBoundary returns true if the page of the cursor of the metadata includes the boundary
+// document of the cursor.
+func includesBoundary(p FindParams, metadata bson.D) bool {
+	return p.includeBoundary || (p.IncludeFirstBoundary && !isGeneratedCursor(metadata))
+}
+
+// generateNextCursor generates the Next cursor of a page of the FindParams from its last result,
+// relative to the Next cursor the page was queried with when DeltaCursors is true and the cursor
+// can be delta encoded.
+func generateNextCursor(p FindParams, last interface{}, fields []string) (string, error) {
+	last, err := registryResult(p, last)
+	if err != nil {
+		return "", err
+	}
+	last, err = arraySortKeyResult(p, last, fields)
+	if err != nil {
+		return "", err
+	}
+	metadata := cursorMetadata(p)
+	if p.DeltaCursors && p.deltaBase != nil && p.Next != "" && len(metadata) == 0 && len(p.CursorSecret) == 0 {
+		// The Next cursor was parsed when querying the page
+		cursor, ok, err := generateDeltaCursor(last, fields, p.parsed.values)
+		if err != nil || ok {
+			return cursor, err
+		}
+	}
+	return generatePageCursor(p, last, fields, metadata)
+}
+
+// generatePageCursor generates the cursor of a result of a page of the FindParams, with the
+// specified metadata.
+func generatePageCursor(p FindParams, result interface{}, fields []string, metadata []bson.E) (string, error) {
+	result, err := registryResult(p, result)
+	if err != nil {
+		return "", err
+	}
+	result, err = arraySortKeyResult(p, result, fields)
+	if err != nil {
+		return "", err
+	}
+	var cursor string
+	switch {
+	case p.CanonicalCursor:
+		cursor, err = generateCanonicalCursor(result, fields, metadata...)
+	case p.CursorFormat == CursorFormatJSON:
+		cursor, err = generateJSONCursor(result, fields, metadata...)
+	default:
+		cursor, err = generateCursor(result, fields, metadata...)
+	}
+	if err != nil || len(p.CursorSecret) == 0 {
+		return cursor, err
+	}
+	return signCursor(cursor, p.CursorSecret)
+}
+
+func generateCursor(result interface{}, fields []string, metadata ...bson.E) (string, error) {
+	// Take a shortcut for the common case of paginating by _id only
+	if len(fields) == 1 && fields[0] == "_id" && len(metadata) == 0 {
+		if id, ok := objectIDOf(result); ok {
+			return encodeObjectIDCursor(id), nil
+		}
+	}
+	return generateFieldsCursor(result, fields, metadata...)
+}
+
+// generateFieldsCursor generates a cursor holding the values of the specified fields of the result
+// by marshaling the result to BSON.
+func generateFieldsCursor(result interface{}, fields []string, metadata ...bson.E) (string, error) {
+	cursorData, err := boundaryData(result, fields)
+	if err != nil {
+		return "", err
+	}
+	cursorData = append(cursorData, metadata...)
+	// Encode the cursor data into a url safe string
+	cursor, err := encodeCursor(cursorData)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor using %v: %s", cursorData, err)
+	}
+	return cursor, nil
+}
+
+// boundaryData returns the values of the specified fields of the result, in order, by marshaling
+// the result to BSON.
+func boundaryData(result interface{}, fields []string) (bson.D, error) {
 	if result == nil {
-		return "", fmt.Errorf("the specified result must be a non nil value")
+		return nil, fmt.Errorf("the specified result must be a non nil value")
 	}
 	// Handle pointer values and reduce number of times reflection is done on the same type.
 	val := reflect.ValueOf(result)
 	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, fmt.Errorf("the specified result must be a non nil value")
+		}
 		val = reflect.Indirect(val)
 	}
 
@@ -328,33 +1904,118 @@ func generateCursor(result interface{}, paginatedField string, shouldSecondarySo
 	switch v := result.(type) {
 	case []byte:
 		recordAsBytes = v
+	case bson.Raw:
+		recordAsBytes = v
 	default:
 		recordAsBytes, err = bson.Marshal(result)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	var recordAsMap map[string]interface{}
 	err = bson.Unmarshal(recordAsBytes, &recordAsMap)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	paginatedFieldValue := recordAsMap[paginatedField]
-	// Set the cursor data
-	cursorData := make(bson.D, 0, 2)
-	cursorData = append(cursorData, bson.E{Key: paginatedField, Value: paginatedFieldValue})
-	if shouldSecondarySortOnID {
-		// Get the value of the ID field
-		id := recordAsMap["_id"]
-		cursorData = append(cursorData, bson.E{Key: "_id", Value: id})
+	// Set the boundary data, keeping the BSON type of each field's value
+	data := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		data = append(data, bson.E{Key: field, Value: fieldValue(recordAsMap, field)})
 	}
-	// Encode the cursor data into a url safe string
-	cursor, err := encodeCursor(cursorData)
-	if err != nil {
-		return "", fmt.Errorf("failed to encode cursor using %v: %s", cursorData, err)
+	return data, nil
+}
+
+// fieldValue returns the value of the field of the document, walking the embedded documents of a
+// dotted field path such as metadata.updatedAt, or nil if the document doesn't hold the field.
+func fieldValue(doc map[string]interface{}, field string) interface{} {
+	if value, ok := doc[field]; ok || !strings.Contains(field, ".") {
+		return value
 	}
-	return cursor, nil
+	var value interface{} = doc
+	for _, key := range strings.Split(field, ".") {
+		switch embedded := value.(type) {
+		case map[string]interface{}:
+			value = embedded[key]
+		case primitive.M:
+			value = embedded[key]
+		case primitive.D:
+			value = embedded.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// idFieldIndexes caches the index of the top level field tagged _id of struct types, or -1 if the
+// type has no such ObjectID field.
+var idFieldIndexes sync.Map
+
+// objectIDOf returns the ObjectID _id of the result without marshaling it, when the result is a
+// struct, a pointer to a struct, bson.Raw, bson.M or bson.D with a non zero ObjectID _id.
+func objectIDOf(result interface{}) (primitive.ObjectID, bool) {
+	var id interface{}
+	switch v := result.(type) {
+	case bson.Raw:
+		oid, ok := v.Lookup("_id").ObjectIDOK()
+		return oid, ok && !oid.IsZero()
+	case bson.M:
+		id = v["_id"]
+	case bson.D:
+		for _, e := range v {
+			if e.Key == "_id" {
+				id = e.Value
+				break
+			}
+		}
+	default:
+		val := reflect.ValueOf(result)
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return primitive.NilObjectID, false
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return primitive.NilObjectID, false
+		}
+		index, cached := idFieldIndexes.Load(val.Type())
+		if !cached {
+			index = structIDFieldIndex(val.Type())
+			idFieldIndexes.Store(val.Type(), index)
+		}
+		if index.(int) < 0 {
+			return primitive.NilObjectID, false
+		}
+		id = val.Field(index.(int)).Interface()
+	}
+	oid, ok := id.(primitive.ObjectID)
+	return oid, ok && !oid.IsZero()
+}
+
+// structIDFieldIndex returns the index of the exported ObjectID field tagged _id of the struct type,
+// or -1 if there isn't any.
+func structIDFieldIndex(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("bson"), ",")[0]
+		if name == "_id" && field.PkgPath == "" && field.Type == reflect.TypeOf(primitive.ObjectID{}) {
+			return i
+		}
+	}
+	return -1
+}
+
+// encodeObjectIDCursor encodes the cursor of an _id only pagination, producing the same bytes as
+// encodeCursor(bson.D{{Key: "_id", Value: id}}) without using the BSON marshaler.
+func encodeObjectIDCursor(id primitive.ObjectID) string {
+	// int32 document length, ObjectID element type, "_id" key, the ObjectID and the document end
+	data := make([]byte, 0, 22)
+	data = append(data, 22, 0, 0, 0, 0x07, '_', 'i', 'd', 0)
+	data = append(data, id[:]...)
+	data = append(data, 0)
+	return base64.RawURLEncoding.EncodeToString(data)
 }
 
 // encodeCursor encodes and returns cursor data that is url safe
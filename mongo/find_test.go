@@ -0,0 +1,2371 @@
+package mongo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	mcpbson "github.com/qlik-oss/mongocursorpagination/bson"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type item struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Name   string             `bson:"name"`
+	Group  string             `bson:"group,omitempty"`
+	Region string             `bson:"region,omitempty"`
+	Seq    int                `bson:"seq,omitempty"`
+}
+
+func newItems(names ...string) []interface{} {
+	items := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		items = append(items, item{ID: primitive.NewObjectID(), Name: name})
+	}
+	return items
+}
+
+// traverse walks the pages the fake collection returns forward using the Next cursors and then
+// back to the first page using the Previous cursors, returning the names of the items in the order
+// they were visited.
+func traverse(t *testing.T, p FindParams) (forward []string, backward []string) {
+	t.Helper()
+	var cursor Cursor
+	for {
+		var page []item
+		var err error
+		cursor, err = Find(context.Background(), p, &page)
+		require.NoError(t, err)
+		forward = append(forward, itemNames(page)...)
+		if !cursor.HasNext {
+			break
+		}
+		require.NotEmpty(t, cursor.Next)
+		p.Next, p.Previous = cursor.Next, ""
+	}
+	for cursor.HasPrevious {
+		require.NotEmpty(t, cursor.Previous)
+		p.Next, p.Previous = "", cursor.Previous
+		var page []item
+		var err error
+		cursor, err = Find(context.Background(), p, &page)
+		require.NoError(t, err)
+		for i := len(page) - 1; i >= 0; i-- {
+			backward = append(backward, page[i].Name)
+		}
+	}
+	return forward, backward
+}
+
+func TestDecodeCursorToMap(t *testing.T) {
+	doc := item{ID: primitive.NewObjectID(), Name: "test item 2"}
+	cursor, err := generateCursor(doc, []string{"name", "_id"}, bson.E{Key: cursorExpiryKey, Value: int64(1)})
+	require.NoError(t, err)
+	values, err := DecodeCursorToMap(cursor)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "test item 2", "_id": doc.ID}, values)
+
+	// The values don't depend on the encoding of the cursor
+	canonical, err := generateCanonicalCursor(doc, []string{"name", "_id"})
+	require.NoError(t, err)
+	require.NotEqual(t, cursor, canonical)
+	canonicalValues, err := DecodeCursorToMap(canonical)
+	require.NoError(t, err)
+	require.Equal(t, values, canonicalValues)
+
+	_, err = DecodeCursorToMap("XXXXXaGVsbG8=")
+	require.True(t, errors.Is(err, ErrBadCursor))
+}
+
+func TestDecodeCursorStandardEncoding(t *testing.T) {
+	id, err := primitive.ObjectIDFromHex("5addf533e81549de7696cb04")
+	require.NoError(t, err)
+	cursor := encodeObjectIDCursor(id)
+	require.Equal(t, "FgAAAAdfaWQAWt31M-gVSd52lssEAA", cursor)
+
+	// The cursors encoded with the standard alphabet, padded or not, decode the same
+	for _, encoded := range []string{cursor, "FgAAAAdfaWQAWt31M+gVSd52lssEAA==", "FgAAAAdfaWQAWt31M+gVSd52lssEAA"} {
+		cursorData, err := decodeCursor(encoded)
+		require.NoError(t, err, encoded)
+		require.Equal(t, bson.D{{Key: "_id", Value: id}}, cursorData, encoded)
+	}
+
+	// And they query the same page
+	items := newItems("a", "b", "c")
+	col := newFakeCollection(t, items...)
+	p := FindParams{Collection: col, Query: primitive.M{}, Limit: 1, SortAscending: true}
+	var results []item
+	first, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next = first.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	data, err := base64.RawURLEncoding.DecodeString(first.Next)
+	require.NoError(t, err)
+	p.Next = base64.StdEncoding.EncodeToString(data)
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, col.findFilters[1], col.findFilters[2])
+}
+
+func TestFindSentinelErrors(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b")...)
+	p := FindParams{Collection: col, Query: primitive.M{}, Limit: 1, PaginatedField: "name"}
+	_, err := Find(context.Background(), p, nil)
+	require.True(t, errors.Is(err, ErrNilResults))
+	var results []item
+	_, err = Find(context.Background(), FindParams{Limit: 1}, &results)
+	require.True(t, errors.Is(err, ErrNilCollection))
+	_, err = Find(context.Background(), FindParams{Collection: col}, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+
+	// All the cursor errors are bad cursors, their messages are unchanged
+	p.Next = "XXXXXaGVsbG8="
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+	require.EqualError(t, err, "next cursor parse failed: illegal base64 data at input byte 12")
+	p.Next = ""
+	p.Previous = mustGenerateCursor(t, item{Name: "a"}, []string{"_id"})
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+
+	// The cursors generated for another sort are told apart
+	require.True(t, errors.Is(err, ErrCursorShapeMismatch))
+	require.EqualError(t, err, "previous cursor parse failed: expecting a cursor with two elements")
+	p.PaginatedField, p.Previous = "", mustGenerateCursor(t, item{Name: "a"}, []string{"name", "_id"})
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrCursorShapeMismatch))
+	require.EqualError(t, err, "previous cursor parse failed: expecting a cursor with a single element")
+	p.PaginatedField = "name"
+
+	// Database errors aren't
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Find(ctx, FindParams{Collection: col, Query: primitive.M{}, Limit: 1}, &results)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrBadCursor))
+}
+
+// cannedCollection is a Collection returning canned results and recording the queries, as a
+// handler paginating with Find would stub it in its unit tests.
+type cannedCollection struct {
+	count   int64
+	results []item
+
+	filters []interface{}
+	limits  []int64
+}
+
+func (c *cannedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.count, nil
+}
+
+func (c *cannedCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	c.filters = append(c.filters, filter)
+	limit := *options.MergeFindOptions(opts...).Limit
+	c.limits = append(c.limits, limit)
+	page := make([]bson.M, 0, limit)
+	for _, result := range c.results {
+		if int64(len(page)) == limit {
+			break
+		}
+		doc, err := toM(result)
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, doc)
+	}
+	return newFakeCursor(page)
+}
+
+func TestFindCollection(t *testing.T) {
+	col := &cannedCollection{count: 3}
+	for _, doc := range newItems("a", "b", "c") {
+		col.results = append(col.results, doc.(item))
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"group": "a"},
+		PaginatedField: "name",
+		SortAscending:  true,
+		Limit:          2,
+		CountTotal:     true,
+	}
+
+	// The queries are executed without a mongo database, fetching one more document than the limit
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, col.results[:2], results)
+	require.Equal(t, 3, cursor.Count)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, []int64{3}, col.limits)
+	require.Equal(t, sentFilter(t, p), mustToM(t, col.filters[0]))
+
+	// The cursor query of the next page is executed too
+	p.Next = cursor.Next
+	col.results = col.results[2:]
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, col.results, results)
+	require.False(t, cursor.HasNext)
+	require.True(t, cursor.HasPrevious)
+	require.Equal(t, sentFilter(t, p), mustToM(t, col.filters[1]))
+}
+
+func TestFindOffset(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+	cases := []struct {
+		offset         int64
+		page           []interface{}
+		before         int64
+		expectedNames  []string
+		expectedCursor Cursor
+	}{
+		{2, items[2:5], 2, []string{"c", "d"}, Cursor{HasPrevious: true, HasNext: true, Returned: 2, EffectiveLimit: 2, Count: 5, Ranks: []int{3, 4}}},
+		{4, items[4:], 4, []string{"e"}, Cursor{HasPrevious: true, HasNext: false, Returned: 1, EffectiveLimit: 2, Count: 5, Ranks: []int{5}}},
+		{6, nil, 0, nil, Cursor{HasPrevious: true, HasNext: false, EffectiveLimit: 2, Count: 5}},
+	}
+	for _, tc := range cases {
+		p.Offset = tc.offset
+		col.queuePage(t, tc.page...)
+		col.queueCount(5)
+		if len(tc.page) > 0 {
+			col.queueCount(tc.before)
+		}
+		var results []item
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedNames, itemNames(results), "offset %d", tc.offset)
+		require.Equal(t, tc.expectedCursor, cursor, "offset %d", tc.offset)
+		// The documents before the offset are skipped
+		opts := col.findOptions[len(col.findOptions)-1]
+		require.Equal(t, tc.offset, *opts.Skip, "offset %d", tc.offset)
+		require.Equal(t, sentFilter(t, p), col.findFilters[len(col.findFilters)-1], "offset %d", tc.offset)
+	}
+
+	// The first page is the same as without an offset
+	p.Offset = 0
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.NotEmpty(t, cursor.Next)
+	require.Nil(t, col.findOptions[len(col.findOptions)-1].Skip)
+
+	// Offsets and cursors are mutually exclusive
+	p.Offset = 2
+	p.Next = cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.Equal(t, ErrOffsetWithCursor, err)
+	p.Offset, p.Next = -1, ""
+	_, err = Find(context.Background(), p, &results)
+	require.Equal(t, ErrNegativeOffset, err)
+}
+
+func TestFindCompositeTieBreaker(t *testing.T) {
+	last := item{ID: primitive.NewObjectID(), Name: "b-emea-2", Group: "b", Region: "emea", Seq: 2}
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:       col,
+		Query:            primitive.M{},
+		Limit:            2,
+		SortAscending:    true,
+		PaginatedField:   "group",
+		TieBreakerFields: []string{"region", "seq"},
+	}
+
+	// The tie-breaker fields are sorted after the PaginatedField in place of the _id, and the
+	// cursors keep the type of each of them
+	p.Next = mustGenerateCursor(t, last, []string{"group", "region", "seq"})
+	values, err := parseCursor(ensureDefaults(p), p.Next)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"b", "emea", int32(2)}, values)
+	var results []item
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "group", Value: 1}, {Key: "region", Value: 1}, {Key: "seq", Value: 1}}, col.findOptions[0].Sort)
+	require.Equal(t, sentFilter(t, p), col.findFilters[0])
+
+	p.SortAscending = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "group", Value: -1}, {Key: "region", Value: -1}, {Key: "seq", Value: -1}}, col.findOptions[1].Sort)
+	require.NotEqual(t, col.findFilters[0], col.findFilters[1])
+	require.Equal(t, sentFilter(t, p), col.findFilters[1])
+}
+
+// itemNames returns the names of the items, in order.
+func itemNames(items []item) []string {
+	var names []string
+	for _, i := range items {
+		names = append(names, i.Name)
+	}
+	return names
+}
+
+// sentFilter returns the filter of the page query of the FindParams, as recorded by a
+// fakeCollection.
+func sentFilter(t *testing.T, p FindParams) bson.M {
+	t.Helper()
+	queries, _, err := BuildQueries(context.Background(), p)
+	require.NoError(t, err)
+	filter, err := toM(bson.M{"$and": queries})
+	require.NoError(t, err)
+	return filter
+}
+
+// pageCursorQuery returns the cursor query of the page of the FindParams.
+func pageCursorQuery(t *testing.T, p FindParams) bson.M {
+	t.Helper()
+	query, _, err := cursorQueryAndSort(cursorParams(p))
+	require.NoError(t, err)
+	return query
+}
+
+// boundaryFilter returns the filter of the page query selecting the documents past the result,
+// comparing the sorted fields with the comparison operators.
+func boundaryFilter(t *testing.T, p FindParams, result interface{}, comparisonOps ...string) bson.M {
+	t.Helper()
+	p = ensureDefaults(p)
+	fields := sortFields(p)
+	values, err := parseCursor(p, mustGenerateCursor(t, result, fields))
+	require.NoError(t, err)
+	query, err := generateCursorQuery(p, fields, comparisonOps, values)
+	require.NoError(t, err)
+	return mustToM(t, bson.M{"$and": bson.A{p.Query, query}})
+}
+
+func mustGenerateCursor(t *testing.T, result interface{}, fields []string, metadata ...bson.E) string {
+	t.Helper()
+	cursor, err := generateCursor(result, fields, metadata...)
+	require.NoError(t, err)
+	return cursor
+}
+
+func TestBuildQueriesPaginatedFieldIsTieBreaker(t *testing.T) {
+	items := newItems("a")
+	id := items[0].(item).ID
+	p := FindParams{
+		Collection:       newFakeCollection(t, items...),
+		Query:            primitive.M{},
+		Limit:            2,
+		SortAscending:    true,
+		PaginatedField:   "_id",
+		TieBreakerFields: []string{"_id"},
+		Next:             mustGenerateCursor(t, items[0], []string{"_id"}),
+	}
+	queries, sort, err := BuildQueries(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []bson.M{{}, {"_id": map[string]interface{}{"$gt": id}}}, queries)
+	require.Equal(t, bson.D{{Key: "_id", Value: 1}}, sort)
+}
+
+func TestFindReturnsEffectiveCollation(t *testing.T) {
+	col := newFakeCollection(t, newItems("test item 1", "test item 2", "test item 3")...)
+	collation := options.Collation{Locale: "en", Strength: 2}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		Collation:      &collation,
+	}
+
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, &collation, cursor.Collation)
+	require.Equal(t, col.findOptions[0].Collation, cursor.Collation)
+	require.True(t, &collation != cursor.Collation, "the returned collation should be a copy")
+
+	// The collation is ignored when paginating by _id
+	p.PaginatedField = ""
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Nil(t, cursor.Collation)
+	require.Nil(t, col.findOptions[1].Collation)
+}
+
+func TestFindCaseInsensitiveCollation(t *testing.T) {
+	col := newFakeCollection(t, newItems("Apple", "apple", "APPLE")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		Collation:      &options.Collation{Locale: "en", Strength: 2},
+	}
+
+	// The page and count queries of both cursors are run with the collation
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next, p.Previous = "", cursor.Previous
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Len(t, col.findOptions, 3)
+	for _, opts := range col.findOptions {
+		require.Equal(t, p.Collation, opts.Collation)
+	}
+	require.Len(t, col.countOptions, 3)
+	for _, opts := range col.countOptions {
+		require.Equal(t, p.Collation, opts.Collation)
+	}
+
+	// Count matches the query with the collation too
+	p.Query = primitive.M{"name": "apple"}
+	col.queueCount(3)
+	count, _, err := Count(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, p.Collation, col.countOptions[3].Collation)
+}
+
+func TestFindExcludeIDs(t *testing.T) {
+	items := newItems("test item 1", "test item 2", "test item 3", "test item 4")
+	col := newFakeCollection(t, items...)
+	excluded := []interface{}{primitive.NewObjectID(), primitive.NewObjectID()}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"name": primitive.Regex{Pattern: "test item.*"}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ExcludeIDs:     excluded,
+	}
+
+	// The excluded documents are left out of the pages and of the count
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 4, cursor.Count)
+	expected := mustToM(t, bson.M{"$and": bson.A{p.Query, bson.M{"_id": bson.M{"$nin": excluded}}}})
+	require.Equal(t, expected, col.countFilters[0])
+	require.Equal(t, expected, col.findFilters[0])
+
+	// As from the pages of the cursors
+	p.Next = cursor.Next
+	p.CountTotal = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, expected["$and"], col.findFilters[1]["$and"].(bson.A)[:2])
+}
+
+func TestFindSnapshotWithExpiringDocuments(t *testing.T) {
+	type expiringItem struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Name      string             `bson:"name"`
+		ExpiresAt time.Time          `bson:"expiresAt"`
+	}
+	snapshot := time.Date(2020, 6, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+	item1 := expiringItem{ID: primitive.NewObjectIDFromTimestamp(snapshot.Add(-time.Hour)), Name: "test item 1", ExpiresAt: snapshot.Add(time.Hour)}
+	col := newFakeCollection(t, item1)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"name": primitive.M{"$ne": ""}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		SnapshotTime:   snapshot,
+		ExpiryField:    "expiresAt",
+	}
+
+	// The documents created after the snapshot second and the ones expired at the snapshot time
+	// are left out of the pages and of the count
+	var results []expiringItem
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	// The ids generated for the bound only share its timestamp, the next second
+	upperBound := func(filter bson.M) bson.M {
+		bound := filter["$and"].(bson.A)[1].(bson.M)["_id"].(bson.M)["$lt"].(primitive.ObjectID)
+		require.Equal(t, time.Date(2020, 6, 1, 12, 0, 1, 0, time.UTC), bound.Timestamp().UTC())
+		filter["$and"].(bson.A)[1] = primitive.M{"_id": primitive.M{"$lt": "upperBound"}}
+		return filter
+	}
+	snapshotQueries := bson.A{
+		p.Query,
+		primitive.M{"_id": primitive.M{"$lt": "upperBound"}},
+		primitive.M{"expiresAt": primitive.M{"$gt": primitive.NewDateTimeFromTime(snapshot)}},
+	}
+	require.Equal(t, primitive.M{"$and": snapshotQueries}, upperBound(col.countFilters[0]))
+	require.Equal(t, primitive.M{"$and": snapshotQueries}, upperBound(col.findFilters[0]))
+
+	// As from the pages of the cursors
+	p.Next = mustGenerateCursor(t, item1, []string{"name", "_id"})
+	p.CountTotal = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, snapshotQueries, upperBound(col.findFilters[1])["$and"].(bson.A)[:3])
+
+	// The creation time is compared against the SnapshotField when set
+	p.SnapshotField = "createdAt"
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, primitive.M{"createdAt": primitive.M{"$lte": primitive.NewDateTimeFromTime(snapshot)}}, col.findFilters[2]["$and"].(bson.A)[1])
+}
+
+func TestValidateCursors(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeNowOri := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() {
+		timeNow = timeNowOri
+	}()
+
+	doc := item{ID: primitive.NewObjectID(), Name: "test item"}
+	nameFields := []string{"name", "_id"}
+	p := FindParams{PaginatedField: "name", CursorTTL: time.Hour}
+	valid := mustGenerateCursor(t, doc, nameFields, cursorMetadata(p)...)
+	withoutTTL := mustGenerateCursor(t, doc, nameFields)
+	wrongShape := mustGenerateCursor(t, doc, []string{"_id"})
+	now = now.Add(-2 * time.Hour)
+	expired := mustGenerateCursor(t, doc, nameFields, cursorMetadata(p)...)
+	now = now.Add(2 * time.Hour)
+
+	errs := p.ValidateCursors([]string{valid, "XXXXXaGVsbG8=", expired, withoutTTL, wrongShape, ""})
+	require.Len(t, errs, 6)
+	require.NoError(t, errs[0])
+	require.EqualError(t, errs[1], "cursor parse failed: illegal base64 data at input byte 12")
+	require.EqualError(t, errs[2], "cursor parse failed: cursor expired")
+	require.True(t, errors.Is(errs[2], ErrCursorExpired))
+	require.NoError(t, errs[3])
+	require.EqualError(t, errs[4], "cursor parse failed: expecting a cursor with two elements")
+	require.True(t, errors.Is(errs[4], ErrCursorShapeMismatch))
+	require.EqualError(t, errs[5], "cursor parse failed: empty cursor")
+	for _, err := range errs[1:3] {
+		require.IsType(t, &CursorError{}, err)
+	}
+
+	// Find rejects the expired cursor the same way
+	p.Collection = newFakeCollection(t, doc)
+	p.Query = primitive.M{}
+	p.Limit = 1
+	p.Next = expired
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.EqualError(t, err, "next cursor parse failed: cursor expired")
+	require.True(t, errors.Is(err, ErrCursorExpired))
+
+	// The cursor is valid until it expires
+	p.Next = valid
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+}
+
+func TestGenerateCursorByIDFastPath(t *testing.T) {
+	doc := item{ID: primitive.NewObjectID(), Name: "test item"}
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	expected, err := generateFieldsCursor(doc, []string{"_id"})
+	require.NoError(t, err)
+
+	for _, result := range []interface{}{doc, &doc, bson.Raw(raw), bson.M{"_id": doc.ID}, bson.D{{Key: "_id", Value: doc.ID}}} {
+		id, ok := objectIDOf(result)
+		require.True(t, ok, "%T should use the fast path", result)
+		require.Equal(t, doc.ID, id)
+		require.Equal(t, expected, mustGenerateCursor(t, result, []string{"_id"}))
+	}
+
+	// Results without a non zero ObjectID _id use the general path
+	type stringIDItem struct {
+		ID string `bson:"_id"`
+	}
+	for _, result := range []interface{}{item{}, stringIDItem{ID: "123"}, bson.M{"_id": "123"}, (*item)(nil)} {
+		_, ok := objectIDOf(result)
+		require.False(t, ok, "%T should not use the fast path", result)
+	}
+	require.Equal(t, "EgAAAAJfaWQABAAAADEyMwAA", mustGenerateCursor(t, stringIDItem{ID: "123"}, []string{"_id"}))
+}
+
+func BenchmarkGenerateCursorByID(b *testing.B) {
+	doc := item{ID: primitive.NewObjectID(), Name: "test item", Region: "emea", Seq: 3}
+	b.Run("fast path", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = generateCursor(doc, []string{"_id"})
+		}
+	})
+	b.Run("general path", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = generateFieldsCursor(doc, []string{"_id"})
+		}
+	})
+}
+
+func TestFindIncludeFirstBoundary(t *testing.T) {
+	items := newItems("test item 4", "test item 5", "test item 6")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:           col,
+		Query:                primitive.M{},
+		Limit:                2,
+		SortAscending:        true,
+		PaginatedField:       "name",
+		IncludeFirstBoundary: true,
+	}
+	// The processing stopped after item 3, the checkpoint is the next item to process
+	checkpoint := mustGenerateCursor(t, items[0], []string{"name", "_id"})
+
+	// The page of the checkpoint starts with its document
+	p.Next = checkpoint
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, items[0], "$gt", "$gte"), col.findFilters[0])
+
+	// unlike the pages of the cursors generated by Find
+	p.Next = cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, items[1], "$gt", "$gt"), col.findFilters[1])
+
+	// Without the option the checkpoint document is excluded
+	p.IncludeFirstBoundary = false
+	p.Next = checkpoint
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, items[0], "$gt", "$gt"), col.findFilters[2])
+}
+
+func TestFindSortKeys(t *testing.T) {
+	col := newFakeCollection(t, newItems("Apple", "apple", "banana")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          5,
+		SortAscending:  true,
+		PaginatedField: "name",
+		Collation:      &options.Collation{Locale: "en", Strength: 2},
+		SortKeyField:   "sortKey",
+	}
+
+	// The sort keys are projected into the results
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, primitive.M{"sortKey": primitive.M{"$meta": "sortKey"}}, col.findOptions[0].Projection)
+}
+
+func TestFindInvalidResultsType(t *testing.T) {
+	p := FindParams{Collection: newFakeCollection(t), Query: primitive.M{}, Limit: 2}
+	var cases = []struct {
+		name        string
+		results     interface{}
+		expectedErr string
+	}{
+		{"errors when results is not a pointer", []item{}, "results must be a non nil pointer to a slice, got []mongo.item"},
+		{"errors when results is a pointer to a non slice", &item{}, "results must be a non nil pointer to a slice, got *mongo.item"},
+		{"errors when results is a nil pointer", (*[]item)(nil), "results must be a non nil pointer to a slice, got a nil *[]mongo.item"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Find(context.Background(), p, tc.results)
+			require.EqualError(t, err, tc.expectedErr)
+			require.True(t, errors.Is(err, ErrInvalidResultsType))
+		})
+	}
+}
+
+func TestFindTimePaginatedFieldMillisecondPrecision(t *testing.T) {
+	type event struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Name      string             `bson:"name"`
+		CreatedAt time.Time          `bson:"createdAt"`
+	}
+	// b is created within a millisecond, which is the precision of a BSON datetime
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	b := event{ID: primitive.NewObjectID(), Name: "b", CreatedAt: start.Add(time.Millisecond + 100*time.Microsecond)}
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          1,
+		SortAscending:  true,
+		PaginatedField: "createdAt",
+	}
+
+	// A cursor generated from a document with a sub-millisecond time holds the time at BSON
+	// precision, so the keyset comparison falls through to the _id tiebreaker
+	cursor, err := generateCursor(b, sortFields(ensureDefaults(p)))
+	require.NoError(t, err)
+	values, err := parseCursor(ensureDefaults(p), cursor)
+	require.NoError(t, err)
+	truncated := primitive.NewDateTimeFromTime(start.Add(time.Millisecond))
+	require.Equal(t, []interface{}{truncated, b.ID}, values)
+	p.Next = cursor
+	var results []event
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, bson.M{"createdAt": truncated, "_id": b.ID}, "$gt", "$gt"), col.findFilters[0])
+}
+
+func TestFindDottedPaginatedField(t *testing.T) {
+	type metadata struct {
+		UpdatedAt time.Time `bson:"updatedAt"`
+	}
+	type document struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Name     string             `bson:"name"`
+		Metadata metadata           `bson:"metadata"`
+	}
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	col := newFakeCollection(t)
+	for i, name := range []string{"c", "a", "d"} {
+		col.insert(t, document{ID: primitive.NewObjectID(), Name: name, Metadata: metadata{UpdatedAt: start.Add(time.Duration(i) * time.Minute)}})
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "metadata.updatedAt",
+	}
+
+	// The value is extracted from the nested documents of the structs
+	var documents []document
+	cursor, err := Find(context.Background(), p, &documents)
+	require.NoError(t, err)
+	values, err := parseCursor(ensureDefaults(p), cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{primitive.NewDateTimeFromTime(start.Add(time.Minute)), documents[1].ID}, values)
+	require.Equal(t, bson.D{{Key: "metadata.updatedAt", Value: 1}, {Key: "_id", Value: 1}}, col.findOptions[0].Sort)
+
+	// and of the maps too
+	var results []bson.M
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	values, err = parseCursor(ensureDefaults(p), cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{primitive.NewDateTimeFromTime(start.Add(time.Minute)), results[1]["_id"]}, values)
+}
+
+func TestFindPaginatedFieldWithNulls(t *testing.T) {
+	newDoc := func(name string, rank ...interface{}) bson.M {
+		doc := bson.M{"_id": primitive.NewObjectID(), "name": name}
+		if len(rank) > 0 {
+			doc["rank"] = rank[0]
+		}
+		return doc
+	}
+	n1, n2, n3, n4, n5, n7 := newDoc("n1", int32(2)), newDoc("n2"), newDoc("n3", int32(1)), newDoc("n4", nil), newDoc("n5", int32(2)), newDoc("n7", int32(3))
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:         col,
+		Query:              primitive.M{},
+		Limit:              2,
+		SortAscending:      true,
+		PaginatedField:     "rank",
+		TreatMissingAsLast: true,
+	}
+	present, missing := bson.M{"rank": bson.M{"$ne": nil}}, bson.M{"rank": nil}
+	segmentFilter := func(segment bson.M, fields []string, comparisonOps []string, doc bson.M) bson.M {
+		queries := bson.A{p.Query, segment}
+		if doc != nil {
+			values := make([]interface{}, 0, len(fields))
+			for _, field := range fields {
+				values = append(values, doc[field])
+			}
+			query, err := generateCursorQuery(ensureDefaults(p), fields, comparisonOps, values)
+			require.NoError(t, err)
+			queries = append(queries, query)
+		}
+		return mustToM(t, bson.M{"$and": queries})
+	}
+	requireQuery := func(i int, filter bson.M, sort bson.D, limit int64) {
+		t.Helper()
+		require.Equal(t, filter, col.findFilters[i])
+		require.Equal(t, sort, col.findOptions[i].Sort)
+		require.Equal(t, limit, *col.findOptions[i].Limit)
+	}
+	var results []bson.M
+	names := func() []string {
+		var names []string
+		for _, doc := range results {
+			names = append(names, doc["name"].(string))
+		}
+		return names
+	}
+	ascending := bson.D{{Key: "rank", Value: 1}, {Key: "_id", Value: 1}}
+
+	// The documents holding the field are queried first
+	col.queuePage(t, n3, n1, n5)
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n3", "n1"}, names())
+	requireQuery(0, segmentFilter(present, nil, nil, nil), ascending, 3)
+
+	// and the ones missing it complete the pages which aren't full
+	p.Next = cursor.Next
+	col.queuePage(t, n5, n7)
+	col.queuePage(t, n2)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n5", "n7"}, names())
+	require.True(t, cursor.HasNext)
+	requireQuery(1, segmentFilter(present, []string{"rank", "_id"}, []string{"$gt", "$gt"}, n1), ascending, 3)
+	requireQuery(2, segmentFilter(missing, nil, nil, nil), bson.D{{Key: "_id", Value: 1}}, 1)
+
+	// The cursors of the documents missing the field only query the documents missing it
+	p.Next = mustGenerateCursor(t, n2, []string{"rank", "_id"})
+	col.queuePage(t, n4)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n4"}, names())
+	require.False(t, cursor.HasNext)
+	requireQuery(3, segmentFilter(missing, []string{"_id"}, []string{"$gt"}, n2), bson.D{{Key: "_id", Value: 1}}, 3)
+	require.Len(t, col.findFilters, 4)
+
+	// The previous pages are queried in the reverse order, the documents missing the field first
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, n2)
+	col.queuePage(t, n7, n5)
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n7", "n2"}, names())
+	descending := bson.D{{Key: "rank", Value: -1}, {Key: "_id", Value: -1}}
+	requireQuery(4, segmentFilter(missing, []string{"_id"}, []string{"$lt"}, n4), descending[1:], 3)
+	requireQuery(5, segmentFilter(present, nil, nil, nil), descending, 2)
+
+	// Sorted descending, the documents missing the field come last already
+	p.SortAscending, p.Previous = false, ""
+	col.queuePage(t, n7, n5, n1)
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n7", "n5"}, names())
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query}}), col.findFilters[6])
+	require.Equal(t, descending, col.findOptions[6].Sort)
+}
+
+func TestFindIncompatibleOptions(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b")...)
+	for _, p := range []FindParams{
+		{PaginatedField: "name", SortAscending: true, TreatMissingAsLast: true, ComputeRanks: true, CountTotal: true},
+		{PaginatedField: "name", SortAscending: true, TreatMissingAsLast: true, BidirectionalProbe: true},
+		{PaginatedField: "name", SortAscending: true, TreatMissingAsLast: true, SkipFallbackThreshold: 10},
+		{PaginatedField: "name", ArrayPaginatedField: true, PaginatedFields: []SortField{{Name: "name"}}},
+		{PaginatedField: "name", ArrayPaginatedField: true, MaxBytes: 100},
+	} {
+		p.Collection, p.Query, p.Limit = col, primitive.M{}, 1
+		var results []item
+		_, err := Find(context.Background(), p, &results)
+		require.True(t, errors.Is(err, ErrIncompatibleOptions), err)
+	}
+
+	// Sorted descending, the documents missing the field come last already
+	var results []item
+	_, err := Find(context.Background(), FindParams{
+		Collection:         col,
+		Query:              primitive.M{},
+		Limit:              1,
+		PaginatedField:     "name",
+		TreatMissingAsLast: true,
+		BidirectionalProbe: true,
+	}, &results)
+	require.NoError(t, err)
+}
+
+func TestFindMissingAsLastIncludeFirstBoundary(t *testing.T) {
+	items := newItems("b", "c")
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:           col,
+		Query:                primitive.M{},
+		Limit:                2,
+		SortAscending:        true,
+		PaginatedField:       "name",
+		TreatMissingAsLast:   true,
+		IncludeFirstBoundary: true,
+		Next:                 mustGenerateCursor(t, items[0], []string{"name", "_id"}),
+	}
+
+	// The page of the cursor starts with its document
+	col.queuePage(t, items...)
+	col.queuePage(t)
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, itemNames(results))
+	b := items[0].(item)
+	query, err := generateCursorQuery(ensureDefaults(p), []string{"name", "_id"}, []string{"$gt", "$gte"}, []interface{}{b.Name, b.ID})
+	require.NoError(t, err)
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query, bson.M{"name": bson.M{"$ne": nil}}, query}}), col.findFilters[0])
+}
+
+func TestFindPointerPaginatedField(t *testing.T) {
+	type task struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Name  string             `bson:"name"`
+		DueAt *time.Time         `bson:"dueAt"`
+	}
+	due := func(hour int) *time.Time {
+		dueAt := time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)
+		return &dueAt
+	}
+	col := newFakeCollection(t,
+		task{ID: primitive.NewObjectID(), Name: "b"},
+		task{ID: primitive.NewObjectID(), Name: "d"},
+	)
+
+	// The nil due dates are encoded as null in the cursors
+	var results []task
+	cursor, err := Find(context.Background(), FindParams{Collection: col, Query: primitive.M{}, Limit: 1, SortAscending: true, PaginatedField: "dueAt"}, &results)
+	require.NoError(t, err)
+	values, err := DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Contains(t, values, "dueAt")
+	require.Nil(t, values["dueAt"])
+
+	// The pointer cursor values are compared as the values they point to, a nil one as null
+	p := FindParams{PaginatedField: "dueAt"}
+	fields, ops := []string{"dueAt", "_id"}, []string{"$gt", "$gt"}
+	id := primitive.NewObjectID()
+	var nilDueAt *time.Time
+	query, err := generateCursorQuery(p, fields, ops, []interface{}{nilDueAt, id})
+	require.NoError(t, err)
+	expected, err := generateCursorQuery(p, fields, ops, []interface{}{nil, id})
+	require.NoError(t, err)
+	require.Equal(t, expected, query)
+	query, err = generateCursorQuery(p, fields, ops, []interface{}{due(1), &id})
+	require.NoError(t, err)
+	expected, err = generateCursorQuery(p, fields, ops, []interface{}{*due(1), id})
+	require.NoError(t, err)
+	require.Equal(t, expected, query)
+
+	// A nil result can't generate a cursor
+	var nilTask *task
+	_, err = generateCursor(nilTask, fields)
+	require.EqualError(t, err, "the specified result must be a non nil value")
+}
+
+func TestFindIDSortAscending(t *testing.T) {
+	doc := item{ID: primitive.NewObjectID(), Name: "b", Seq: 2}
+	ascending, descending := true, false
+	var cases = []struct {
+		name            string
+		sortAscending   bool
+		idSortAscending *bool
+		expectedSort    bson.D
+		expectedOps     []string
+	}{
+		{"ascending score and _id", true, &ascending, bson.D{{Key: "seq", Value: 1}, {Key: "_id", Value: 1}}, []string{"$gt", "$gt"}},
+		{"ascending score, descending _id", true, &descending, bson.D{{Key: "seq", Value: 1}, {Key: "_id", Value: -1}}, []string{"$gt", "$lt"}},
+		{"descending score, ascending _id", false, &ascending, bson.D{{Key: "seq", Value: -1}, {Key: "_id", Value: 1}}, []string{"$lt", "$gt"}},
+		{"descending score and _id", false, &descending, bson.D{{Key: "seq", Value: -1}, {Key: "_id", Value: -1}}, []string{"$lt", "$lt"}},
+		{"_id sorted according to SortAscending when unset", false, nil, bson.D{{Key: "seq", Value: -1}, {Key: "_id", Value: -1}}, []string{"$lt", "$lt"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			col := newFakeCollection(t)
+			p := FindParams{
+				Collection:      col,
+				Query:           primitive.M{},
+				Limit:           2,
+				SortAscending:   tc.sortAscending,
+				PaginatedField:  "seq",
+				IDSortAscending: tc.idSortAscending,
+				Next:            mustGenerateCursor(t, doc, []string{"seq", "_id"}),
+			}
+			var results []item
+			_, err := Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSort, col.findOptions[0].Sort)
+			require.Equal(t, boundaryFilter(t, p, doc, tc.expectedOps...), col.findFilters[0])
+
+			// The fields are sorted the other way around for the previous pages
+			p.Next, p.Previous = "", p.Next
+			_, err = Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			reversedSort := bson.D{}
+			for _, e := range tc.expectedSort {
+				reversedSort = append(reversedSort, bson.E{Key: e.Key, Value: -e.Value.(int)})
+			}
+			require.Equal(t, reversedSort, col.findOptions[1].Sort)
+		})
+	}
+}
+
+func TestFindComparison(t *testing.T) {
+	items := newItems("a", "b", "c")
+	col := newFakeCollection(t)
+	type comparison struct {
+		field, op string
+		value     interface{}
+	}
+	var comparisons []comparison
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		Comparison: func(field string, comparisonOp string, value interface{}) map[string]interface{} {
+			comparisons = append(comparisons, comparison{field, comparisonOp, value})
+			return mcpbson.NullableComparison(field, comparisonOp, value)
+		},
+	}
+
+	// The cursor queries compare the fields with the Comparison
+	col.queuePage(t, items...)
+	col.queuePage(t, items[2])
+	col.queuePage(t, items[1], items[0])
+	forward, backward := traverse(t, p)
+	require.Equal(t, []string{"a", "b", "c"}, forward)
+	require.Equal(t, []string{"b", "a"}, backward)
+	b, c := items[1].(item), items[2].(item)
+	require.Equal(t, []comparison{
+		{"name", "$gt", "b"}, {"name", "$eq", "b"}, {"_id", "$gt", b.ID},
+		{"name", "$lt", "c"}, {"name", "$eq", "c"}, {"_id", "$lt", c.ID},
+	}, comparisons)
+}
+
+func TestFindPaginatedFieldsWithNulls(t *testing.T) {
+	doc := bson.M{"_id": primitive.NewObjectID(), "name": "n5", "status": "a", "score": int32(2), "label": nil}
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection: col,
+		Query:      primitive.M{},
+		Limit:      2,
+		PaginatedFields: []SortField{
+			{Name: "status", Ascending: true},
+			{Name: "score", Ascending: false},
+			{Name: "label", Ascending: true},
+		},
+		// The _id tie-breaker is sorted ascending
+		SortAscending: true,
+		Next:          mustGenerateCursor(t, doc, []string{"status", "score", "label", "_id"}),
+	}
+
+	// Each field is compared in its own direction, the null values included
+	var results []bson.M
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "status", Value: 1}, {Key: "score", Value: -1}, {Key: "label", Value: 1}, {Key: "_id", Value: 1}}, col.findOptions[0].Sort)
+	require.Equal(t, boundaryFilter(t, p, doc, "$gt", "$lt", "$gt", "$gt"), col.findFilters[0])
+
+	// And the other way around for the previous pages
+	p.Next, p.Previous = "", p.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "status", Value: -1}, {Key: "score", Value: 1}, {Key: "label", Value: -1}, {Key: "_id", Value: -1}}, col.findOptions[1].Sort)
+	require.Equal(t, boundaryFilter(t, p, doc, "$lt", "$gt", "$lt", "$lt"), col.findFilters[1])
+}
+
+func TestFindPaginatedFieldsCompoundSort(t *testing.T) {
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	doc := func(name, status string, createdAt time.Time) bson.M {
+		return bson.M{"_id": primitive.NewObjectID(), "name": name, "status": status, "createdAt": createdAt}
+	}
+	a4, a1, a3 := doc("a4", "active", day.Add(3*time.Hour)), doc("a1", "active", day.Add(2*time.Hour)), doc("a3", "active", day.Add(2*time.Hour))
+	col := newFakeCollection(t, a4, a1, a3)
+	p := FindParams{
+		Collection: col,
+		Query:      primitive.M{},
+		Limit:      2,
+		PaginatedFields: []SortField{
+			{Name: "status", Ascending: true},
+			{Name: "createdAt", Ascending: false},
+		},
+		SortAscending: true,
+	}
+
+	// The fields are sorted in order, followed by the _id tie-breaker
+	var results []bson.M
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}}, col.findOptions[0].Sort)
+
+	// The cursor holds the value of each sort field in order, followed by the _id tie-breaker
+	cursorData, err := decodeCursor(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{
+		{Key: "status", Value: "active"},
+		{Key: "createdAt", Value: primitive.NewDateTimeFromTime(day.Add(2 * time.Hour))},
+		{Key: "_id", Value: a1["_id"]},
+	}, cursorData)
+
+	// A PaginatedField is sorted like a single PaginatedFields field
+	single := ensureDefaults(FindParams{PaginatedField: "status", SortAscending: false})
+	multiple := ensureDefaults(FindParams{PaginatedFields: []SortField{{Name: "status", Ascending: false}}, SortAscending: false})
+	require.Equal(t, sortSpec(multiple), sortSpec(single))
+}
+
+func TestFindReturnCountFilter(t *testing.T) {
+	// The documents matching the query
+	col := newFakeCollection(t, newItems("a", "b")...)
+	p := FindParams{
+		Collection:        col,
+		Query:             primitive.M{"name": primitive.M{"$ne": "c"}},
+		Limit:             1,
+		CountTotal:        true,
+		ReturnCountFilter: true,
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 2, cursor.Count)
+
+	// The count ignores the cursor boundary
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 2, cursor.Count)
+	require.Equal(t, bson.M{"$and": []bson.M{p.Query}}, cursor.CountFilter)
+	sent, err := toM(cursor.CountFilter)
+	require.NoError(t, err)
+	require.Equal(t, col.countFilters[1], sent)
+
+	count, filter, err := Count(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, cursor.CountFilter, filter)
+
+	// The filter is only returned when requested
+	p.ReturnCountFilter = false
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Nil(t, cursor.CountFilter)
+	_, filter, err = Count(context.Background(), p)
+	require.NoError(t, err)
+	require.Nil(t, filter)
+}
+
+func TestFindCountQuery(t *testing.T) {
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:        col,
+		Query:             primitive.M{"name": primitive.Regex{Pattern: "^a.*o"}},
+		CountQuery:        primitive.M{"name": primitive.M{"$lt": "b"}},
+		Limit:             1,
+		PaginatedField:    "name",
+		SortAscending:     true,
+		CountTotal:        true,
+		ReturnCountFilter: true,
+	}
+
+	// The count uses the CountQuery, the find query the Query
+	col.queuePage(t, newItems("apricot", "avocado")...)
+	col.queueCount(3, 3)
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"apricot"}, itemNames(results))
+	require.Equal(t, 3, cursor.Count)
+	require.Equal(t, bson.M{"$and": []bson.M{p.CountQuery}}, cursor.CountFilter)
+	require.Equal(t, bson.M{"$and": bson.A{p.CountQuery}}, col.countFilters[0])
+	require.Equal(t, sentFilter(t, p), col.findFilters[0])
+	count, _, err := Count(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, bson.M{"$and": bson.A{p.CountQuery}}, col.countFilters[1])
+
+	// The Query is counted without a CountQuery
+	p.CountQuery = nil
+	col.queueCount(2)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 2, cursor.Count)
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query}}), col.countFilters[2])
+}
+
+func TestFindProjection(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		projection         bson.M
+		expectedProjection bson.M
+		expectedIDs        bool
+	}{
+		{"exclusion projection of the paginated field", bson.M{"name": 0, "region": 0}, bson.M{"region": int32(0)}, true},
+		{"inclusion projection leaving out the paginated field and the _id", bson.M{"group": 1, "_id": 0}, bson.M{"group": int32(1), "name": int32(1), "_id": int32(1)}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The documents as projected by mongo, holding the sorted fields and the _id
+			col := newFakeCollection(t)
+			for _, name := range []string{"a", "b", "c"} {
+				col.insert(t, bson.M{"_id": primitive.NewObjectID(), "name": name, "group": "group " + name})
+			}
+			p := FindParams{
+				Collection:     col,
+				Query:          primitive.M{},
+				Limit:          2,
+				SortAscending:  true,
+				PaginatedField: "name",
+				Projection:     tc.projection,
+			}
+			var results []item
+			cursor, err := Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.True(t, cursor.HasNext)
+
+			// The sorted fields and the _id are sent in the projection
+			sent, err := toM(col.findOptions[0].Projection)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedProjection, sent)
+
+			// And left out of the results when the projection leaves them out
+			var groups []string
+			for _, result := range results {
+				require.Empty(t, result.Name)
+				require.Equal(t, tc.expectedIDs, !result.ID.IsZero())
+				groups = append(groups, result.Group)
+			}
+			require.Equal(t, []string{"group a", "group b"}, groups)
+
+			// While the cursors are still generated from them
+			values, err := DecodeCursorToMap(cursor.Next)
+			require.NoError(t, err)
+			require.Equal(t, "b", values["name"])
+		})
+	}
+}
+
+func TestFindHint(t *testing.T) {
+	for _, hint := range []interface{}{"name_1__id_1", bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}} {
+		col := newFakeCollection(t, newItems("a", "b", "c")...)
+		p := FindParams{
+			Collection:     col,
+			Query:          primitive.M{},
+			Limit:          2,
+			PaginatedField: "name",
+			CountTotal:     true,
+			Hint:           hint,
+		}
+		var results []item
+		_, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Len(t, col.findOptions, 1)
+		require.Equal(t, hint, col.findOptions[0].Hint)
+		require.Len(t, col.countOptions, 1)
+		require.Equal(t, hint, col.countOptions[0].Hint)
+	}
+}
+
+func TestFindCountTotal(t *testing.T) {
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"name": primitive.M{"$ne": "g"}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	items := newItems("a", "b", "c", "d", "e", "f")
+	col.queuePages(t, 2, items...)
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queueCount(6, 6, 6, 6)
+
+	// Every page, in either direction, has the count of all the documents matching the query
+	var counts []int
+	var cursor Cursor
+	for {
+		var results []item
+		var err error
+		cursor, err = Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		counts = append(counts, cursor.Count)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	p.Next, p.Previous = "", cursor.Previous
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	counts = append(counts, cursor.Count)
+	require.Equal(t, []int{6, 6, 6, 6}, counts)
+
+	// Counting the query whatever the cursor
+	for _, filter := range col.countFilters {
+		require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query}}), filter)
+	}
+}
+
+func TestFindBatchSize(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection: col,
+		Query:      primitive.M{},
+		Limit:      2,
+		BatchSize:  100,
+	}
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int32(100), *col.findOptions[0].BatchSize)
+
+	// The driver's default is kept when unset
+	p.BatchSize = 0
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Nil(t, col.findOptions[1].BatchSize)
+}
+
+func TestFindMaxLimit(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          1000000,
+		MaxLimit:       2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// A Limit above the MaxLimit is clamped to it
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), *col.findOptions[0].Limit)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.True(t, cursor.HasNext)
+	require.Equal(t, int64(2), cursor.EffectiveLimit)
+
+	// A Limit below it is kept
+	p.Limit = 1
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, itemNames(results))
+	require.Equal(t, int64(1), cursor.EffectiveLimit)
+
+	// A Limit of at least 1 is still required
+	p.Limit = 0
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+}
+
+func TestFindAllowDiskUse(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:   col,
+		Query:        primitive.M{},
+		Limit:        2,
+		AllowDiskUse: true,
+	}
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.True(t, *col.findOptions[0].AllowDiskUse)
+
+	// The server's default is kept when unset
+	p.AllowDiskUse = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Nil(t, col.findOptions[1].AllowDiskUse)
+}
+
+func TestFindFindOptions(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		FindOptions:    options.Find().SetAllowDiskUse(true).SetSort(bson.M{"group": 1}).SetLimit(10),
+	}
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+
+	// The base options survive the merge, the sort and limit of the page taking precedence
+	opts := col.findOptions[0]
+	require.True(t, *opts.AllowDiskUse)
+	require.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, opts.Sort)
+	require.Equal(t, int64(3), *opts.Limit)
+
+	// The base options aren't modified
+	require.Equal(t, int64(10), *p.FindOptions.Limit)
+}
+
+func TestFindLookaheadBatch(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		LookaheadBatch: true,
+	}
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), *col.findOptions[0].Limit)
+	require.Equal(t, int32(3), *col.findOptions[0].BatchSize)
+
+	// An explicit BatchSize takes precedence
+	p.BatchSize = 100
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int32(100), *col.findOptions[1].BatchSize)
+}
+
+func TestFindExactlyLimitDocuments(t *testing.T) {
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, newItems("a", "b", "c", "d")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// The last page is full, without a phantom empty page after it
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.True(t, cursor.HasNext)
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.False(t, cursor.HasNext)
+	require.Empty(t, cursor.Next)
+
+	// As is the first page of a collection holding the limit
+	p.Next = ""
+	p.Collection = newFakeCollection(t, newItems("a", "b")...)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasNext)
+	require.False(t, cursor.HasPrevious)
+
+	// The limit of the find query doesn't overflow with the largest limit
+	col = newFakeCollection(t, newItems("a", "b")...)
+	p.Collection, p.Limit = col, math.MaxInt64
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(math.MaxInt64), *col.findOptions[0].Limit)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasNext)
+}
+
+func TestFindPageSignature(t *testing.T) {
+	col := newFakeCollection(t)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		col.insert(t, bson.M{"_id": primitive.NewObjectID(), "name": name, "version": int32(i)})
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		PaginatedField: "name",
+		SortAscending:  true,
+		VersionField:   "version",
+	}
+	signature := func() string {
+		t.Helper()
+		var results []bson.M
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.NotEmpty(t, cursor.PageSignature)
+		return cursor.PageSignature
+	}
+	first := signature()
+	require.Equal(t, first, signature())
+
+	// Updating a document outside of the page leaves the signature unchanged
+	col.docs[3]["version"] = int32(10)
+	require.Equal(t, first, signature())
+
+	// Updating a document of the page changes it
+	col.docs[1]["version"] = int32(10)
+	updated := signature()
+	require.NotEqual(t, first, updated)
+
+	// Inserting a document in the page changes it
+	col.queuePage(t, col.docs[0], bson.M{"_id": primitive.NewObjectID(), "name": "aa", "version": int32(0)}, col.docs[1])
+	require.NotEqual(t, updated, signature())
+
+	p.VersionField = ""
+	var results []bson.M
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Empty(t, cursor.PageSignature)
+}
+
+func TestFindBindCursorsToFilter(t *testing.T) {
+	col := newFakeCollection(t)
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		status := "active"
+		if i%2 == 1 {
+			status = "archived"
+		}
+		col.insert(t, bson.M{"_id": primitive.NewObjectID(), "name": name, "status": status, "tags": bson.A{"x", "y"}})
+	}
+	tab := func(status string) FindParams {
+		return FindParams{
+			Collection:          col,
+			Query:               primitive.M{"status": status, "name": primitive.M{"$gte": "a", "$lte": "z"}},
+			Limit:               2,
+			SortAscending:       true,
+			PaginatedField:      "name",
+			BindCursorsToFilter: true,
+		}
+	}
+
+	active := tab("active")
+	col.queuePages(t, 2, col.docs[0], col.docs[2], col.docs[4])
+	var results []bson.M
+	cursor, err := Find(context.Background(), active, &results)
+	require.NoError(t, err)
+	active.Next = cursor.Next
+	_, err = Find(context.Background(), active, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "e", results[0]["name"])
+
+	// The stale cursor of the active tab is rejected by the archived tab
+	archived := tab("archived")
+	archived.Next = cursor.Next
+	_, err = Find(context.Background(), archived, &results)
+	require.True(t, errors.Is(err, ErrCursorFilterMismatch))
+	var cursorErr *CursorError
+	require.True(t, errors.As(err, &cursorErr))
+
+	// Cursors without a hash are accepted
+	archived.Next = mustGenerateCursor(t, col.docs[0], []string{"name", "_id"})
+	col.queuePage(t, col.docs[1], col.docs[3], col.docs[5])
+	_, err = Find(context.Background(), archived, &results)
+	require.NoError(t, err)
+	require.Equal(t, "b", results[0]["name"])
+	require.Equal(t, sentFilter(t, archived), col.findFilters[len(col.findFilters)-1])
+
+	// A filter which can't be hashed fails the query rather than binding the cursors to no filter
+	archived.Next, archived.Query = "", primitive.M{"status": make(chan int)}
+	_, err = Find(context.Background(), archived, &results)
+	require.ErrorContains(t, err, "could not hash the filter")
+	require.Len(t, col.findFilters, 3)
+}
+
+func TestFilterHashIgnoresFieldOrder(t *testing.T) {
+	hash := func(query primitive.M) string {
+		h, err := filterHash(FindParams{Query: query})
+		require.NoError(t, err)
+		return h
+	}
+	query := primitive.M{"status": "active", "a": 1, "b": primitive.M{"$in": bson.A{1, 2}}, "c": 3, "d": 4}
+	for i := 0; i < 10; i++ {
+		require.Equal(t, hash(query), hash(primitive.M{"d": 4, "c": 3, "b": primitive.M{"$in": bson.A{1, 2}}, "a": 1, "status": "active"}))
+	}
+	require.NotEqual(t, hash(query), hash(primitive.M{"status": "archived", "a": 1, "b": primitive.M{"$in": bson.A{1, 2}}, "c": 3, "d": 4}))
+}
+
+func TestFindQueryComment(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:   col,
+		Query:        primitive.M{},
+		Limit:        2,
+		CountTotal:   true,
+		QueryComment: "trace 4bf92f3577b34da6",
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.Len(t, results, 2)
+	require.Len(t, col.findOptions, 1)
+	require.NotNil(t, col.findOptions[0].Comment)
+	require.Equal(t, "trace 4bf92f3577b34da6", *col.findOptions[0].Comment)
+	require.Len(t, col.countFilters, 1)
+	require.Equal(t, "trace 4bf92f3577b34da6", col.countFilters[0]["$comment"])
+
+	p.QueryComment = ""
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Nil(t, col.findOptions[1].Comment)
+	require.NotContains(t, col.countFilters[1], "$comment")
+}
+
+func TestFindMaxBytes(t *testing.T) {
+	big := strings.Repeat("b", 1000)
+	items := newItems("a", big, "c", "d", "e")
+	col := newFakeCollection(t, items...)
+	size := func(doc interface{}) int64 {
+		data, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		return int64(len(data))
+	}
+	p := FindParams{
+		Collection:    col,
+		Query:         primitive.M{},
+		Limit:         10,
+		SortAscending: true,
+		MaxBytes:      size(items[0]) + size(items[1]),
+	}
+
+	// The page is cut once the big document has been added
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, big, results[1].Name)
+	require.True(t, cursor.HasNext)
+
+	p.Next = cursor.Next
+	results = nil
+	col.queuePage(t, items[2:]...)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, "c", results[0].Name)
+	require.False(t, cursor.HasNext)
+	require.True(t, cursor.HasPrevious)
+	require.Equal(t, boundaryFilter(t, p, items[1], "$gt"), col.findFilters[1])
+
+	// A document larger than the budget makes a page on its own
+	p.Next = ""
+	p.MaxBytes = 1
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, itemNames(results))
+	require.True(t, cursor.HasNext)
+
+	// The limit still applies within the budget
+	p.MaxBytes = 1 << 20
+	p.Limit = 2
+	results = nil
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.True(t, cursor.HasNext)
+}
+
+func TestCursorRedactedID(t *testing.T) {
+	doc := bson.M{"_id": primitive.NewObjectID(), "email": "jane.doe@example.com"}
+	fields := []string{"email", "_id"}
+	token := mustGenerateCursor(t, doc, fields)
+	cursor := Cursor{Next: token}
+
+	id := cursor.RedactedID()
+	require.Len(t, id, 16)
+	require.Equal(t, id, Cursor{Next: mustGenerateCursor(t, doc, fields)}.RedactedID())
+	require.NotContains(t, id, "jane")
+	require.NotContains(t, id, token[:8])
+
+	require.NotEqual(t, id, Cursor{Previous: token}.RedactedID())
+	other := bson.M{"_id": doc["_id"], "email": "john.doe@example.com"}
+	require.NotEqual(t, id, Cursor{Next: mustGenerateCursor(t, other, fields)}.RedactedID())
+}
+
+func TestFindSkipFallback(t *testing.T) {
+	items := newItems("a", "a", "b", "b", "c", "d", "e")
+	for _, tc := range []struct {
+		name      string
+		threshold int64
+		fallback  bool
+	}{
+		{"fewer documents than the threshold", 8, true},
+		{"as many documents as the threshold", 7, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			col := newFakeCollection(t, items...)
+			p := FindParams{
+				Collection:            col,
+				Query:                 primitive.M{},
+				Limit:                 2,
+				SortAscending:         true,
+				PaginatedField:        "name",
+				SkipFallbackThreshold: tc.threshold,
+			}
+
+			// The pages of a cursor are queried either by skipping the documents before the
+			// cursor or with the keyset query
+			p.Next = mustGenerateCursor(t, items[1], []string{"name", "_id"})
+			col.queueCount(7, 2)
+			var results []item
+			_, err := Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			if tc.fallback {
+				require.Equal(t, int64(2), *col.findOptions[0].Skip)
+				require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query}}), col.findFilters[0])
+			} else {
+				require.Nil(t, col.findOptions[0].Skip)
+				require.Equal(t, boundaryFilter(t, p, items[1], "$gt", "$gt"), col.findFilters[0])
+			}
+
+			// The first page is queried without skipping
+			p.Next = ""
+			_, err = Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.Nil(t, col.findOptions[1].Skip)
+		})
+	}
+}
+
+func TestFindLegacyCursorDecoder(t *testing.T) {
+	items := newItems("a", "b", "c", "d")
+	col := newFakeCollection(t, items...)
+	legacyCalls := 0
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		// The legacy cursors are "legacy:<name>:<hex _id>"
+		LegacyCursorDecoder: func(cursor string) (bson.D, error) {
+			legacyCalls++
+			parts := strings.Split(cursor, ":")
+			if len(parts) != 3 || parts[0] != "legacy" {
+				return nil, errors.New("not a legacy cursor")
+			}
+			id, err := primitive.ObjectIDFromHex(parts[2])
+			if err != nil {
+				return nil, err
+			}
+			return bson.D{{Key: "name", Value: parts[1]}, {Key: "_id", Value: id}}, nil
+		},
+	}
+
+	// A legacy cursor is decoded by the fallback
+	p.Next = "legacy:b:" + items[1].(item).ID.Hex()
+	col.queuePage(t, items[2], items[3])
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, items[1], "$gt", "$gt"), col.findFilters[0])
+	require.Equal(t, 1, legacyCalls)
+
+	// The cursors of the current format don't use the fallback
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, items[1], items[0])
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, boundaryFilter(t, p, items[2], "$lt", "$lt"), col.findFilters[1])
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, 1, legacyCalls)
+
+	// The error of the primary decoding is returned when both fail
+	p.Previous = "legacy:b:not-hex"
+	_, err = Find(context.Background(), p, &results)
+	_, primaryErr := decodeCursor(p.Previous)
+	require.Error(t, primaryErr)
+	require.EqualError(t, err, "previous cursor parse failed: "+primaryErr.Error())
+	require.Equal(t, []error{nil, &CursorError{fmt.Errorf("cursor parse failed: %w", primaryErr)}}, p.ValidateCursors([]string{"legacy:a:" + items[0].(item).ID.Hex(), p.Previous}))
+}
+
+func TestFindMaxPageDepth(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e", "f", "g", "h")
+	col := newFakeCollection(t)
+	col.queuePage(t, items[0:3]...)
+	col.queuePage(t, items[2:5]...)
+	col.queuePage(t, items[4:7]...)
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queuePage(t, items[4:7]...)
+	col.queuePage(t, items[4:7]...)
+	col.queuePage(t, items[6:]...)
+	p := FindParams{
+		Collection:    col,
+		Query:         primitive.M{},
+		Limit:         2,
+		SortAscending: true,
+		MaxPageDepth:  3,
+	}
+
+	var cursor Cursor
+	var results []item
+	for depth := 1; depth <= 3; depth++ {
+		var err error
+		cursor, err = Find(context.Background(), p, &results)
+		require.NoError(t, err, "depth %d", depth)
+		require.True(t, cursor.HasNext)
+		_, metadata, err := parseCursorData(ensureDefaults(p), cursor.Next)
+		require.NoError(t, err)
+		require.Equal(t, bson.D{{Key: cursorDepthKey, Value: int32(depth)}}, metadata)
+		p.Next = cursor.Next
+	}
+	_, err := Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrMaxDepthExceeded))
+
+	// Going back from the deepest page decreases the depth
+	p.Next, p.Previous = "", cursor.Previous
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, "c", results[0].Name)
+	p.Next, p.Previous = cursor.Next, ""
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, "e", results[0].Name)
+
+	// The depth isn't limited nor held by the cursors without the option
+	p.MaxPageDepth = 0
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	_, metadata, err := parseCursorData(ensureDefaults(p), cursor.Previous)
+	require.NoError(t, err)
+	require.Empty(t, metadata)
+}
+
+// expiringCollection is a fakeCollection moving the clock on by a step at every find query.
+type expiringCollection struct {
+	*fakeCollection
+	now  *time.Time
+	step time.Duration
+}
+
+func (c expiringCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	*c.now = c.now.Add(c.step)
+	return c.fakeCollection.Find(ctx, filter, opts...)
+}
+
+func TestFindMaxPageDepthExpiringCursor(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	items := newItems("a", "b", "c", "d", "e", "f", "g")
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	p := FindParams{
+		// The cursors expire while their page is queried
+		Collection:    expiringCollection{col, &now, time.Minute},
+		Query:         primitive.M{},
+		Limit:         2,
+		SortAscending: true,
+		MaxPageDepth:  3,
+		CursorTTL:     time.Minute,
+	}
+
+	// The depth of the cursors of a page is the one of the cursor parsed for its query
+	var results []item
+	for depth := 1; depth <= 3; depth++ {
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		_, metadata, err := parseCursorData(ensureDefaults(p), cursor.Next)
+		require.NoError(t, err)
+		require.Contains(t, metadata, bson.E{Key: cursorDepthKey, Value: int32(depth)})
+		p.Next = cursor.Next
+	}
+	_, err := Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrMaxDepthExceeded))
+}
+
+func TestFindMiddlePageNavigatesBothWays(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e", "f")
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	for _, middle := range [][]interface{}{items[2:5], {items[3], items[2], items[1]}} {
+		col.queuePage(t, middle...)
+		col.queuePage(t, items[1], items[0])
+		col.queuePage(t, items[4:]...)
+	}
+	p := FindParams{
+		Collection:    col,
+		Query:         primitive.M{},
+		Limit:         2,
+		SortAscending: true,
+	}
+	find := func(next, previous string) ([]string, Cursor) {
+		t.Helper()
+		p.Next, p.Previous = next, previous
+		var results []item
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		return itemNames(results), cursor
+	}
+
+	_, first := find("", "")
+	_, last := find(first.Next, "")
+	_, last = find(last.Next, "")
+
+	// The middle page reached forward and backward has both cursors
+	for _, middle := range []func() ([]string, Cursor){
+		func() ([]string, Cursor) { return find(first.Next, "") },
+		func() ([]string, Cursor) { return find("", last.Previous) },
+	} {
+		page, cursor := middle()
+		require.Equal(t, []string{"c", "d"}, page)
+		require.True(t, cursor.HasPrevious)
+		require.True(t, cursor.HasNext)
+		require.NotEmpty(t, cursor.Previous)
+		require.NotEmpty(t, cursor.Next)
+
+		previous, _ := find("", cursor.Previous)
+		require.Equal(t, []string{"a", "b"}, previous)
+		next, _ := find(cursor.Next, "")
+		require.Equal(t, []string{"e", "f"}, next)
+	}
+}
+
+func TestFindBidirectionalProbe(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e", "f")
+	a, b, c, d, e, f := items[0], items[1], items[2], items[3], items[4], items[5]
+	newParams := func(col *fakeCollection) FindParams {
+		return FindParams{
+			Collection:         col,
+			Query:              primitive.M{},
+			Limit:              2,
+			SortAscending:      true,
+			BidirectionalProbe: true,
+		}
+	}
+	find := func(p FindParams, next, previous string) ([]item, Cursor) {
+		t.Helper()
+		p.Next, p.Previous = next, previous
+		var results []item
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		return results, cursor
+	}
+	requireFlags := func(cursor Cursor, hasPrevious, hasNext bool) {
+		t.Helper()
+		require.Equal(t, hasPrevious, cursor.HasPrevious)
+		require.Equal(t, hasPrevious, cursor.Previous != "")
+		require.Equal(t, hasNext, cursor.HasNext)
+		require.Equal(t, hasNext, cursor.Next != "")
+	}
+	cursorOf := func(doc interface{}) string { return mustGenerateCursor(t, doc, []string{"_id"}) }
+	// requireProbe requires the last query to be the probe of the documents of the page of the
+	// FindParams
+	requireProbe := func(col *fakeCollection, page FindParams) {
+		t.Helper()
+		last := len(col.findOptions) - 1
+		require.Equal(t, sentFilter(t, page), col.findFilters[last])
+		require.Equal(t, int64(1), *col.findOptions[last].Limit)
+		require.Equal(t, bson.M{"_id": 1}, col.findOptions[last].Projection)
+	}
+
+	t.Run("first, middle and last pages", func(t *testing.T) {
+		col := newFakeCollection(t)
+		p := newParams(col)
+		col.queuePage(t, a, b, c)
+		_, first := find(p, "", "")
+		requireFlags(first, false, true)
+
+		// The documents before the page of a Next cursor are probed from its first document
+		col.queuePage(t, c, d, e)
+		col.queuePage(t, a)
+		_, middle := find(p, first.Next, "")
+		requireFlags(middle, true, true)
+		before := p
+		before.Previous = cursorOf(c)
+		requireProbe(col, before)
+		col.queuePage(t, e, f)
+		col.queuePage(t, a)
+		_, last := find(p, middle.Next, "")
+		requireFlags(last, true, false)
+
+		// The documents after the page of a Previous cursor from its last one
+		col.queuePage(t, d, c, b)
+		col.queuePage(t, e)
+		_, middle = find(p, "", last.Previous)
+		requireFlags(middle, true, true)
+		after := p
+		after.Next = cursorOf(d)
+		requireProbe(col, after)
+		col.queuePage(t, b, a)
+		col.queuePage(t, c)
+		_, first = find(p, "", middle.Previous)
+		requireFlags(first, false, true)
+
+		// A single probe query for each page of a cursor
+		require.Len(t, col.findOptions, 5+4)
+		require.Empty(t, col.pages)
+	})
+
+	t.Run("nothing before the page of a Next cursor", func(t *testing.T) {
+		col := newFakeCollection(t)
+		p := newParams(col)
+		p.BidirectionalProbe = false
+		col.queuePage(t, c, d, e)
+		_, page := find(p, cursorOf(b), "")
+		requireFlags(page, true, true)
+		p.BidirectionalProbe = true
+		col.queuePage(t, c, d, e)
+		col.queuePage(t)
+		_, page = find(p, cursorOf(b), "")
+		requireFlags(page, false, true)
+	})
+
+	t.Run("nothing after the page of a Previous cursor", func(t *testing.T) {
+		col := newFakeCollection(t)
+		p := newParams(col)
+		p.BidirectionalProbe = false
+		col.queuePage(t, d, c, b)
+		_, page := find(p, "", cursorOf(e))
+		requireFlags(page, true, true)
+		p.BidirectionalProbe = true
+		col.queuePage(t, d, c, b)
+		col.queuePage(t)
+		_, page = find(p, "", cursorOf(e))
+		requireFlags(page, true, false)
+	})
+
+	t.Run("empty page of a Next cursor", func(t *testing.T) {
+		col := newFakeCollection(t)
+		p := newParams(col)
+		col.queuePage(t)
+		col.queuePage(t, b)
+		results, page := find(p, cursorOf(b), "")
+		require.Empty(t, results)
+		require.True(t, page.HasPrevious)
+		require.False(t, page.HasNext)
+
+		// The documents are probed from the cursor, its boundary document included
+		before := p
+		before.Previous, before.includeBoundary = cursorOf(b), true
+		requireProbe(col, before)
+	})
+}
+
+func TestFindComputeRanks(t *testing.T) {
+	// The known ordering is a, b, b, c, d, e
+	items := newItems("a", "b", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	col.queueCount(6, 0, 6, 2, 6, 4)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+	var ranks []int
+	var names []string
+	var cursor Cursor
+	for {
+		var results []item
+		var err error
+		cursor, err = Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, 6, cursor.Count)
+		require.Len(t, cursor.Ranks, len(results))
+		for _, result := range results {
+			names = append(names, result.Name)
+		}
+		ranks = append(ranks, cursor.Ranks...)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	require.Equal(t, []string{"a", "b", "b", "c", "d", "e"}, names)
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6}, ranks)
+
+	// The documents before the first result of the page are counted
+	values, err := parseCursor(ensureDefaults(p), mustGenerateCursor(t, items[2], []string{"name", "_id"}))
+	require.NoError(t, err)
+	before, err := besideQuery(ensureDefaults(p), values, true, false)
+	require.NoError(t, err)
+	require.Equal(t, mustToM(t, bson.M{"$and": bson.A{p.Query, before}}), col.countFilters[3])
+
+	// Ranks of a previous page
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queueCount(6, 2)
+	cursor, err = Find(context.Background(), p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4}, cursor.Ranks)
+
+	// Ranks in descending order
+	p.Previous, p.SortAscending = "", false
+	col.queuePage(t, items[5], items[4], items[3])
+	col.queueCount(6, 0)
+	cursor, err = Find(context.Background(), p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, cursor.Ranks)
+
+	// Ranks aren't computed without CountTotal
+	p.CountTotal = false
+	col.queuePage(t, items[5], items[4], items[3])
+	cursor, err = Find(context.Background(), p, &[]item{})
+	require.NoError(t, err)
+	require.Nil(t, cursor.Ranks)
+}
+
+func TestCursorJSON(t *testing.T) {
+	cursor := Cursor{
+		Next:           "next",
+		HasNext:        true,
+		StartCursor:    "start",
+		EndCursor:      "next",
+		Returned:       2,
+		EffectiveLimit: 2,
+		Count:          5,
+		Ranks:          []int{1, 2},
+	}
+	data, err := json.Marshal(cursor)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"next": "next",
+		"hasPrevious": false,
+		"hasNext": true,
+		"startCursor": "start",
+		"endCursor": "next",
+		"returned": 2,
+		"effectiveLimit": 2,
+		"count": 5,
+		"ranks": [1, 2]
+	}`, string(data))
+	var decoded Cursor
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, cursor, decoded)
+
+	// The BSON field names are unchanged
+	data, err = bson.Marshal(cursor)
+	require.NoError(t, err)
+	raw := bson.Raw(data)
+	require.Equal(t, "next", raw.Lookup("next").StringValue())
+	require.True(t, raw.Lookup("hasnext").Boolean())
+	_, err = raw.LookupErr("hasNext")
+	require.Error(t, err)
+}
+
+func TestReverseCursor(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	col.queuePage(t, items[0:3]...)
+	col.queuePage(t, items[2:5]...)
+	col.queuePage(t, items[2], items[1], items[0])
+	col.queuePage(t, items[3:]...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, cursor.Next, ReverseCursor(cursor.Next))
+
+	// The Next cursor of c, d passed as Previous returns the page ending before d
+	p.Next, p.Previous = "", ReverseCursor(cursor.Next)
+	reversedCursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, itemNames(results))
+	require.True(t, reversedCursor.HasPrevious)
+	require.True(t, reversedCursor.HasNext)
+
+	// And its Previous cursor passed as Next returns the page starting after c
+	p.Next, p.Previous = ReverseCursor(cursor.Previous), ""
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "e"}, itemNames(results))
+}
+
+func TestFindStartAndEndCursors(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	col.queuePage(t, items[3], items[2], items[1])
+	col.queuePage(t, items[1], items[0])
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// The first page has a start cursor but no Previous cursor
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Empty(t, cursor.Previous)
+	require.NotEmpty(t, cursor.StartCursor)
+	require.Equal(t, cursor.Next, cursor.EndCursor)
+	first := cursor
+
+	// On the middle page they're the Previous and Next cursors
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, cursor.Previous, cursor.StartCursor)
+	require.Equal(t, cursor.Next, cursor.EndCursor)
+
+	// The last page has an end cursor but no Next cursor
+	p.Next = cursor.Next
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e"}, itemNames(results))
+	require.Empty(t, cursor.Next)
+	require.Equal(t, cursor.Previous, cursor.StartCursor)
+	require.NotEmpty(t, cursor.EndCursor)
+
+	// They're the cursors of the first and last results whatever the direction
+	p.Next, p.Previous = "", cursor.StartCursor
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	p.Previous = cursor.StartCursor
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, first.StartCursor, cursor.StartCursor)
+	require.Equal(t, first.EndCursor, cursor.EndCursor)
+
+	// An empty page has none
+	p.Previous, p.Query = "", primitive.M{"name": "z"}
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	require.Empty(t, cursor.StartCursor)
+	require.Empty(t, cursor.EndCursor)
+}
+
+func TestFindUnknownPaginatedField(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		PaginatedField: "nmae",
+	}
+
+	// The field isn't a field of item, so no query is run
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+	require.EqualError(t, err, "the paginated field isn't a field of the results, got nmae for mongo.item")
+	require.Empty(t, col.findOptions)
+
+	// Nor is a path into a field that isn't a document
+	p.PaginatedField = "name.first"
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+
+	// The fields of maps are dynamic
+	p.PaginatedField = "nmae"
+	var docs []bson.M
+	_, err = Find(context.Background(), p, &docs)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+}
+
+func TestFindReturned(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	col.queuePages(t, 2, items...)
+	col.queuePage(t, items[3], items[2], items[1])
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// The full pages return the Limit, the partial last page the documents left, without the
+	// document queried to see if there's a next page
+	var returned []int64
+	var cursor Cursor
+	for {
+		var results []item
+		var err error
+		cursor, err = Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(results)), cursor.Returned)
+		returned = append(returned, cursor.Returned)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	require.Equal(t, []int64{2, 2, 1}, returned)
+
+	// The pages of a Previous cursor are full
+	p.Next, p.Previous = "", cursor.Previous
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cursor.Returned)
+
+	// An empty page returns none
+	p.Previous, p.Query = "", primitive.M{"name": "z"}
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), cursor.Returned)
+}
+
+func TestFindNaturalOrder(t *testing.T) {
+	items := newItems("a", "b", "c")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:    col,
+		Query:         primitive.M{},
+		Limit:         2,
+		SortAscending: true,
+		NaturalOrder:  true,
+	}
+
+	// The pages are sorted in natural order, the cursors holding the _id
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, bson.D{{Key: "$natural", Value: 1}}, col.findOptions[0].Sort)
+	require.Equal(t, mustGenerateCursor(t, items[1], []string{"_id"}), cursor.Next)
+
+	// The pages of the cursors are queried from the _id
+	p.Next = cursor.Next
+	col.queuePage(t, items[2])
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$and": bson.A{bson.M{}, bson.M{"_id": bson.M{"$gt": items[1].(item).ID}}}}, col.findFilters[1])
+	require.Equal(t, bson.D{{Key: "$natural", Value: 1}}, col.findOptions[1].Sort)
+	p.Next = ""
+	p.SortAscending = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "$natural", Value: -1}}, col.findOptions[2].Sort)
+	p.SortAscending = true
+
+	// Only the _id can be paginated on
+	p.PaginatedField = "name"
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrNaturalOrderSort))
+	p.PaginatedField, p.TieBreakerFields = "_id", []string{"seq"}
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrNaturalOrderSort))
+	p.TieBreakerFields = nil
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+}
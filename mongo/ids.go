@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FindIDs executes a find mongo query by using the provided FindParams and returns the _id of the
+// results, in sort order, along with the Cursor of the page. Only the _id and the sorted fields are
+// returned by mongo, so the query is covered by an index on these fields, and the full documents
+// can then be fetched in batch, e.g. from a cache.
+func FindIDs(ctx context.Context, p FindParams) ([]interface{}, Cursor, error) {
+	projection := bson.M{}
+	for _, field := range sortFields(ensureDefaults(p)) {
+		projection[field] = 1
+	}
+	var results []bson.Raw
+	cursor, err := find(ctx, p, &results, projection)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	ids := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		var doc struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := bson.Unmarshal(result, &doc); err != nil {
+			return nil, Cursor{}, fmt.Errorf("could not decode the _id of a result: %s", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor, nil
+}
@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindIDs(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t)
+	idOf := func(i int) interface{} { return items[i].(item).ID }
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// Only the sorted fields are queried
+	col.queuePage(t, items[:3]...)
+	ids, cursor, err := FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{idOf(0), idOf(1)}, ids)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, bson.M{"name": 1, "_id": 1}, col.findOptions[0].Projection)
+
+	// The cursor is valid for both FindIDs and Find, querying the same page
+	p.Next = cursor.Next
+	col.queuePage(t, items[2:5]...)
+	col.queuePage(t, items[2:5]...)
+	ids, cursor, err = FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{idOf(2), idOf(3)}, ids)
+	var results []item
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{items[2], items[3]}, []interface{}{results[0], results[1]})
+	require.Equal(t, col.findFilters[1], col.findFilters[2])
+	require.Nil(t, col.findOptions[2].Projection)
+
+	p.Next = cursor.Next
+	col.queuePage(t, items[4])
+	ids, cursor, err = FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{idOf(4)}, ids)
+	require.False(t, cursor.HasNext)
+	require.True(t, cursor.HasPrevious)
+}
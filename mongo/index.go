@@ -0,0 +1,181 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// IndexedCollection is a Collection which can list its indexes, e.g. a wrapper of a
+// *mongo.Collection calling the List method of its Indexes. The Collection of FindParams with
+// RequireIndex must implement it.
+type IndexedCollection interface {
+	Collection
+	ListIndexes(ctx context.Context) (MongoCursor, error)
+}
+
+var (
+	// ErrNoSupportingIndex is the error returned when the FindParams have RequireIndex and no index
+	// of the Collection supports the sort
+	ErrNoSupportingIndex = errors.New("no index supports the sort")
+	// ErrCollectionNotIndexed is the error returned when querying with RequireIndex a Collection
+	// which doesn't implement IndexedCollection
+	ErrCollectionNotIndexed = errors.New("RequireIndex requires an IndexedCollection")
+)
+
+// The bounds of the cache of the supported sorts: a cached sort is checked again once its TTL
+// elapsed, e.g. in case its index was dropped, and the cache holds a bounded number of sorts.
+const (
+	supportingIndexTTL   = time.Minute
+	maxSupportingIndexes = 1000
+)
+
+// supportingIndexes caches the sorts a supporting index was found for, by collection, along with
+// the time their entry expires. Only the supported sorts are cached, so an index created after a
+// failed check is found by the next one.
+var supportingIndexes = struct {
+	sync.Mutex
+	expiries map[supportingIndexKey]time.Time
+}{expiries: map[supportingIndexKey]time.Time{}}
+
+type supportingIndexKey struct {
+	collection Collection
+	sort       string
+}
+
+// cachedSupportingIndex returns true if the sort of the key was found to be supported by an index
+// of its collection less than the TTL ago.
+func cachedSupportingIndex(key supportingIndexKey) bool {
+	supportingIndexes.Lock()
+	defer supportingIndexes.Unlock()
+	expiry, ok := supportingIndexes.expiries[key]
+	if ok && !timeNow().Before(expiry) {
+		delete(supportingIndexes.expiries, key)
+		return false
+	}
+	return ok
+}
+
+// cacheSupportingIndex caches the sort of the key as supported by an index of its collection. When
+// the cache is full, the expired entries are evicted, or the entry expiring first if none is.
+func cacheSupportingIndex(key supportingIndexKey) {
+	supportingIndexes.Lock()
+	defer supportingIndexes.Unlock()
+	now := timeNow()
+	if _, ok := supportingIndexes.expiries[key]; !ok && len(supportingIndexes.expiries) >= maxSupportingIndexes {
+		var oldest supportingIndexKey
+		var oldestExpiry time.Time
+		for k, expiry := range supportingIndexes.expiries {
+			if !now.Before(expiry) {
+				delete(supportingIndexes.expiries, k)
+			} else if oldestExpiry.IsZero() || expiry.Before(oldestExpiry) {
+				oldest, oldestExpiry = k, expiry
+			}
+		}
+		if len(supportingIndexes.expiries) >= maxSupportingIndexes {
+			delete(supportingIndexes.expiries, oldest)
+		}
+	}
+	supportingIndexes.expiries[key] = now.Add(supportingIndexTTL)
+}
+
+// requireIndex returns ErrNoSupportingIndex unless an index of the Collection of the FindParams
+// supports their sort.
+func requireIndex(ctx context.Context, p FindParams) error {
+	col, ok := unwrapCollection(p.Collection).(IndexedCollection)
+	if !ok {
+		return ErrCollectionNotIndexed
+	}
+	spec := sortSpec(ensureDefaults(p))
+	var sort strings.Builder
+	for _, field := range spec {
+		sort.WriteString(field.Name)
+		if field.Ascending {
+			sort.WriteString(",1;")
+		} else {
+			sort.WriteString(",-1;")
+		}
+	}
+	// Collections of non comparable types can't be map keys, and are checked every time
+	key := supportingIndexKey{collection: col, sort: sort.String()}
+	cacheable := reflect.TypeOf(col).Comparable()
+	if cacheable && cachedSupportingIndex(key) {
+		return nil
+	}
+
+	cursor, err := col.ListIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var index struct {
+			Key bson.D `bson:"key"`
+		}
+		if err := cursor.Decode(&index); err != nil {
+			return err
+		}
+		if supportsSort(index.Key, spec) {
+			if cacheable {
+				cacheSupportingIndex(key)
+			}
+			return nil
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return ErrNoSupportingIndex
+}
+
+// unwrapCollection returns the Collection the registryCollection and readOptionsCollection
+// wrappers execute the queries on, whose indexes are the ones of the collection.
+func unwrapCollection(col Collection) Collection {
+	for {
+		switch wrapper := col.(type) {
+		case *registryCollection:
+			col = wrapper.Collection
+		case *readOptionsCollection:
+			col = wrapper.Collection
+		default:
+			return col
+		}
+	}
+}
+
+// supportsSort reports whether the index key starts with the fields of the spec, their directions
+// being either all the ones of the spec or all reversed.
+func supportsSort(key bson.D, spec []SortField) bool {
+	if len(key) < len(spec) {
+		return false
+	}
+	reversed := false
+	for i, field := range spec {
+		if key[i].Key != field.Name {
+			return false
+		}
+		var ascending bool
+		switch dir := key[i].Value.(type) {
+		case int32:
+			ascending = dir > 0
+		case int64:
+			ascending = dir > 0
+		case float64:
+			ascending = dir > 0
+		default:
+			// e.g. a text or hashed index field
+			return false
+		}
+		if i == 0 {
+			reversed = ascending != field.Ascending
+		} else if reversed != (ascending != field.Ascending) {
+			return false
+		}
+	}
+	return true
+}
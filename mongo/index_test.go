@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// indexedCollection lists the keys of its indexes and records the number of listings.
+type indexedCollection struct {
+	*fakeCollection
+	keys     []bson.D
+	listings int
+}
+
+func (c *indexedCollection) ListIndexes(ctx context.Context) (MongoCursor, error) {
+	c.listings++
+	cursor := &fakeCursor{current: -1}
+	for _, key := range c.keys {
+		data, err := bson.Marshal(bson.M{"key": key})
+		if err != nil {
+			return nil, err
+		}
+		cursor.docs = append(cursor.docs, data)
+	}
+	return cursor, nil
+}
+
+func TestFindRequireIndex(t *testing.T) {
+	col := &indexedCollection{
+		fakeCollection: newFakeCollection(t, newItems("a", "b", "c")...),
+		keys:           []bson.D{{{Key: "_id", Value: int32(1)}}},
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		RequireIndex:   true,
+	}
+
+	// Without a supporting index the page isn't queried
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrNoSupportingIndex))
+	require.Empty(t, col.findOptions)
+
+	// Indexes in another order or direction don't support the sort
+	col.keys = append(col.keys,
+		bson.D{{Key: "_id", Value: int32(1)}, {Key: "name", Value: int32(1)}},
+		bson.D{{Key: "name", Value: int32(1)}, {Key: "_id", Value: int32(-1)}},
+		bson.D{{Key: "name", Value: "text"}, {Key: "_id", Value: int32(1)}},
+	)
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrNoSupportingIndex))
+	require.Equal(t, 2, col.listings)
+
+	// An index starting with the sorted fields, reversed, supports the sort and is cached
+	col.keys = append(col.keys, bson.D{{Key: "name", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}, {Key: "userId", Value: int32(1)}})
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, col.listings)
+
+	// The sort descending is supported by the same index, but checked on its own
+	p.SortAscending = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: -1}}, col.findOptions[len(col.findOptions)-1].Sort)
+	require.Equal(t, 4, col.listings)
+
+	// The cached sorts are checked again once their TTL elapsed, e.g. when the index was dropped
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	start := time.Now()
+	timeNow = func() time.Time { return start.Add(supportingIndexTTL) }
+	col.keys = col.keys[:1]
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrNoSupportingIndex))
+	require.Equal(t, 5, col.listings)
+
+	p.Collection = col.fakeCollection
+	_, err = Find(context.Background(), p, &results)
+	require.Equal(t, ErrCollectionNotIndexed, err)
+	p.RequireIndex = false
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+}
+
+func TestCacheSupportingIndexBound(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	defer func() { supportingIndexes.expiries = map[supportingIndexKey]time.Time{} }()
+	start := time.Now()
+	timeNow = func() time.Time { return start }
+	col := newFakeCollection(t)
+
+	// The cache never holds more than its bound, evicting the entries expiring first
+	for i := 0; i < maxSupportingIndexes+10; i++ {
+		timeNow = func() time.Time { return start.Add(time.Duration(i) * time.Millisecond) }
+		cacheSupportingIndex(supportingIndexKey{collection: col, sort: fmt.Sprint(i)})
+	}
+	require.Len(t, supportingIndexes.expiries, maxSupportingIndexes)
+	require.False(t, cachedSupportingIndex(supportingIndexKey{collection: col, sort: "0"}))
+	require.True(t, cachedSupportingIndex(supportingIndexKey{collection: col, sort: fmt.Sprint(maxSupportingIndexes + 9)}))
+}
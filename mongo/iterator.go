@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrBudgetExceeded is the error returned by a PageIterator whose page queries used up its
+// execution budget
+var ErrBudgetExceeded = errors.New("execution budget exceeded")
+
+// PageIterator iterates over the pages of a paginated find query, starting from the page of the
+// Next or Previous cursor of its FindParams and moving towards the next pages. The page queries
+// share an execution budget, so a long traversal can't consume unbounded database time.
+type PageIterator struct {
+	p      FindParams
+	budget time.Duration
+	spent  time.Duration
+	cursor Cursor
+	done   bool
+	err    error
+}
+
+// NewPageIterator returns a PageIterator over the pages of the FindParams, whose page queries may
+// take up to the budget in total, or as long as needed when the budget is 0. Each page query is
+// bounded server-side by the budget left.
+func NewPageIterator(p FindParams, budget time.Duration) *PageIterator {
+	return &PageIterator{p: p, budget: budget}
+}
+
+// Next fills the passed in result slice pointer with the next page and returns true, or returns
+// false when there are no more pages or when an error occurred, which Err then returns.
+func (it *PageIterator) Next(ctx context.Context, results interface{}) bool {
+	if it.done {
+		return false
+	}
+	p := it.p
+	if it.budget > 0 {
+		if it.spent >= it.budget {
+			it.done, it.err = true, ErrBudgetExceeded
+			return false
+		}
+		p.maxTime = it.budget - it.spent
+	}
+
+	start := timeNow()
+	cursor, err := Find(ctx, p, results)
+	it.spent += timeNow().Sub(start)
+	if err != nil {
+		it.done, it.err = true, err
+		if it.budget > 0 && it.spent >= it.budget {
+			it.err = fmt.Errorf("%w: %s", ErrBudgetExceeded, err)
+		}
+		return false
+	}
+
+	it.cursor = cursor
+	if cursor.HasNext {
+		if p.DeltaCursors {
+			// The Next cursor of the page is relative to the one the page was queried with
+			it.p.deltaBase, _, _ = parseCursorData(cursorParams(p), p.Next)
+		}
+		it.p.Next, it.p.Previous = cursor.Next, ""
+	} else {
+		it.done = true
+	}
+	return true
+}
+
+// Cursor returns the Cursor of the last page returned by Next.
+func (it *PageIterator) Cursor() Cursor {
+	return it.cursor
+}
+
+// ResumeCursor returns the cursor to pass as the Next of a FindParams to resume the traversal
+// after the last page returned by Next, e.g. once the iterator stopped with ErrBudgetExceeded. It
+// is empty when the traversal hasn't gone past its first page. It's encoded in full with
+// DeltaCursors.
+func (it *PageIterator) ResumeCursor() string {
+	data, err := decodeCursorBytes(it.p.Next)
+	if err != nil || !isDeltaCursor(data) {
+		return it.p.Next
+	}
+	p := ensureDefaults(it.p)
+	values, _, err := parseCursorData(p, p.Next)
+	if err != nil {
+		return it.p.Next
+	}
+	fields := sortFields(p)
+	boundary := make(bson.D, 0, len(fields))
+	for i, field := range fields {
+		boundary = append(boundary, bson.E{Key: field, Value: values[i]})
+	}
+	cursor, err := generatePageCursor(p, boundary, fields, nil)
+	if err != nil {
+		return it.p.Next
+	}
+	return cursor
+}
+
+// Spent returns the time the page queries took so far.
+func (it *PageIterator) Spent() time.Duration {
+	return it.spent
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,86 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// slowCollection advances the clock by a delay on every find query.
+type slowCollection struct {
+	*fakeCollection
+	now   *time.Time
+	delay time.Duration
+}
+
+func (c *slowCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	*c.now = c.now.Add(c.delay)
+	return c.fakeCollection.Find(ctx, filter, opts...)
+}
+
+func TestPageIteratorBudget(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNowOri := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() {
+		timeNow = timeNowOri
+	}()
+	items := newItems("a", "b", "c", "d", "e", "f", "g", "h", "i", "j")
+	col := &slowCollection{
+		fakeCollection: newFakeCollection(t),
+		now:            &now,
+		delay:          40 * time.Millisecond,
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// The third page goes over the budget, the iterator stops before the fourth one
+	col.queuePage(t, items[0:3]...)
+	col.queuePage(t, items[2:5]...)
+	col.queuePage(t, items[4:7]...)
+	it := NewPageIterator(p, 100*time.Millisecond)
+	var names []string
+	for {
+		var page []item
+		if !it.Next(context.Background(), &page) {
+			break
+		}
+		names = append(names, itemNames(page)...)
+	}
+	require.True(t, errors.Is(it.Err(), ErrBudgetExceeded))
+	require.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, names)
+	require.Equal(t, 120*time.Millisecond, it.Spent())
+	require.Equal(t, it.Cursor().Next, it.ResumeCursor())
+
+	// Each page query is bounded by the budget left
+	require.Len(t, col.findOptions, 3)
+	require.Equal(t, 100*time.Millisecond, *col.findOptions[0].MaxTime)
+	require.Equal(t, 60*time.Millisecond, *col.findOptions[1].MaxTime)
+	require.Equal(t, 20*time.Millisecond, *col.findOptions[2].MaxTime)
+
+	// The traversal resumes from the cursor
+	p.Next = it.ResumeCursor()
+	col.queuePages(t, 2, items[6:]...)
+	it = NewPageIterator(p, 0)
+	names = nil
+	for {
+		var page []item
+		if !it.Next(context.Background(), &page) {
+			break
+		}
+		names = append(names, itemNames(page)...)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []string{"g", "h", "i", "j"}, names)
+	require.Nil(t, col.findOptions[len(col.findOptions)-1].MaxTime)
+}
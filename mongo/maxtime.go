@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrMaxTimeExceeded is the error wrapped by the errors returned when mongo aborted a query which
+// ran for longer than the MaxTime of the FindParams
+var ErrMaxTimeExceeded = errors.New("maximum execution time exceeded")
+
+// maxTimeExpiredCode is the code of the command errors of the queries aborted by mongo once their
+// maximum execution time elapsed
+const maxTimeExpiredCode = 50
+
+// queryMaxTime returns the maximum execution time of the queries of the FindParams, the shortest
+// of its MaxTime and of the budget left of the PageIterator executing them, or 0 if neither is set.
+func queryMaxTime(p FindParams) time.Duration {
+	if p.MaxTime > 0 && (p.maxTime <= 0 || p.MaxTime < p.maxTime) {
+		return p.MaxTime
+	}
+	return p.maxTime
+}
+
+// maxTimeError wraps ErrMaxTimeExceeded in the error of a query if mongo aborted it because it ran
+// for longer than its maximum execution time, otherwise it returns the error as is.
+func maxTimeError(err error) error {
+	var cmdErr driver.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == maxTimeExpiredCode {
+		return fmt.Errorf("%w: %s", ErrMaxTimeExceeded, err)
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// abortingCollection fails the queries like mongo does once their maximum execution time elapsed.
+type abortingCollection struct {
+	*fakeCollection
+}
+
+var errMaxTimeExpired = driver.CommandError{Code: maxTimeExpiredCode, Name: "MaxTimeMSExpired", Message: "operation exceeded time limit"}
+
+func (c *abortingCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return 0, errMaxTimeExpired
+}
+
+func (c *abortingCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	return nil, errMaxTimeExpired
+}
+
+func TestFindMaxTime(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+		MaxTime:        time.Second,
+	}
+	require.Equal(t, time.Second, *findOptions(p, nil, nil).MaxTime)
+	require.Equal(t, time.Second, *countOptions(p).MaxTime)
+
+	// The duration is forwarded to the find and count queries
+	var results []item
+	_, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Len(t, col.findOptions, 1)
+	require.Equal(t, time.Second, *col.findOptions[0].MaxTime)
+	require.Len(t, col.countOptions, 2)
+	for _, opts := range col.countOptions {
+		require.Equal(t, time.Second, *opts.MaxTime)
+	}
+
+	// The budget left of a PageIterator takes precedence when it's shorter
+	p.maxTime = 500 * time.Millisecond
+	require.Equal(t, 500*time.Millisecond, queryMaxTime(p))
+	p.maxTime = 2 * time.Second
+	require.Equal(t, time.Second, queryMaxTime(p))
+	p.MaxTime = 0
+	require.Equal(t, 2*time.Second, queryMaxTime(p))
+	p.maxTime = 0
+	require.Nil(t, findOptions(p, nil, nil).MaxTime)
+	require.Nil(t, countOptions(p).MaxTime)
+
+	// The queries aborted by mongo return a recognizable error
+	p.Collection = &abortingCollection{col}
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrMaxTimeExceeded))
+	require.EqualError(t, err, "maximum execution time exceeded: (MaxTimeMSExpired) operation exceeded time limit")
+	p.CountTotal = false
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrMaxTimeExceeded))
+}
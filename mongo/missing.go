@@ -0,0 +1,93 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// missingLast returns true if the documents whose first sorted field is null or missing are moved
+// after the other ones, i.e. if TreatMissingAsLast is true and the field is sorted ascending, since
+// MongoDB sorts the null and missing values before any other value.
+func missingLast(p FindParams) bool {
+	spec := sortSpec(p)
+	return p.TreatMissingAsLast && len(spec) > 1 && spec[0].Ascending
+}
+
+// missingLastSegment is a part of the documents in the sort order of TreatMissingAsLast: either
+// the documents holding the first sorted field, sorted on all the fields, or the ones missing it,
+// sorted on the remaining fields.
+type missingLastSegment struct {
+	filter        bson.M
+	fields        []string
+	comparisonOps []string
+	sort          bson.D
+}
+
+// executeMissingLastPageQuery executes the find query of the page like executePageQuery, when the
+// documents missing the first sorted field come after the other ones. The segment of the cursor
+// is queried from the cursor, along with the following segment when the page isn't full.
+func executeMissingLastPageQuery(ctx context.Context, p FindParams, opts *options.FindOptions, results interface{}) (int, error) {
+	token := p.Next
+	if token == "" {
+		token = p.Previous
+	}
+	// The cursor was parsed when building the page queries
+	cursorValues, cursorMetadata := p.parsed.values, p.parsed.metadata
+
+	// The documents are queried in the reverse order for the previous page
+	forward := p.Previous == ""
+	spec := sortSpec(p)
+	fields := make([]string, len(spec))
+	comparisonOps := make([]string, len(spec))
+	sort := make(bson.D, len(spec))
+	for i, field := range spec {
+		fields[i] = field.Name
+		comparisonOps[i], sort[i] = "$gt", bson.E{Key: field.Name, Value: 1}
+		if field.Ascending != forward {
+			comparisonOps[i], sort[i] = "$lt", bson.E{Key: field.Name, Value: -1}
+		}
+	}
+	if token != "" && includesBoundary(p, cursorMetadata) {
+		// Include the boundary document itself by including equality on the last field
+		comparisonOps[len(comparisonOps)-1] += "e"
+	}
+	present := missingLastSegment{bson.M{fields[0]: bson.M{"$ne": nil}}, fields, comparisonOps, sort}
+	missing := missingLastSegment{bson.M{fields[0]: nil}, fields[1:], comparisonOps[1:], sort[1:]}
+	segments := []missingLastSegment{present, missing}
+	if !forward {
+		segments = []missingLastSegment{missing, present}
+	}
+	if token != "" && (cursorValues[0] == nil) == forward {
+		// The cursor is in the second segment
+		segments = segments[1:]
+	}
+
+	resultsVal := reflect.ValueOf(results).Elem()
+	resultsVal.Set(reflect.MakeSlice(resultsVal.Type(), 0, 0))
+	for i, segment := range segments {
+		remaining := lookaheadLimit(p.Limit) - int64(resultsVal.Len())
+		if remaining <= 0 {
+			break
+		}
+		queries := append(baseQueries(p), segment.filter)
+		if i == 0 && token != "" {
+			values := cursorValues[len(cursorValues)-len(segment.fields):]
+			cursorQuery, err := generateCursorQuery(p, segment.fields, segment.comparisonOps, values)
+			if err != nil {
+				return 0, err
+			}
+			queries = append(queries, cursorQuery)
+		}
+		segmentOpts := *opts
+		segmentOpts.SetSort(segment.sort).SetLimit(remaining)
+		found := reflect.New(resultsVal.Type())
+		if err := executeCursorQuery(ctx, p.Collection, queries, &segmentOpts, found.Interface()); err != nil {
+			return 0, err
+		}
+		resultsVal.Set(reflect.AppendSlice(resultsVal, found.Elem()))
+	}
+	return int(p.Limit), nil
+}
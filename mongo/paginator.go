@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Paginator paginates a collection with the same FindParams on every call, so only the query, the
+// limit and the cursor change from one page query to the next.
+type Paginator struct {
+	p FindParams
+}
+
+// PaginatorOption sets a parameter of the FindParams of a Paginator.
+type PaginatorOption func(*FindParams)
+
+// WithPaginatedField sets the PaginatedField of the FindParams of a Paginator.
+func WithPaginatedField(field string) PaginatorOption {
+	return func(p *FindParams) { p.PaginatedField = field }
+}
+
+// WithTieBreakerFields sets the TieBreakerFields of the FindParams of a Paginator.
+func WithTieBreakerFields(fields ...string) PaginatorOption {
+	return func(p *FindParams) { p.TieBreakerFields = fields }
+}
+
+// WithSortAscending sets the SortAscending of the FindParams of a Paginator.
+func WithSortAscending(ascending bool) PaginatorOption {
+	return func(p *FindParams) { p.SortAscending = ascending }
+}
+
+// WithCollation sets the Collation of the FindParams of a Paginator.
+func WithCollation(collation *options.Collation) PaginatorOption {
+	return func(p *FindParams) { p.Collation = collation }
+}
+
+// WithCountTotal sets the CountTotal of the FindParams of a Paginator.
+func WithCountTotal(countTotal bool) PaginatorOption {
+	return func(p *FindParams) { p.CountTotal = countTotal }
+}
+
+// NewPaginator returns a Paginator over the collection of the database. The options set the other
+// parameters of its FindParams, a PaginatorOption being any function setting them.
+func NewPaginator(db *driver.Database, collection string, opts ...PaginatorOption) *Paginator {
+	return newPaginator(&driverCollection{col: db.Collection(collection)}, opts...)
+}
+
+func newPaginator(col Collection, opts ...PaginatorOption) *Paginator {
+	p := FindParams{Collection: col}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return &Paginator{p: p}
+}
+
+// Next fills the passed in result slice pointer with the page of the documents matching the query
+// that comes after the Next cursor of a previous page, or the first page if the cursor is empty,
+// and returns its Cursor.
+func (pg *Paginator) Next(ctx context.Context, query primitive.M, limit int64, cursor string, results interface{}) (Cursor, error) {
+	p := pg.p
+	p.Query, p.Limit, p.Next = query, limit, cursor
+	return Find(ctx, p, results)
+}
+
+// Previous fills the passed in result slice pointer with the page of the documents matching the
+// query that comes before the Previous cursor of a previous page, or the first page if the cursor
+// is empty, and returns its Cursor.
+func (pg *Paginator) Previous(ctx context.Context, query primitive.M, limit int64, cursor string, results interface{}) (Cursor, error) {
+	p := pg.p
+	p.Query, p.Limit, p.Previous = query, limit, cursor
+	return Find(ctx, p, results)
+}
+
+// driverCollection is the Collection of a *mongo.Collection, implementing CloneableCollection,
+// EstimatedCountCollection, AggregateCollection and IndexedCollection, so every option of the
+// FindParams of a Paginator is supported.
+type driverCollection struct {
+	col *driver.Collection
+}
+
+func (c *driverCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.col.CountDocuments(ctx, filter, opts...)
+}
+
+func (c *driverCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	cursor, err := c.col.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (c *driverCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	col, err := c.col.Clone(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &driverCollection{col: col}, nil
+}
+
+func (c *driverCollection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	return c.col.EstimatedDocumentCount(ctx, opts...)
+}
+
+func (c *driverCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (MongoCursor, error) {
+	cursor, err := c.col.Aggregate(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (c *driverCollection) ListIndexes(ctx context.Context) (MongoCursor, error) {
+	cursor, err := c.col.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
@@ -0,0 +1,99 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestPaginator(t *testing.T) {
+	items := newItems("a", "b", "C", "d", "e")
+	col := newFakeCollection(t)
+	collation := &options.Collation{Locale: "en", Strength: 2}
+	pg := newPaginator(col, WithPaginatedField("name"), WithSortAscending(true), WithCollation(collation), WithCountTotal(true))
+	ctx := context.Background()
+
+	col.queueCount(5, 5, 5, 5)
+	col.queuePage(t, items[0:3]...)
+	col.queuePage(t, items[2:5]...)
+	var results []item
+	cursor, err := pg.Next(ctx, primitive.M{}, 2, "", &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, 5, cursor.Count)
+	require.Equal(t, collation, col.findOptions[0].Collation)
+	require.Equal(t, collation, col.countOptions[0].Collation)
+
+	results = nil
+	cursor, err = pg.Next(ctx, primitive.M{}, 2, cursor.Next, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"C", "d"}, itemNames(results))
+	require.Equal(t, sentFilter(t, FindParams{Collection: col, Query: primitive.M{}, Limit: 2, PaginatedField: "name", SortAscending: true, Collation: collation, Next: mustGenerateCursor(t, items[1], []string{"name", "_id"})}), col.findFilters[1])
+
+	// The previous page is queried in the reverse order
+	col.queuePage(t, items[1], items[0])
+	results = nil
+	cursor, err = pg.Previous(ctx, primitive.M{}, 2, cursor.Previous, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.Equal(t, bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: -1}}, col.findOptions[2].Sort)
+
+	// The query and the limit are those of the call
+	results = nil
+	query := primitive.M{"name": primitive.M{"$ne": "a"}}
+	_, err = pg.Next(ctx, query, 1, "", &results)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$and": bson.A{query}}, col.findFilters[3])
+	require.Equal(t, bson.M{"$and": bson.A{query}}, col.countFilters[3])
+	require.Equal(t, int64(2), *col.findOptions[3].Limit)
+}
+
+func TestNewPaginator(t *testing.T) {
+	client, err := driver.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	pg := NewPaginator(client.Database("db"), "items", WithPaginatedField("name"))
+	require.Equal(t, "name", pg.p.PaginatedField)
+
+	// The collection can be cloned to query with a ReadPreference
+	col, ok := pg.p.Collection.(CloneableCollection)
+	require.True(t, ok)
+	clone, err := col.Clone(options.Collection().SetReadPreference(readpref.Secondary()))
+	require.NoError(t, err)
+	require.Equal(t, "items", clone.(*driverCollection).col.Name())
+}
+
+func TestNewPaginatorCollection(t *testing.T) {
+	client, err := driver.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	db := client.Database("db")
+	ctx := context.Background()
+
+	// The options requiring more than the queries of a Collection reach the driver, which fails
+	// since the client isn't connected, rather than being rejected
+	text := primitive.M{"$text": primitive.M{"$search": "go"}}
+	for _, tc := range []struct {
+		opt   PaginatorOption
+		query primitive.M
+	}{
+		{func(p *FindParams) { p.RequireIndex = true }, primitive.M{}},
+		{WithPaginatedField(TextScore), text},
+	} {
+		pg := NewPaginator(db, "articles", tc.opt)
+		var results []scoredArticle
+		_, err := pg.Next(ctx, tc.query, 1, "", &results)
+		require.Equal(t, driver.ErrClientDisconnected, err)
+	}
+
+	// The count of an empty query can be estimated
+	col, ok := NewPaginator(db, "articles").p.Collection.(EstimatedCountCollection)
+	require.True(t, ok)
+	_, err = col.EstimatedDocumentCount(ctx)
+	require.Equal(t, driver.ErrClientDisconnected, err)
+}
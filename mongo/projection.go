@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// findProjected is Find for FindParams with a Projection. The projection sent to mongo keeps the
+// sorted fields and the _id, from which the cursors are generated, and the ones the Projection
+// leaves out are removed from the results.
+func findProjected(ctx context.Context, p FindParams, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+
+	fields := sortFields(ensureDefaults(p))
+	if !contains(fields, "_id") {
+		fields = append(fields, "_id")
+	}
+	projection, err := projectKeepingFields(p.Projection, fields)
+	if err != nil {
+		return Cursor{}, err
+	}
+	hidden := hiddenFields(p.Projection, fields)
+	if len(hidden) == 0 {
+		return find(ctx, p, results, projection)
+	}
+
+	// Generate the cursors from the kept fields before removing them from the results
+	var raws []bson.Raw
+	cursor, err := find(ctx, p, &raws, projection)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if err := decodeWithout(raws, hidden, results); err != nil {
+		return Cursor{}, err
+	}
+	return cursor, nil
+}
+
+// hiddenFields returns the fields that the $project specification leaves out: the fields excluded
+// by an exclusion projection, or the fields other than the _id which aren't included by an
+// inclusion projection, along with the _id if it's excluded.
+func hiddenFields(spec bson.M, fields []string) []string {
+	inclusion := false
+	for field, value := range spec {
+		if field != "_id" && !isExclusion(value) {
+			inclusion = true
+		}
+	}
+	var hidden []string
+	for _, field := range fields {
+		value, ok := spec[field]
+		if (ok && isExclusion(value)) || (inclusion && !ok && field != "_id") {
+			hidden = append(hidden, field)
+		}
+	}
+	return hidden
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RangeBounds tells which of the boundary documents of a range between two cursors are part of
+// the range.
+type RangeBounds int
+
+const (
+	// ExcludeBounds excludes both boundary documents of the range
+	ExcludeBounds RangeBounds = iota
+	// IncludeStart includes the start boundary document of the range only
+	IncludeStart
+	// IncludeEnd includes the end boundary document of the range only
+	IncludeEnd
+	// IncludeBounds includes both boundary documents of the range
+	IncludeBounds
+)
+
+// ErrCursorsOutOfOrder is the error returned when the start cursor of a range comes after its end
+// cursor in the sort order
+var ErrCursorsOutOfOrder = errors.New("the start cursor must not come after the end cursor")
+
+// CountBetween counts the documents matching the query of the FindParams that lie between the
+// start and the end cursors in the sort order of the FindParams. An empty start or end cursor
+// leaves the range unbounded on that side. The bounds tell whether the boundary documents
+// themselves are counted.
+func CountBetween(ctx context.Context, p FindParams, startToken, endToken string, bounds RangeBounds) (int64, error) {
+	queries, err := rangeQueries(p, startToken, endToken, bounds)
+	if err != nil {
+		return 0, err
+	}
+	count, err := executeCountQuery(ctx, withReadOptions(p), countFilter(p, queries), countOptions(ensureDefaults(p)))
+	return int64(count), err
+}
+
+// FindWindowTokens executes a find mongo query by using the provided FindParams, fills the passed
+// in result slice pointer with the documents of the window from the start cursor, included, to the
+// end cursor, excluded, in the sort order of the FindParams, and returns a Cursor. The Previous and
+// Next cursors of the Cursor are generated from the first and last documents of the window, so
+// passing them to Find gets the documents just outside the window to extend it. An empty start or
+// end cursor leaves the window unbounded on that side. The Next and Previous cursors of the
+// FindParams are ignored, and its Limit, when set, caps the number of documents of the window.
+func FindWindowTokens(ctx context.Context, p FindParams, startToken, endToken string, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+
+	p.Next, p.Previous = "", ""
+	queries, err := rangeQueries(p, startToken, endToken, IncludeStart)
+	if err != nil {
+		return Cursor{}, err
+	}
+	p, err = withParsedCursor(ensureDefaults(p))
+	if err != nil {
+		return Cursor{}, err
+	}
+	_, sort, err := cursorQueryAndSort(p)
+	if err != nil {
+		return Cursor{}, err
+	}
+	opts := findOptions(p, sort, nil)
+	if p.Limit <= 0 {
+		opts.Limit = nil
+	}
+	if err := executeCursorQuery(ctx, p.Collection, queries, opts, results); err != nil {
+		return Cursor{}, err
+	}
+
+	resultsVal := reflect.ValueOf(results).Elem()
+	hasMore := p.Limit > 0 && int64(resultsVal.Len()) > p.Limit
+	if hasMore {
+		resultsVal.Set(resultsVal.Slice(0, int(p.Limit)))
+	}
+	cursor := Cursor{
+		HasPrevious: startToken != "",
+		HasNext:     endToken != "" || hasMore,
+	}
+	if p.Collation != nil {
+		collation := *p.Collation
+		cursor.Collation = &collation
+	}
+	if resultsVal.Len() == 0 {
+		return cursor, nil
+	}
+	fields := sortFields(p)
+	metadata := cursorMetadata(p)
+	if cursor.HasPrevious {
+		cursor.Previous, err = generatePageCursor(p, resultsVal.Index(0).Interface(), fields, metadata)
+		if err != nil {
+			return Cursor{}, fmt.Errorf("could not create a previous cursor: %s", err)
+		}
+	}
+	if cursor.HasNext {
+		cursor.Next, err = generatePageCursor(p, resultsVal.Index(resultsVal.Len()-1).Interface(), fields, metadata)
+		if err != nil {
+			return Cursor{}, fmt.Errorf("could not create a next cursor: %s", err)
+		}
+	}
+	return cursor, nil
+}
+
+// rangeQueries returns the queries selecting the documents between the start and end cursors.
+func rangeQueries(p FindParams, startToken, endToken string, bounds RangeBounds) ([]bson.M, error) {
+	p = ensureDefaults(p)
+	fields := sortFields(p)
+
+	if p.Collection == nil {
+		return nil, ErrNilCollection
+	}
+
+	startValues, err := parseCursor(p, startToken)
+	if err != nil {
+		return nil, &CursorError{fmt.Errorf("start cursor parse failed: %w", err)}
+	}
+	endValues, err := parseCursor(p, endToken)
+	if err != nil {
+		return nil, &CursorError{fmt.Errorf("end cursor parse failed: %w", err)}
+	}
+
+	spec := sortSpec(p)
+	afterOps, beforeOps := make([]string, len(spec)), make([]string, len(spec))
+	ascending := make([]bool, len(spec))
+	for i, field := range spec {
+		afterOps[i], beforeOps[i] = "$gt", "$lt"
+		if !field.Ascending {
+			afterOps[i], beforeOps[i] = beforeOps[i], afterOps[i]
+		}
+		ascending[i] = field.Ascending
+	}
+	if startToken != "" && endToken != "" {
+		cmp, comparable := compareCursorValues(startValues, endValues, ascending, p.Collation != nil)
+		if comparable && cmp > 0 {
+			return nil, &CursorError{ErrCursorsOutOfOrder}
+		}
+	}
+
+	queries := baseQueries(p)
+	if startToken != "" {
+		query, err := boundaryQuery(p, fields, afterOps, startValues, bounds == IncludeStart || bounds == IncludeBounds)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	if endToken != "" {
+		query, err := boundaryQuery(p, fields, beforeOps, endValues, bounds == IncludeEnd || bounds == IncludeBounds)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// boundaryQuery returns the query selecting the documents past the cursor values using the
+// comparison operator of each field, including the boundary document itself if inclusive is true.
+func boundaryQuery(p FindParams, fields []string, comparisonOps []string, cursorValues []interface{}, inclusive bool) (bson.M, error) {
+	comparisonOps = append([]string{}, comparisonOps...)
+	if inclusive {
+		comparisonOps[len(comparisonOps)-1] += "e"
+	}
+	return generateCursorQuery(p, fields, comparisonOps, cursorValues)
+}
+
+// compareCursorValues compares the values of two cursors field by field in the sort order, using
+// the BSON comparison order of the values and the direction of each field. It returns false if the
+// values can't be compared locally: when strings are compared under a collation or when the values
+// are of an unsupported type.
+func compareCursorValues(a, b []interface{}, ascending []bool, collated bool) (int, bool) {
+	for i := range a {
+		cmp, comparable := compareBSONValues(a[i], b[i])
+		if !comparable {
+			return 0, false
+		}
+		if cmp == 0 {
+			continue
+		}
+		if _, isString := a[i].(string); isString && collated {
+			return 0, false
+		}
+		if !ascending[i] {
+			cmp = -cmp
+		}
+		return cmp, true
+	}
+	return 0, true
+}
+
+// bsonTypeOrder returns the rank of the value's type in the BSON comparison order, or -1 if the
+// type isn't supported.
+func bsonTypeOrder(v interface{}) int {
+	switch v.(type) {
+	case primitive.MinKey:
+		return 0
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int32, int64, float64:
+		return 2
+	case string:
+		return 3
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.MaxKey:
+		return 13
+	}
+	return -1
+}
+
+func compareBSONValues(a, b interface{}) (int, bool) {
+	ta, tb := bsonTypeOrder(a), bsonTypeOrder(b)
+	if ta < 0 || tb < 0 {
+		return 0, false
+	}
+	if ta != tb {
+		return ta - tb, true
+	}
+	switch av := a.(type) {
+	case int32, int64, float64:
+		fa, fb := toFloat64(av), toFloat64(b)
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		return strings.Compare(av, b.(string)), true
+	case primitive.ObjectID:
+		bv := b.(primitive.ObjectID)
+		return bytes.Compare(av[:], bv[:]), true
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0, true
+		case !av:
+			return -1, true
+		}
+		return 1, true
+	case primitive.DateTime:
+		bv := b.(primitive.DateTime)
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case primitive.Timestamp:
+		bv := b.(primitive.Timestamp)
+		switch {
+		case av.T != bv.T:
+			if av.T < bv.T {
+				return -1, true
+			}
+			return 1, true
+		case av.I < bv.I:
+			return -1, true
+		case av.I > bv.I:
+			return 1, true
+		}
+		return 0, true
+	}
+	// Null, MinKey and MaxKey are equal to themselves
+	return 0, true
+}
+
+func toFloat64(v interface{}) float64 {
+	switch n := v.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCountBetween(t *testing.T) {
+	items := newItems("test item 1", "test item 2", "test item 3", "test item 4", "test item 5", "test item 6")
+	col := newFakeCollection(t)
+	fields := []string{"name", "_id"}
+	item2 := mustGenerateCursor(t, items[1], fields)
+	item5 := mustGenerateCursor(t, items[4], fields)
+	ascending := FindParams{Collection: col, Query: primitive.M{}, PaginatedField: "name", SortAscending: true}
+	descending := FindParams{Collection: col, Query: primitive.M{}, PaginatedField: "name", SortAscending: false}
+	// bound returns the query selecting the documents past the item using the comparison operators
+	bound := func(p FindParams, i int, ops ...string) bson.M {
+		values, err := parseCursor(ensureDefaults(p), mustGenerateCursor(t, items[i], fields))
+		require.NoError(t, err)
+		query, err := generateCursorQuery(ensureDefaults(p), fields, ops, values)
+		require.NoError(t, err)
+		return query
+	}
+
+	var cases = []struct {
+		name            string
+		findParams      FindParams
+		start           string
+		end             string
+		bounds          RangeBounds
+		expectedQueries []bson.M
+		expectedErr     error
+	}{
+		{"count documents between the cursors excluding the bounds", ascending, item2, item5, ExcludeBounds,
+			[]bson.M{bound(ascending, 1, "$gt", "$gt"), bound(ascending, 4, "$lt", "$lt")}, nil},
+		{"count documents between the cursors including the start", ascending, item2, item5, IncludeStart,
+			[]bson.M{bound(ascending, 1, "$gt", "$gte"), bound(ascending, 4, "$lt", "$lt")}, nil},
+		{"count documents between the cursors including the end", ascending, item2, item5, IncludeEnd,
+			[]bson.M{bound(ascending, 1, "$gt", "$gt"), bound(ascending, 4, "$lt", "$lte")}, nil},
+		{"count documents between the cursors including the bounds", ascending, item2, item5, IncludeBounds,
+			[]bson.M{bound(ascending, 1, "$gt", "$gte"), bound(ascending, 4, "$lt", "$lte")}, nil},
+		{"count documents from the beginning when no start cursor is specified", ascending, "", item5, ExcludeBounds,
+			[]bson.M{bound(ascending, 4, "$lt", "$lt")}, nil},
+		{"count documents to the end when no end cursor is specified", ascending, item2, "", IncludeStart,
+			[]bson.M{bound(ascending, 1, "$gt", "$gte")}, nil},
+		{"count documents between the cursors in descending order", descending, item5, item2, IncludeBounds,
+			[]bson.M{bound(descending, 4, "$lt", "$lte"), bound(descending, 1, "$gt", "$gte")}, nil},
+		{"errors when the cursors are out of order", ascending, item5, item2, ExcludeBounds, nil, &CursorError{ErrCursorsOutOfOrder}},
+		{"errors when the cursors are out of order in descending order", descending, item2, item5, ExcludeBounds, nil, &CursorError{ErrCursorsOutOfOrder}},
+		{"errors when the start cursor is bad", ascending, "XXXXXaGVsbG8=", item5, ExcludeBounds, nil, errors.New("start cursor parse failed: illegal base64 data at input byte 12")},
+		{"errors when the Collection is nil", FindParams{}, item2, item5, ExcludeBounds, nil, errors.New("Collection can't be nil")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			col.countFilters = nil
+			col.queueCount(3)
+			count, err := CountBetween(context.Background(), tc.findParams, tc.start, tc.end, tc.bounds)
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+				require.Empty(t, col.countFilters)
+				col.counts = nil
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(3), count)
+			queries := append([]bson.M{tc.findParams.Query}, tc.expectedQueries...)
+			require.Equal(t, mustToM(t, bson.M{"$and": queries}), col.countFilters[0])
+		})
+	}
+}
+
+func TestFindWindowTokens(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e", "f", "g")
+	col := newFakeCollection(t)
+	fields := []string{"name", "_id"}
+	p := FindParams{Collection: col, Query: primitive.M{}, PaginatedField: "name", SortAscending: true}
+
+	// Render the window [c, f), its cursors being those of its first and last documents
+	start, end := mustGenerateCursor(t, items[2], fields), mustGenerateCursor(t, items[5], fields)
+	col.queuePage(t, items[2:5]...)
+	var window []item
+	cursor, err := FindWindowTokens(context.Background(), p, start, end, &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d", "e"}, itemNames(window))
+	require.True(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, start, cursor.Previous)
+	require.Equal(t, mustGenerateCursor(t, items[4], fields), cursor.Next)
+	queries, err := rangeQueries(p, start, end, IncludeStart)
+	require.NoError(t, err)
+	require.Equal(t, mustToM(t, bson.M{"$and": queries}), col.findFilters[0])
+	require.Nil(t, col.findOptions[0].Limit)
+
+	// The unbounded sides of a window have no cursor
+	col.queuePage(t, items[:2]...)
+	cursor, err = FindWindowTokens(context.Background(), p, "", start, &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(window))
+	require.False(t, cursor.HasPrevious)
+	require.Empty(t, cursor.Previous)
+	require.True(t, cursor.HasNext)
+
+	// The limit caps the window
+	p.Limit = 3
+	col.queuePage(t, items[1:5]...)
+	cursor, err = FindWindowTokens(context.Background(), p, mustGenerateCursor(t, items[1], fields), "", &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "d"}, itemNames(window))
+	require.True(t, cursor.HasNext)
+	require.Equal(t, int64(4), *col.findOptions[2].Limit)
+	p.Limit = 0
+	col.queuePage(t, items[1:]...)
+	cursor, err = FindWindowTokens(context.Background(), p, mustGenerateCursor(t, items[1], fields), "", &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "d", "e", "f", "g"}, itemNames(window))
+	require.False(t, cursor.HasNext)
+
+	_, err = FindWindowTokens(context.Background(), p, end, start, &window)
+	require.EqualError(t, err, ErrCursorsOutOfOrder.Error())
+}
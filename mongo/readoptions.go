@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CloneableCollection is a Collection which can be cloned with other options, e.g. a wrapper of a
+// *mongo.Collection calling its Clone method. The Collection of FindParams with a ReadPreference
+// or a ReadConcern must implement it.
+type CloneableCollection interface {
+	Collection
+	Clone(opts ...*options.CollectionOptions) (Collection, error)
+}
+
+// ErrCollectionNotCloneable is the error returned when querying with a ReadPreference or a
+// ReadConcern a Collection which doesn't implement CloneableCollection
+var ErrCollectionNotCloneable = errors.New("a ReadPreference or a ReadConcern requires a CloneableCollection")
+
+// readOptionsCollection is a Collection executing the queries on a clone of its collection with
+// the read preference and read concern of the FindParams.
+type readOptionsCollection struct {
+	Collection
+	opts *options.CollectionOptions
+}
+
+// withReadOptions returns the Collection of the FindParams executing the queries with their
+// ReadPreference and ReadConcern, or the Collection as is if neither is set. A registryCollection
+// wraps the Collection with the read options already.
+func withReadOptions(p FindParams) Collection {
+	if p.Collection == nil || (p.ReadPreference == nil && p.ReadConcern == nil) {
+		return p.Collection
+	}
+	switch p.Collection.(type) {
+	case *readOptionsCollection, *registryCollection:
+		return p.Collection
+	}
+	return &readOptionsCollection{Collection: p.Collection, opts: collectionOptions(p)}
+}
+
+// collectionOptions returns the options of the clone of the collection the queries of the
+// FindParams are executed on.
+func collectionOptions(p FindParams) *options.CollectionOptions {
+	opts := options.Collection()
+	if p.ReadPreference != nil {
+		opts.SetReadPreference(p.ReadPreference)
+	}
+	if p.ReadConcern != nil {
+		opts.SetReadConcern(p.ReadConcern)
+	}
+	return opts
+}
+
+func (c *readOptionsCollection) clone() (Collection, error) {
+	cloneable, ok := c.Collection.(CloneableCollection)
+	if !ok {
+		return nil, ErrCollectionNotCloneable
+	}
+	return cloneable.Clone(c.opts)
+}
+
+func (c *readOptionsCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	col, err := c.clone()
+	if err != nil {
+		return 0, err
+	}
+	return col.CountDocuments(ctx, filter, opts...)
+}
+
+func (c *readOptionsCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	col, err := c.clone()
+	if err != nil {
+		return nil, err
+	}
+	return col.Find(ctx, filter, opts...)
+}
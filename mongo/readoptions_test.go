@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// cloneableCollection records the options of the clones the queries are executed on.
+type cloneableCollection struct {
+	*fakeCollection
+	clones []*options.CollectionOptions
+}
+
+func (c *cloneableCollection) Clone(opts ...*options.CollectionOptions) (Collection, error) {
+	c.clones = append(c.clones, options.MergeCollectionOptions(opts...))
+	return c.fakeCollection, nil
+}
+
+func TestFindReadOptions(t *testing.T) {
+	col := &cloneableCollection{fakeCollection: newFakeCollection(t, newItems("a", "b", "c")...)}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ReadPreference: readpref.SecondaryPreferred(),
+		ReadConcern:    readconcern.Majority(),
+	}
+	opts := collectionOptions(p)
+	require.Equal(t, readpref.SecondaryPreferred(), opts.ReadPreference)
+	require.Equal(t, readconcern.Majority(), opts.ReadConcern)
+
+	// The count and find queries are executed on a clone with the read options
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, []*options.CollectionOptions{opts, opts}, col.clones)
+
+	// The Collection is used as is without read options
+	col.clones = nil
+	p.ReadPreference, p.ReadConcern = nil, nil
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Empty(t, col.clones)
+
+	p.Collection = col.fakeCollection
+	p.ReadPreference = readpref.Secondary()
+	_, err = Find(context.Background(), p, &results)
+	require.Equal(t, ErrCollectionNotCloneable, err)
+}
@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// registryCollection is a Collection whose cursors decode the documents with a registry.
+type registryCollection struct {
+	Collection
+	registry *bsoncodec.Registry
+}
+
+// registryCursor is a MongoCursor decoding the documents with a registry.
+type registryCursor struct {
+	MongoCursor
+	registry *bsoncodec.Registry
+}
+
+// withRegistry returns the Collection of the FindParams decoding the documents with their
+// Registry, and executing the queries with their read options, or the Collection with the read
+// options if the Registry isn't set.
+func withRegistry(p FindParams) Collection {
+	if p.Collection == nil || p.Registry == nil {
+		return withReadOptions(p)
+	}
+	if _, ok := p.Collection.(*registryCollection); ok {
+		return p.Collection
+	}
+	return &registryCollection{Collection: withReadOptions(p), registry: p.Registry}
+}
+
+func (c *registryCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	cursor, err := c.Collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &registryCursor{MongoCursor: cursor, registry: c.registry}, nil
+}
+
+func (c *registryCursor) Decode(v interface{}) error {
+	var raw bson.Raw
+	if err := c.MongoCursor.Decode(&raw); err != nil {
+		return err
+	}
+	return bson.UnmarshalWithRegistry(c.registry, raw, v)
+}
+
+func (c *registryCursor) All(ctx context.Context, results interface{}) error {
+	defer c.Close(ctx)
+	sliceVal := reflect.ValueOf(results).Elem()
+	elemType := sliceVal.Type().Elem()
+	sliceVal = sliceVal.Slice(0, 0)
+	for c.Next(ctx) {
+		elem := reflect.New(elemType)
+		if err := c.Decode(elem.Interface()); err != nil {
+			return err
+		}
+		sliceVal = reflect.Append(sliceVal, elem.Elem())
+	}
+	if err := c.Err(); err != nil {
+		return err
+	}
+	reflect.ValueOf(results).Elem().Set(sliceVal)
+	return nil
+}
+
+// unmarshalResult decodes the raw document into the result with the Registry of the FindParams,
+// or the default registry if it isn't set.
+func unmarshalResult(p FindParams, raw bson.Raw, result interface{}) error {
+	if p.Registry != nil {
+		return bson.UnmarshalWithRegistry(p.Registry, raw, result)
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+// registryResult returns the result encoded with the Registry of the FindParams, so the values its
+// cursors hold are the ones the codecs of the registry encode, or the result as is if the Registry
+// isn't set.
+func registryResult(p FindParams, result interface{}) (interface{}, error) {
+	if p.Registry == nil || result == nil {
+		return result, nil
+	}
+	switch result.(type) {
+	case []byte, bson.Raw, bson.D:
+		return result, nil
+	}
+	data, err := bson.MarshalWithRegistry(p.Registry, result)
+	if err != nil {
+		return nil, err
+	}
+	return bson.Raw(data), nil
+}
+
+// resultData returns the values of the specified fields of the result like boundaryData, encoding
+// the result with the Registry of the FindParams.
+func resultData(p FindParams, result interface{}, fields []string) (bson.D, error) {
+	result, err := registryResult(p, result)
+	if err != nil {
+		return nil, err
+	}
+	return boundaryData(result, fields)
+}
@@ -0,0 +1,84 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// money is an amount the default registry can't encode, its cents being unexported.
+type money struct {
+	cents int64
+}
+
+type product struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  string             `bson:"name"`
+	Price money              `bson:"price"`
+}
+
+// newMoneyRegistry returns a registry encoding money as its int64 cents.
+func newMoneyRegistry() *bsoncodec.Registry {
+	moneyType := reflect.TypeOf(money{})
+	return bson.NewRegistryBuilder().
+		RegisterTypeEncoder(moneyType, bsoncodec.ValueEncoderFunc(func(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+			return vw.WriteInt64(val.Interface().(money).cents)
+		})).
+		RegisterTypeDecoder(moneyType, bsoncodec.ValueDecoderFunc(func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+			cents, err := vr.ReadInt64()
+			if err != nil {
+				return err
+			}
+			val.Set(reflect.ValueOf(money{cents: cents}))
+			return nil
+		})).
+		Build()
+}
+
+func TestFindRegistry(t *testing.T) {
+	col := newFakeCollection(t)
+	for _, doc := range []struct {
+		name  string
+		cents int64
+	}{{"b", 100}, {"d", 200}, {"a", 300}} {
+		col.insert(t, bson.M{"_id": primitive.NewObjectID(), "name": doc.name, "price": doc.cents})
+	}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "price",
+	}
+
+	// The default registry can't decode the prices. The driver caches the codecs of the fields of
+	// a struct type across registries, so it's checked with another type
+	var plain []struct {
+		Price money `bson:"price"`
+	}
+	_, err := Find(context.Background(), p, &plain)
+	require.Error(t, err)
+
+	// The prices are decoded with the registry, and the cursors hold the cents they're encoded to
+	p.Registry = newMoneyRegistry()
+	var results []product
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []money{{cents: 100}, {cents: 200}}, []money{results[0].Price, results[1].Price})
+	values, err := DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), values["price"])
+
+	// The values of the results are encoded with the registry
+	data, err := resultData(p, struct {
+		Cost money `bson:"cost"`
+	}{money{cents: 700}}, []string{"cost"})
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: "cost", Value: int64(700)}}, data)
+}
@@ -0,0 +1,97 @@
+package mongo
+
+type (
+	// CursorSchemaInfo describes the contents of the cursors generated for a FindParams
+	// configuration, e.g. to document the opaque cursor strings in an API schema.
+	CursorSchemaInfo struct {
+		// How the cursor string is encoded, which depends on the CanonicalCursor, CursorFormat and
+		// CursorSecret of the FindParams
+		Encoding string
+		// The fields whose values the cursor holds, in order
+		Fields []CursorFieldSchema
+		// The keys of the metadata the cursor holds after the values of the fields, in order, e.g.
+		// "$exp" for the expiry time of a CursorTTL. Metadata keys start with $ so they can't clash
+		// with field names
+		MetadataKeys []string
+	}
+
+	// CursorFieldSchema describes a field held by a cursor.
+	CursorFieldSchema struct {
+		// The name of the field. The cursor holds the value of the field of the boundary document
+		// as is, so the value has the BSON type of the field of the document
+		Name string
+		// true if the results are sorted ascending on the field when querying the next page,
+		// false otherwise
+		Ascending bool
+	}
+)
+
+// The encodings of the cursor strings
+const (
+	// CursorEncoding is the encoding of the cursors in the CursorFormatBSON format, the default
+	CursorEncoding = "base64url (unpadded) BSON document"
+	// CursorEncodingJSON is the encoding of the cursors in the CursorFormatJSON format
+	CursorEncodingJSON = "base64url (unpadded) 'j' byte followed by a canonical Extended JSON object"
+	// CursorEncodingCanonical is the encoding of the cursors generated with CanonicalCursor
+	CursorEncodingCanonical = "base64url (unpadded) version byte followed by the canonical encoding of the values"
+	// cursorSignatureEncoding is appended to the encoding of the cursors signed with a CursorSecret
+	cursorSignatureEncoding = ", followed by its HMAC-SHA256"
+)
+
+// CursorSchema returns the structure of the cursors Find generates for the provided FindParams,
+// without generating a cursor.
+func CursorSchema(p FindParams) CursorSchemaInfo {
+	p = cursorParams(p)
+	spec := sortSpec(p)
+	schema := CursorSchemaInfo{
+		Encoding:     cursorEncoding(p),
+		Fields:       make([]CursorFieldSchema, 0, len(spec)),
+		MetadataKeys: cursorMetadataKeys(p),
+	}
+	for _, field := range spec {
+		schema.Fields = append(schema.Fields, CursorFieldSchema{
+			Name:      field.Name,
+			Ascending: field.Ascending,
+		})
+	}
+	return schema
+}
+
+// cursorEncoding returns the encoding of the cursors generated for the FindParams, in the order of
+// precedence of generatePageCursor.
+func cursorEncoding(p FindParams) string {
+	encoding := CursorEncoding
+	switch {
+	case p.CanonicalCursor:
+		encoding = CursorEncodingCanonical
+	case p.CursorFormat == CursorFormatJSON:
+		encoding = CursorEncodingJSON
+	}
+	if len(p.CursorSecret) > 0 {
+		encoding += cursorSignatureEncoding
+	}
+	return encoding
+}
+
+// cursorMetadataKeys returns the keys of the metadata cursorMetadata adds to the cursors generated
+// for the FindParams, in order.
+func cursorMetadataKeys(p FindParams) []string {
+	var keys []string
+	if p.CursorTTL > 0 {
+		keys = append(keys, cursorExpiryKey)
+	}
+	if p.IncludeFirstBoundary {
+		keys = append(keys, cursorGeneratedKey)
+	}
+	if p.BindCursorsToFilter {
+		keys = append(keys, cursorFilterHashKey)
+	}
+	if p.CausalCursors {
+		// Held by the cursors generated in a causally consistent session only
+		keys = append(keys, cursorOperationTimeKey)
+	}
+	if p.MaxPageDepth > 0 {
+		keys = append(keys, cursorDepthKey)
+	}
+	return keys
+}
@@ -0,0 +1,112 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorSchema(t *testing.T) {
+	doc := item{ID: primitive.NewObjectID(), Name: "test item", Group: "a", Region: "emea", Seq: 3}
+	var cases = []struct {
+		name           string
+		findParams     FindParams
+		expectedSchema CursorSchemaInfo
+	}{
+		{
+			"return the _id field when no paginated field is specified",
+			FindParams{SortAscending: true},
+			CursorSchemaInfo{
+				Encoding: CursorEncoding,
+				Fields:   []CursorFieldSchema{{Name: "_id", Ascending: true}},
+			},
+		},
+		{
+			"return the paginated field and _id",
+			FindParams{PaginatedField: "name"},
+			CursorSchemaInfo{
+				Encoding: CursorEncoding,
+				Fields: []CursorFieldSchema{
+					{Name: "name", Ascending: false},
+					{Name: "_id", Ascending: false},
+				},
+			},
+		},
+		{
+			"return the paginated field and the tie-breaker fields",
+			FindParams{PaginatedField: "group", TieBreakerFields: []string{"region", "seq"}, SortAscending: true},
+			CursorSchemaInfo{
+				Encoding: CursorEncoding,
+				Fields: []CursorFieldSchema{
+					{Name: "group", Ascending: true},
+					{Name: "region", Ascending: true},
+					{Name: "seq", Ascending: true},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := CursorSchema(tc.findParams)
+			require.Equal(t, tc.expectedSchema, schema)
+
+			// The schema matches the cursor generated for the same params
+			cursor := mustGenerateCursor(t, doc, sortFields(ensureDefaults(tc.findParams)))
+			cursorData, err := decodeCursor(cursor)
+			require.NoError(t, err)
+			require.Len(t, cursorData, len(schema.Fields))
+			for i, e := range cursorData {
+				require.Equal(t, schema.Fields[i].Name, e.Key)
+			}
+		})
+	}
+}
+
+func TestCursorSchemaEncoding(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	var cases = []struct {
+		name             string
+		findParams       FindParams
+		expectedEncoding string
+		expectedKeys     []string
+	}{
+		{"the default encoding", FindParams{}, CursorEncoding, nil},
+		{"the JSON format", FindParams{CursorFormat: CursorFormatJSON}, CursorEncodingJSON, nil},
+		{"the canonical encoding", FindParams{CanonicalCursor: true, CursorFormat: CursorFormatJSON}, CursorEncodingCanonical, nil},
+		{"signed cursors", FindParams{CursorSecret: []byte("secret")}, CursorEncoding + cursorSignatureEncoding, nil},
+		{
+			"the metadata",
+			FindParams{CursorTTL: time.Hour, IncludeFirstBoundary: true, BindCursorsToFilter: true, MaxPageDepth: 10},
+			CursorEncoding,
+			[]string{cursorExpiryKey, cursorGeneratedKey, cursorFilterHashKey, cursorDepthKey},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.findParams
+			p.Collection, p.Query, p.Limit, p.PaginatedField = col, primitive.M{}, 1, "name"
+			schema := CursorSchema(p)
+			require.Equal(t, tc.expectedEncoding, schema.Encoding)
+			require.Equal(t, tc.expectedKeys, schema.MetadataKeys)
+
+			// The metadata keys are the ones of the cursors generated by Find
+			var results []item
+			cursor, err := Find(context.Background(), p, &results)
+			require.NoError(t, err)
+			_, metadata, err := parseCursorData(ensureDefaults(p), cursor.Next)
+			require.NoError(t, err)
+			var keys []string
+			for _, e := range metadata {
+				keys = append(keys, e.Key)
+			}
+			require.Equal(t, tc.expectedKeys, keys)
+		})
+	}
+
+	// The cursors of the TextScore hold the score
+	schema := CursorSchema(FindParams{PaginatedField: TextScore})
+	require.Equal(t, []CursorFieldSchema{{Name: TextScoreField}, {Name: "_id"}}, schema.Fields)
+}
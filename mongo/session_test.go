@@ -0,0 +1,136 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeSession is a mongo.Session only identifying a session, calling its methods panics.
+type fakeSession struct {
+	driver.Session
+}
+
+// sessionCollection records the session of the context of every count and find query.
+type sessionCollection struct {
+	*fakeCollection
+	sessions []driver.Session
+}
+
+func (c *sessionCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	c.sessions = append(c.sessions, driver.SessionFromContext(ctx))
+	return c.fakeCollection.CountDocuments(ctx, filter, opts...)
+}
+
+func (c *sessionCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	c.sessions = append(c.sessions, driver.SessionFromContext(ctx))
+	return c.fakeCollection.Find(ctx, filter, opts...)
+}
+
+func TestFindInSession(t *testing.T) {
+	col := &sessionCollection{fakeCollection: newFakeCollection(t, newItems("a", "b", "c")...)}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	session := &fakeSession{}
+	var results []item
+	cursor, err := Find(driver.NewSessionContext(context.Background(), session), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+	require.Equal(t, []driver.Session{session, session}, col.sessions)
+
+	// The cursor generated in the session is valid outside of it
+	col.sessions = nil
+	p.Next = cursor.Next
+	col.queuePage(t, col.docs[2])
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, itemNames(results))
+	require.Equal(t, []driver.Session{nil, nil}, col.sessions)
+}
+
+// causalSession is a fakeSession holding an operation time.
+type causalSession struct {
+	fakeSession
+	operationTime *primitive.Timestamp
+}
+
+func (s *causalSession) OperationTime() *primitive.Timestamp {
+	return s.operationTime
+}
+
+func (s *causalSession) AdvanceOperationTime(operationTime *primitive.Timestamp) error {
+	if s.operationTime == nil || primitive.CompareTimestamp(*operationTime, *s.operationTime) > 0 {
+		s.operationTime = operationTime
+	}
+	return nil
+}
+
+// operationTimeCollection records the operation time of the session of the context of every find
+// query.
+type operationTimeCollection struct {
+	*fakeCollection
+	operationTimes []*primitive.Timestamp
+}
+
+func (c *operationTimeCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (MongoCursor, error) {
+	c.operationTimes = append(c.operationTimes, driver.SessionFromContext(ctx).OperationTime())
+	return c.fakeCollection.Find(ctx, filter, opts...)
+}
+
+func TestFindCausalCursors(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := &operationTimeCollection{fakeCollection: newFakeCollection(t, items...)}
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CausalCursors:  true,
+	}
+
+	// The session operation time after a write is held by the cursors
+	written := &primitive.Timestamp{T: 100, I: 1}
+	var results []item
+	cursor, err := Find(driver.NewSessionContext(context.Background(), &causalSession{operationTime: written}), p, &results)
+	require.NoError(t, err)
+	_, metadata, err := parseCursorData(ensureDefaults(p), cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, bson.D{{Key: cursorOperationTimeKey, Value: *written}}, metadata)
+
+	// The next page is queried at or after it in another session
+	session := &causalSession{}
+	p.Next = cursor.Next
+	col.queuePage(t, items[2:]...)
+	_, err = Find(driver.NewSessionContext(context.Background(), session), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, []*primitive.Timestamp{written, written}, col.operationTimes)
+
+	// A later operation time of the session is kept
+	later := &primitive.Timestamp{T: 200, I: 1}
+	session.operationTime = later
+	_, err = Find(driver.NewSessionContext(context.Background(), session), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, later, col.operationTimes[2])
+
+	// The cursors are valid without a session
+	col.queuePage(t, items[2:]...)
+	cursor, err = Find(context.Background(), FindParams{Collection: col.fakeCollection, Query: primitive.M{}, Limit: 2, SortAscending: true, PaginatedField: "name", CausalCursors: true, Next: p.Next}, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	_, metadata, err = parseCursorData(ensureDefaults(p), cursor.Next)
+	require.NoError(t, err)
+	require.Empty(t, metadata)
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrCursorTampered is the error returned when the signature of a cursor doesn't match its
+// content, i.e. when it wasn't generated with the CursorSecret of the FindParams
+var ErrCursorTampered = errors.New("cursor signature mismatch")
+
+// signCursor returns the cursor with the HMAC-SHA256 of its data appended to the data, so the
+// signed cursor is a single url safe string.
+func signCursor(cursor string, secret []byte) (string, error) {
+	data, err := decodeCursorBytes(cursor)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(data)), nil
+}
+
+// verifyCursor verifies the signature of a cursor signed with signCursor and returns the cursor
+// without its signature, or ErrCursorTampered if the signature doesn't match.
+func verifyCursor(cursor string, secret []byte) (string, error) {
+	data, err := decodeCursorBytes(cursor)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < sha256.Size {
+		return "", ErrCursorTampered
+	}
+	data, signature := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", ErrCursorTampered
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindCursorSecret(t *testing.T) {
+	items := newItems("a", "b", "c")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CursorSecret:   []byte("s3cr3t"),
+	}
+	var results []item
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+
+	// The signed cursors are accepted
+	p.Next = cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next, p.Previous = "", cursor.Next
+	_, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Previous = ""
+
+	// The signature is part of the cursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor.Next)
+	require.NoError(t, err)
+	unsigned := mustGenerateCursor(t, items[1], []string{"name", "_id"})
+	unsignedData, err := base64.RawURLEncoding.DecodeString(unsigned)
+	require.NoError(t, err)
+	require.Len(t, data, len(unsignedData)+32)
+	require.Equal(t, unsignedData, data[:len(unsignedData)])
+
+	expectTampered := func(cursor string) {
+		t.Helper()
+		p.Next = cursor
+		_, err := Find(context.Background(), p, &results)
+		var cursorErr *CursorError
+		require.True(t, errors.As(err, &cursorErr))
+		require.True(t, errors.Is(err, ErrCursorTampered))
+	}
+
+	// Unsigned, forged and truncated cursors are rejected
+	expectTampered(unsigned)
+	forged := mustGenerateCursor(t, items[2], []string{"name", "_id"})
+	forgedData, err := base64.RawURLEncoding.DecodeString(forged)
+	require.NoError(t, err)
+	expectTampered(base64.RawURLEncoding.EncodeToString(append(forgedData, data[len(unsignedData):]...)))
+	expectTampered(base64.RawURLEncoding.EncodeToString(data[:10]))
+
+	// A cursor signed with another secret is rejected
+	p.CursorSecret = []byte("other")
+	expectTampered(cursor.Next)
+}
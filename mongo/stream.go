@@ -0,0 +1,150 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNilEach is the error returned when the callback passed to FindStream is nil
+var ErrNilEach = errors.New("each can't be nil")
+
+// FindStream executes a find mongo query by using the provided FindParams like Find, calling each
+// with the documents of the page in order as they're read from the mongo cursor instead of filling
+// a slice, so the memory used doesn't grow with the size of the page, and returns a Cursor. The raw
+// document passed to each is only valid until each returns. If each returns an error, the
+// iteration stops and FindStream returns that error.
+//
+// The page of a Previous cursor is queried in the reverse sort order, so FindStream first queries
+// the sorted fields of its documents to find where the page starts, and then streams the page in
+// the sort order. The options which need the documents of the whole page, or decode them, can't be
+// used: FindStream returns an error matching ErrIncompatibleOptions when the FindParams set one of
+// them, e.g. a Projection.
+func FindStream(ctx context.Context, p FindParams, each func(raw bson.Raw) error) (Cursor, error) {
+	if each == nil {
+		return Cursor{}, ErrNilEach
+	}
+	if err := validateStreamOptions(p); err != nil {
+		return Cursor{}, err
+	}
+
+	var projection bson.M
+	if p.Previous != "" {
+		projection = bson.M{}
+		for _, field := range sortFields(ensureDefaults(p)) {
+			projection[field] = 1
+		}
+	}
+	p, queries, opts, count, err := pageQueries(ctx, p, projection)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	stream := streamCursorQuery
+	if p.Previous != "" {
+		stream = streamPreviousPage
+	}
+	first, last, streamed, hasMore, err := stream(ctx, p, queries, opts, each)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if p.CausalCursors {
+		p = withOperationTime(ctx, p)
+	}
+
+	cursor, err := boundaryCursor(p, streamed, first, last, hasMore)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return completeCursor(ctx, p, cursor, &[]bson.Raw{}, count)
+}
+
+// validateStreamOptions returns an error matching ErrIncompatibleOptions if the FindParams set an
+// option which can't be used along with FindStream.
+func validateStreamOptions(p FindParams) error {
+	return incompatibleOptions("FindStream",
+		findOption{"the TextScore PaginatedField", p.PaginatedField == TextScore},
+		findOption{"Projection", p.Projection != nil},
+		findOption{"DiscriminatorField", p.DiscriminatorField != ""},
+		findOption{"TreatMissingAsLast", missingLast(ensureDefaults(p))},
+		findOption{"ArrayPaginatedField with a Previous cursor", arrayPaginated(p) && p.Previous != ""},
+		findOption{"MaxBytes", p.MaxBytes > 0},
+		findOption{"BidirectionalProbe", p.BidirectionalProbe},
+		findOption{"ComputeRanks", p.ComputeRanks},
+		findOption{"VersionField", p.VersionField != ""},
+	)
+}
+
+// streamPreviousPage streams the page of the Previous cursor of the FindParams in the sort order.
+// The queries and options select the sorted fields of the documents of the page in the reverse
+// sort order, from which the first document of the page is found, and the page is then streamed
+// from that document, included, to the Previous cursor, excluded. It returns the first and last
+// documents streamed and their number, along with whether there are documents before the page.
+func streamPreviousPage(ctx context.Context, p FindParams, queries []bson.M, opts *options.FindOptions, each func(raw bson.Raw) error) (bson.Raw, bson.Raw, int, bool, error) {
+	var keys []bson.Raw
+	if err := executeCursorQuery(ctx, p.Collection, queries, opts, &keys); err != nil {
+		return nil, nil, 0, false, err
+	}
+	hasMore := len(keys) > int(p.Limit)
+	if hasMore {
+		keys = keys[:p.Limit]
+	}
+	if len(keys) == 0 {
+		return nil, nil, 0, false, nil
+	}
+	start, err := generatePageCursor(p, keys[len(keys)-1], sortFields(p), cursorMetadata(p))
+	if err != nil {
+		return nil, nil, 0, false, fmt.Errorf("could not create the start cursor of the page: %s", err)
+	}
+
+	forward := p
+	forward.Previous = ""
+	pageRange, err := rangeQueries(forward, start, p.Previous, IncludeStart)
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	_, sort, err := cursorQueryAndSort(forward)
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+	first, last, streamed, _, err := streamCursorQuery(ctx, forward, pageRange, findOptions(forward, sort, nil), each)
+	return first, last, streamed, hasMore, err
+}
+
+// streamCursorQuery executes the find query, calling each with up to Limit documents, and returns
+// copies of the first and last documents streamed and their number, along with whether the
+// query returned another document past the Limit.
+func streamCursorQuery(ctx context.Context, p FindParams, queries []bson.M, opts *options.FindOptions, each func(raw bson.Raw) error) (bson.Raw, bson.Raw, int, bool, error) {
+	cursor, err := p.Collection.Find(ctx, bson.M{"$and": queries}, opts)
+	if err != nil {
+		return nil, nil, 0, false, maxTimeError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var first, last bson.Raw
+	streamed := 0
+	for cursor.Next(ctx) {
+		if streamed == int(p.Limit) {
+			return first, last, streamed, true, nil
+		}
+		var raw bson.Raw
+		if err := cursor.Decode(&raw); err != nil {
+			return nil, nil, 0, false, err
+		}
+		if streamed == 0 {
+			first = append(bson.Raw{}, raw...)
+		}
+		if err := each(raw); err != nil {
+			return nil, nil, 0, false, err
+		}
+		last = append(last[:0], raw...)
+		streamed++
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, nil, 0, false, maxTimeError(err)
+	}
+	return first, last, streamed, false, nil
+}
@@ -0,0 +1,124 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindStream(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	stream := func(p FindParams) ([]string, Cursor) {
+		var names []string
+		cursor, err := FindStream(context.Background(), p, func(raw bson.Raw) error {
+			names = append(names, raw.Lookup("name").StringValue())
+			return nil
+		})
+		require.NoError(t, err)
+		return names, cursor
+	}
+	find := func(p FindParams) Cursor {
+		var results []item
+		cursor, err := Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		return cursor
+	}
+
+	// The pages are streamed as returned by mongo, with the cursors Find returns
+	queueTwice := func(docs ...interface{}) {
+		col.queuePage(t, docs...)
+		col.queuePage(t, docs...)
+	}
+	queueTwice(items[0:3]...)
+	queueTwice(items[2:5]...)
+	queueTwice(items[4])
+	var pages [][]string
+	for {
+		names, cursor := stream(p)
+		require.Equal(t, find(p), cursor)
+		require.Equal(t, int64(len(names)), cursor.Returned)
+		pages = append(pages, names)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+
+	// and streaming back from the last page gets the previous pages in the sort order too, the
+	// sorted fields of a page being queried in the reverse order before the page is streamed
+	col.queuePage(t, items[4])
+	_, cursor := stream(p)
+	for _, page := range [][]interface{}{{items[3], items[2], items[1]}, {items[1], items[0]}} {
+		col.queuePage(t, page...)
+		col.queuePage(t, page[1], page[0])
+		col.queuePage(t, page...)
+	}
+	p.Next, p.Previous = "", cursor.Previous
+	pages = nil
+	for {
+		var names []string
+		names, cursor = stream(p)
+		require.Equal(t, find(p), cursor)
+		require.Equal(t, int64(len(names)), cursor.Returned)
+		pages = append(pages, names)
+		if !cursor.HasPrevious {
+			break
+		}
+		p.Previous = cursor.Previous
+	}
+	require.Equal(t, [][]string{{"c", "d"}, {"a", "b"}}, pages)
+	require.Equal(t, bson.M{"name": 1, "_id": 1}, col.findOptions[7].Projection)
+	require.Nil(t, col.findOptions[8].Projection)
+	require.Equal(t, 5, cursor.Count)
+}
+
+func TestFindStreamErrors(t *testing.T) {
+	col := newFakeCollection(t, newItems("a", "b", "c")...)
+	p := FindParams{Collection: col, Query: primitive.M{}, Limit: 3}
+
+	// The iteration stops at the error of each
+	eachErr := errors.New("each error")
+	calls := 0
+	_, err := FindStream(context.Background(), p, func(bson.Raw) error {
+		calls++
+		if calls == 2 {
+			return eachErr
+		}
+		return nil
+	})
+	require.Equal(t, eachErr, err)
+	require.Equal(t, 2, calls)
+
+	_, err = FindStream(context.Background(), p, nil)
+	require.Equal(t, ErrNilEach, err)
+
+	// The options which can't be honoured while streaming are rejected
+	for _, set := range []func(p *FindParams){
+		func(p *FindParams) { p.Projection = bson.M{"name": 1} },
+		func(p *FindParams) { p.DiscriminatorField = "type" },
+		func(p *FindParams) { p.PaginatedField, p.SortAscending, p.TreatMissingAsLast = "name", true, true },
+		func(p *FindParams) { p.MaxBytes = 100 },
+		func(p *FindParams) { p.BidirectionalProbe = true },
+		func(p *FindParams) { p.ComputeRanks = true },
+		func(p *FindParams) { p.VersionField = "version" },
+	} {
+		pp := p
+		set(&pp)
+		_, err = FindStream(context.Background(), pp, func(bson.Raw) error { return nil })
+		require.True(t, errors.Is(err, ErrIncompatibleOptions), err)
+	}
+}
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// TextScore is the PaginatedField paginating the documents of a $text query on their text
+	// score, in descending order whatever the SortAscending, documents sharing a score being
+	// ordered by the TieBreakerFields. The score is returned in the TextScoreField of the results
+	TextScore = "$textScore"
+	// TextScoreField is the field of the results receiving the text score of the documents when
+	// paginating on the TextScore, e.g. Score float64 `bson:"textScore"`
+	TextScoreField = "textScore"
+)
+
+// ErrCollectionNotAggregatable is the error returned when paginating on the TextScore a
+// Collection which doesn't implement AggregateCollection
+var ErrCollectionNotAggregatable = errors.New("TextScore requires an AggregateCollection")
+
+// textScoreMeta is the expression of the text score of a document.
+var textScoreMeta = bson.M{"$meta": "textScore"}
+
+// findTextScore is Find when the PaginatedField is TextScore. The text score only exists in the
+// projection and the sort of a find query, so the documents can't be queried from the score a
+// cursor holds with a find query: the page is queried with an aggregation instead, which adds the
+// score to the documents matching the query, so the cursor query compares it like any other field.
+// The Collection must implement AggregateCollection.
+func findTextScore(ctx context.Context, p FindParams, results interface{}) (Cursor, error) {
+	if results == nil {
+		return Cursor{}, ErrNilResults
+	}
+	if err := validateResults(results); err != nil {
+		return Cursor{}, err
+	}
+	if p.Collection == nil {
+		return Cursor{}, ErrNilCollection
+	}
+	if p.Limit <= 0 {
+		return Cursor{}, ErrLimitTooSmall
+	}
+	if err := validateTextScoreOptions(p); err != nil {
+		return Cursor{}, err
+	}
+	if err := validatePaginatedField(TextScoreField, results); err != nil {
+		return Cursor{}, err
+	}
+	p = textScoreParams(p)
+	col, err := aggregateCollection(p.Collection)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if col == nil {
+		return Cursor{}, ErrCollectionNotAggregatable
+	}
+	if p.CausalCursors {
+		if err := advanceOperationTime(ctx, p); err != nil {
+			return Cursor{}, err
+		}
+	}
+
+	var count int
+	if p.CountTotal {
+		count, p.estimatedCount, err = totalCount(ctx, p)
+		if err != nil {
+			return Cursor{}, err
+		}
+	}
+
+	// The $text query must be the first stage, the score being added to the documents it matches
+	p, err = withParsedCursor(p)
+	if err != nil {
+		return Cursor{}, err
+	}
+	cursorQuery, sort, err := cursorQueryAndSort(p)
+	if err != nil {
+		return Cursor{}, err
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{"$and": baseQueries(p)}},
+		{"$addFields": bson.M{TextScoreField: textScoreMeta}},
+	}
+	if cursorQuery != nil {
+		pipeline = append(pipeline, bson.M{"$match": cursorQuery})
+	}
+	pipeline = append(pipeline, bson.M{"$sort": sort}, bson.M{"$limit": lookaheadLimit(p.Limit)})
+
+	cursor, err := col.Aggregate(ctx, pipeline, textScoreAggregateOptions(p))
+	if err != nil {
+		return Cursor{}, maxTimeError(err)
+	}
+	if p.Registry != nil {
+		cursor = &registryCursor{MongoCursor: cursor, registry: p.Registry}
+	}
+	if err := cursor.All(ctx, results); err != nil {
+		return Cursor{}, maxTimeError(err)
+	}
+	if p.CausalCursors {
+		p = withOperationTime(ctx, p)
+	}
+
+	paged, err := pageCursor(p, results, int(p.Limit))
+	if err != nil {
+		return Cursor{}, err
+	}
+	return completeCursor(ctx, p, paged, results, count)
+}
+
+// validateTextScoreOptions returns an error matching ErrIncompatibleOptions if the FindParams set
+// an option which can't be used along with the TextScore, whose page is queried with an
+// aggregation rather than with a find query.
+func validateTextScoreOptions(p FindParams) error {
+	return incompatibleOptions("the TextScore PaginatedField",
+		findOption{"PaginatedFields", len(p.PaginatedFields) > 0},
+		findOption{"Projection", p.Projection != nil},
+		findOption{"DiscriminatorField", p.DiscriminatorField != ""},
+		findOption{"TreatMissingAsLast", p.TreatMissingAsLast},
+		findOption{"ArrayPaginatedField", p.ArrayPaginatedField},
+		findOption{"NaturalOrder", p.NaturalOrder},
+		findOption{"ComputeRanks", p.ComputeRanks},
+		findOption{"BidirectionalProbe", p.BidirectionalProbe},
+		findOption{"MaxBytes", p.MaxBytes > 0},
+		findOption{"SkipFallbackThreshold", p.SkipFallbackThreshold > 0},
+		findOption{"Offset", p.Offset != 0},
+		findOption{"SortKeyField", p.SortKeyField != ""},
+		findOption{"RequireIndex", p.RequireIndex},
+		findOption{"FindOptions", p.FindOptions != nil},
+	)
+}
+
+// textScoreParams returns the FindParams paginating on the TextScoreField, which the cursors hold
+// along with the TieBreakerFields, with their defaults filled in.
+func textScoreParams(p FindParams) FindParams {
+	p.PaginatedField, p.SortAscending = TextScoreField, false
+	return ensureDefaults(p)
+}
+
+// textScoreAggregateOptions returns the options of the aggregation of a TextScore page, set from
+// the FindParams like the options of a find query.
+func textScoreAggregateOptions(p FindParams) *options.AggregateOptions {
+	opts := options.Aggregate()
+	if p.Collation != nil {
+		opts.SetCollation(p.Collation)
+	}
+	if p.QueryComment != "" {
+		opts.SetComment(p.QueryComment)
+	}
+	if p.Hint != nil {
+		opts.SetHint(p.Hint)
+	}
+	if maxTime := queryMaxTime(p); maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	if p.AllowDiskUse {
+		opts.SetAllowDiskUse(true)
+	}
+	if batchSize := findOptions(p, nil, nil).BatchSize; batchSize != nil {
+		opts.SetBatchSize(*batchSize)
+	}
+	return opts
+}
+
+// aggregateCollection returns the AggregateCollection the wrapper Collections execute the queries
+// on, the clone with the read options of a readOptionsCollection, or nil if the Collection doesn't
+// implement it.
+func aggregateCollection(col Collection) (AggregateCollection, error) {
+	switch wrapper := col.(type) {
+	case *registryCollection:
+		return aggregateCollection(wrapper.Collection)
+	case *readOptionsCollection:
+		clone, err := wrapper.clone()
+		if err != nil {
+			return nil, err
+		}
+		return aggregateCollection(clone)
+	case AggregateCollection:
+		return wrapper, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,190 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type scoredArticle struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  string             `bson:"name"`
+	Title string             `bson:"title"`
+	Score float64            `bson:"textScore"`
+}
+
+func newArticles(t *testing.T, names ...string) []scoredArticle {
+	articles := make([]scoredArticle, 0, len(names))
+	for _, name := range names {
+		articles = append(articles, scoredArticle{ID: primitive.NewObjectID(), Name: name})
+	}
+	return articles
+}
+
+// withScore returns the article holding the score mongo adds to the results.
+func withScore(article scoredArticle, score float64) scoredArticle {
+	article.Score = score
+	return article
+}
+
+func TestFindTextScore(t *testing.T) {
+	articles := newArticles(t, "a", "b", "c", "d")
+	a, b, c, d := withScore(articles[0], 3), withScore(articles[1], 2), withScore(articles[2], 2), withScore(articles[3], 1)
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"$text": primitive.M{"$search": "go mongo"}},
+		Limit:          2,
+		PaginatedField: TextScore,
+		CountTotal:     true,
+	}
+
+	// The page is aggregated from the documents matching the query, holding their score, sorted
+	// on the score descending and the documents sharing a score on their _id descending
+	var results []scoredArticle
+	col.queuePage(t, a, c, b)
+	col.queueCount(4)
+	cursor, err := Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []scoredArticle{a, c}, results)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, 4, cursor.Count)
+	require.Equal(t, bson.M{"$and": bson.A{p.Query}}, col.countFilters[0])
+	requireStages(t, []bson.D{
+		stage(t, "$match", bson.M{"$and": bson.A{p.Query}}),
+		{{Key: "$addFields", Value: bson.D{{Key: TextScoreField, Value: bson.D{{Key: "$meta", Value: "textScore"}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: TextScoreField, Value: int32(-1)}, {Key: "_id", Value: int32(-1)}}}},
+		{{Key: "$limit", Value: int64(3)}},
+	}, col.pipelines[0])
+
+	// The cursors hold the score and the _id
+	values, err := DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{TextScoreField: float64(2), "_id": c.ID}, values)
+
+	// The pages of a cursor are queried from the score and the _id it holds, without skipping
+	p.Next = cursor.Next
+	col.queuePage(t, b, d)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []scoredArticle{b, d}, results)
+	require.True(t, cursor.HasPrevious)
+	require.False(t, cursor.HasNext)
+	pipeline := col.pipelines[len(col.pipelines)-1]
+	require.Len(t, pipeline, 5)
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, p)), pipeline[2])
+	for _, stage := range pipeline {
+		require.NotEqual(t, "$skip", stage[0].Key)
+	}
+
+	// The previous pages are sorted the other way around and returned in the order of the sort
+	p.Next, p.Previous = "", cursor.Previous
+	col.queuePage(t, c, a)
+	cursor, err = Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []scoredArticle{a, c}, results)
+	require.False(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+	pipeline = col.pipelines[len(col.pipelines)-1]
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, p)), pipeline[2])
+	require.Equal(t, bson.D{{Key: "$sort", Value: bson.D{{Key: TextScoreField, Value: int32(1)}, {Key: "_id", Value: int32(1)}}}}, pipeline[3])
+}
+
+func TestFindTextScoreHelpers(t *testing.T) {
+	articles := newArticles(t, "a", "b", "c", "d")
+	a, b, c, d := withScore(articles[0], 3), withScore(articles[1], 2), withScore(articles[2], 2), withScore(articles[3], 1)
+	col := newFakeCollection(t)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{"$text": primitive.M{"$search": "go mongo"}},
+		Limit:          2,
+		PaginatedField: TextScore,
+	}
+
+	// FindTyped and FindEdges query the pages like Find
+	col.queuePage(t, a, c, b)
+	results, cursor, err := FindTyped[scoredArticle](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []scoredArticle{a, c}, results)
+	col.queuePage(t, a, c, b)
+	edges, edgesCursor, err := FindEdges[scoredArticle](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, cursor, edgesCursor)
+	require.Equal(t, cursor.Next, edges[1].Cursor)
+	values, err := DecodeCursorToMap(edges[0].Cursor)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{TextScoreField: float64(3), "_id": a.ID}, values)
+	require.Len(t, col.pipelines, 2)
+	requireStages(t, col.pipelines[0], col.pipelines[1])
+
+	// FindAround parses the anchor as a TextScore cursor
+	col.queuePage(t, a)
+	col.queuePage(t, c, b)
+	col.queuePage(t, b, d)
+	var window []scoredArticle
+	cursor, err = FindAround(context.Background(), p, edges[1].Cursor, 1, 2, true, &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c", "b", "d"}, articleNames(window))
+	require.False(t, cursor.HasPrevious)
+	require.False(t, cursor.HasNext)
+	require.Len(t, col.pipelines, 5)
+	before := p
+	before.Previous = edges[1].Cursor
+	requireStage(t, stage(t, "$match", pageCursorQuery(t, before)), col.pipelines[2][2])
+}
+
+func TestFindTextScoreErrors(t *testing.T) {
+	p := FindParams{
+		Collection:     newFakeCollection(t),
+		Query:          primitive.M{"$text": primitive.M{"$search": "go"}},
+		Limit:          2,
+		PaginatedField: TextScore,
+	}
+
+	// The results must receive the score
+	var items []item
+	_, err := Find(context.Background(), p, &items)
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+
+	var results []scoredArticle
+	p.Next = "XXXXXaGVsbG8="
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrBadCursor))
+	p.Next = ""
+	_, err = Find(context.Background(), p, nil)
+	require.True(t, errors.Is(err, ErrNilResults))
+	p.Limit = 0
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrLimitTooSmall))
+	p.Limit = 2
+
+	// The options the aggregation can't honour are rejected
+	for _, set := range []func(p *FindParams){
+		func(p *FindParams) { p.Projection = bson.M{"name": 1} },
+		func(p *FindParams) { p.ComputeRanks = true },
+		func(p *FindParams) { p.BidirectionalProbe = true },
+		func(p *FindParams) { p.MaxBytes = 100 },
+	} {
+		pp := p
+		set(&pp)
+		_, err = Find(context.Background(), pp, &results)
+		require.True(t, errors.Is(err, ErrIncompatibleOptions), err)
+	}
+
+	// The page is queried with an aggregation
+	p.Collection = struct{ Collection }{p.Collection}
+	_, err = Find(context.Background(), p, &results)
+	require.True(t, errors.Is(err, ErrCollectionNotAggregatable))
+}
+
+func articleNames(docs []scoredArticle) []string {
+	names := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		names = append(names, doc.Name)
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+)
+
+// FindTyped executes a find mongo query by using the provided FindParams like Find, decoding the
+// documents into a slice of T rather than filling in a slice pointer, and returns the results and
+// the Cursor. The queries, the cursors and the errors are the same as the ones of Find.
+//
+// The documents are decoded by the driver straight into the []T, which is trimmed and reordered
+// without reflection. The pages of the TextScore PaginatedField, of a Projection and of a
+// DiscriminatorField are decoded from the documents the cursors are generated from, so they're
+// queried by Find: a DiscriminatorField requires T to be interface{}, which registered types the
+// results are decoded into, FindTyped returning an error matching ErrInvalidResultsType otherwise.
+func FindTyped[T any](ctx context.Context, p FindParams) ([]T, Cursor, error) {
+	var results []T
+	if p.PaginatedField == TextScore || p.Projection != nil || p.DiscriminatorField != "" {
+		cursor, err := Find(ctx, p, &results)
+		if err != nil {
+			return nil, Cursor{}, err
+		}
+		return results, cursor, nil
+	}
+	if err := validatePaginatedField(p.PaginatedField, &results); err != nil {
+		return nil, Cursor{}, err
+	}
+
+	p, queries, opts, count, err := pageQueries(ctx, p, nil)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	pageSize, err := executePageQuery(ctx, p, queries, opts, &results)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	if p.CausalCursors {
+		p = withOperationTime(ctx, p)
+	}
+
+	// Remove the extra element that we added to see if there was another page
+	hasMore := len(results) > pageSize
+	if hasMore {
+		results = results[:pageSize]
+	}
+	var first, last interface{}
+	if len(results) > 0 {
+		// If we sorted reverse to get the previous page, correct the sort order
+		if p.Previous != "" {
+			for left, right := 0, len(results)-1; left < right; left, right = left+1, right-1 {
+				results[left], results[right] = results[right], results[left]
+			}
+		}
+		first, last = results[0], results[len(results)-1]
+	}
+	cursor, err := boundaryCursor(p, len(results), first, last, hasMore)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	cursor, err = completeCursor(ctx, p, cursor, &results, count)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	return results, cursor, nil
+}
@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindTyped(t *testing.T) {
+	items := newItems("a", "b", "c", "d", "e")
+	col := newFakeCollection(t, items...)
+	p := FindParams{
+		Collection:     col,
+		Query:          primitive.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+
+	// The results and cursors are the same as the ones of Find, going forward then backward
+	for _, page := range [][]interface{}{items[0:3], items[2:5], items[4:]} {
+		col.queuePage(t, page...)
+		col.queuePage(t, page...)
+	}
+	var forward []string
+	for {
+		results, cursor, err := FindTyped[item](context.Background(), p)
+		require.NoError(t, err)
+		var expected []item
+		expectedCursor, err := Find(context.Background(), p, &expected)
+		require.NoError(t, err)
+		require.Equal(t, expected, results)
+		require.Equal(t, expectedCursor, cursor)
+		forward = append(forward, itemNames(results)...)
+		if !cursor.HasNext {
+			p.Next, p.Previous = "", cursor.Previous
+			break
+		}
+		p.Next, p.Previous = cursor.Next, ""
+	}
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, forward)
+
+	// The ranks count the documents before the page
+	for i := 0; i < 3; i++ {
+		col.queuePage(t, items[3], items[2], items[1])
+		col.queueCount(5, 2)
+	}
+	results, cursor, err := FindTyped[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, []int{3, 4}, cursor.Ranks)
+	var expected []item
+	expectedCursor, err := Find(context.Background(), p, &expected)
+	require.NoError(t, err)
+	require.Equal(t, expectedCursor, cursor)
+
+	// Pointer types are supported
+	pointers, _, err := FindTyped[*item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, &expected[0], pointers[0])
+
+	_, _, err = FindTyped[item](context.Background(), FindParams{Collection: col})
+	require.EqualError(t, err, "a limit of at least 1 is required")
+
+	// The results are validated like the ones of Find
+	_, _, err = FindTyped[item](context.Background(), FindParams{Collection: col, Limit: 1, PaginatedField: "title"})
+	require.True(t, errors.Is(err, ErrUnknownPaginatedField))
+}
+
+func TestFindTypedDiscriminated(t *testing.T) {
+	c := circle{ID: primitive.NewObjectID(), Type: "circle", Name: "a", Radius: 1}
+	r := rectangle{ID: primitive.NewObjectID(), Type: "rectangle", Name: "b", Width: 2, Height: 3}
+	p := FindParams{
+		Collection:         newFakeCollection(t, c, r),
+		Query:              primitive.M{},
+		Limit:              2,
+		SortAscending:      true,
+		PaginatedField:     "name",
+		DiscriminatorField: "type",
+		DiscriminatorTypes: map[string]reflect.Type{
+			"circle":    reflect.TypeOf(circle{}),
+			"rectangle": reflect.TypeOf(rectangle{}),
+		},
+	}
+
+	// The documents are decoded into their registered type when T is interface{}
+	results, cursor, err := FindTyped[interface{}](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{c, r}, results)
+	require.Equal(t, mustGenerateCursor(t, c, []string{"name", "_id"}), cursor.StartCursor)
+
+	// And can't be decoded into another type
+	_, _, err = FindTyped[circle](context.Background(), p)
+	require.True(t, errors.Is(err, ErrInvalidResultsType))
+}
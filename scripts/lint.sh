@@ -3,7 +3,7 @@
 # Purpose: This script lints the code.
 # Instructions: make lint
 
-VERSION="1.27.0"
+VERSION="1.45.2"
 
 $(go env GOPATH)/bin/golangci-lint --version 2>/dev/null | grep -q "version $VERSION"
 
@@ -94,7 +94,7 @@ func NewMongoService(withinDocker bool) *DockerService {
 	return &DockerService{
 		DockerHostname: dockerHostname,
 		Image:          "mongo",
-		Version:        "4.2",
+		Version:        "5.0",
 		PublishedPort:  "27017",
 		ContainerPort:  "27017",
 		Env:            []string{},
@@ -0,0 +1,328 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMongoDistinct(t *testing.T) {
+	col := newTestCollection(t)
+	insertDocuments(t, col,
+		bson.M{"_id": 1, "category": "books", "tags": bson.A{"b", "a"}},
+		bson.M{"_id": 2, "category": "games", "tags": bson.A{"c", "b"}},
+		bson.M{"_id": 3, "category": "books", "tags": "d"},
+		bson.M{"_id": 4, "category": "music", "tags": bson.A{}},
+		bson.M{"_id": 5, "category": "films"},
+		bson.M{"_id": 6, "category": "games", "tags": nil},
+	)
+	p := mongocursorpagination.DistinctParams{
+		Collection:    col,
+		Field:         "category",
+		Limit:         2,
+		SortAscending: true,
+		CountTotal:    true,
+	}
+	ctx := context.Background()
+
+	var categories []string
+	cursor, err := mongocursorpagination.Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"books", "films"}, categories)
+	require.Equal(t, 4, cursor.Count)
+	require.True(t, cursor.HasNext)
+
+	// The next page continues after the last value of the page
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"games", "music"}, categories)
+	require.False(t, cursor.HasNext)
+
+	p.Next, p.Previous = "", cursor.Previous
+	cursor, err = mongocursorpagination.Distinct(ctx, p, &categories)
+	require.NoError(t, err)
+	require.Equal(t, []string{"books", "films"}, categories)
+	require.False(t, cursor.HasPrevious)
+
+	// The elements of the arrays are distinct values of their own, the missing, null and empty
+	// array values holding none
+	p = mongocursorpagination.DistinctParams{Collection: col, Field: "tags", Limit: 10, SortAscending: true}
+	var tags []string
+	_, err = mongocursorpagination.Distinct(ctx, p, &tags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d"}, tags)
+
+	// The query selects the documents whose values are paginated over
+	p.Query = bson.M{"category": "games"}
+	p.SortAscending = false
+	_, err = mongocursorpagination.Distinct(ctx, p, &tags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, tags)
+}
+
+type player struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  string             `bson:"name"`
+	Score int                `bson:"score"`
+	Rank  int                `bson:"rank"`
+}
+
+// aggregateTraverse walks all the pages of the aggregation forward and then back to the first
+// page, returning the results in the order they were visited.
+func aggregateTraverse[T any](t *testing.T, p mongocursorpagination.AggregateParams) (forward []T, backward []T) {
+	t.Helper()
+	var cursor mongocursorpagination.Cursor
+	for {
+		var page []T
+		var err error
+		cursor, err = mongocursorpagination.Aggregate(context.Background(), p, &page)
+		require.NoError(t, err)
+		forward = append(forward, page...)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next, p.Previous = cursor.Next, ""
+	}
+	for cursor.HasPrevious {
+		var page []T
+		var err error
+		p.Next, p.Previous = "", cursor.Previous
+		cursor, err = mongocursorpagination.Aggregate(context.Background(), p, &page)
+		require.NoError(t, err)
+		for i := len(page) - 1; i >= 0; i-- {
+			backward = append(backward, page[i])
+		}
+	}
+	return forward, backward
+}
+
+func TestMongoAggregateByWindowRank(t *testing.T) {
+	col := newTestCollection(t)
+	for _, s := range []struct {
+		name  string
+		score int
+	}{{"a", 10}, {"b", 30}, {"c", 20}, {"d", 30}, {"e", 5}, {"f", 20}, {"g", 1}} {
+		insertDocuments(t, col, player{ID: primitive.NewObjectID(), Name: s.name, Score: s.score})
+	}
+	p := mongocursorpagination.AggregateParams{
+		Collection: col,
+		Pipeline: []bson.M{{"$setWindowFields": bson.M{
+			"sortBy": bson.M{"score": -1},
+			"output": bson.M{"rank": bson.M{"$rank": bson.M{}}},
+		}}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "rank",
+	}
+
+	// Players sharing a rank are ordered by _id, i.e. by insertion order
+	forward, backward := aggregateTraverse[player](t, p)
+	var names, backwardNames []string
+	var ranks []int
+	for _, result := range forward {
+		names = append(names, result.Name)
+		ranks = append(ranks, result.Rank)
+	}
+	for _, result := range backward {
+		backwardNames = append(backwardNames, result.Name)
+	}
+	require.Equal(t, []string{"b", "d", "c", "f", "a", "e", "g"}, names)
+	require.Equal(t, []int{1, 1, 3, 3, 5, 6, 7}, ranks)
+	require.Equal(t, reversed(names, 1), backwardNames)
+}
+
+type person struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	First    string             `bson:"first,omitempty"`
+	Last     string             `bson:"last,omitempty"`
+	FullName string             `bson:"fullName"`
+}
+
+func TestMongoAggregateProjectStage(t *testing.T) {
+	col := newTestCollection(t)
+	insertDocuments(t, col,
+		person{ID: primitive.NewObjectID(), First: "Ada", Last: "Lovelace"},
+		person{ID: primitive.NewObjectID(), First: "Alan", Last: "Turing"},
+		person{ID: primitive.NewObjectID(), First: "Grace", Last: "Hopper"},
+	)
+	fullName := bson.M{"$concat": bson.A{"$first", " ", "$last"}}
+	for _, tc := range []struct {
+		name         string
+		projectStage bson.M
+		expected     []person
+	}{
+		{
+			"computed field added to the documents",
+			bson.M{"$addFields": bson.M{"fullName": fullName}},
+			[]person{{First: "Grace", Last: "Hopper", FullName: "Grace Hopper"}, {First: "Ada", Last: "Lovelace", FullName: "Ada Lovelace"}},
+		},
+		{
+			"inclusion projection keeping the paginated field and the _id",
+			bson.M{"$project": bson.M{"_id": 0, "fullName": fullName}},
+			[]person{{Last: "Hopper", FullName: "Grace Hopper"}, {Last: "Lovelace", FullName: "Ada Lovelace"}},
+		},
+		{
+			"exclusion projection keeping the paginated field",
+			bson.M{"$project": bson.M{"first": 0, "last": 0}},
+			[]person{{Last: "Hopper"}, {Last: "Lovelace"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := mongocursorpagination.AggregateParams{
+				Collection:     col,
+				Limit:          2,
+				SortAscending:  true,
+				PaginatedField: "last",
+				ProjectStage:   tc.projectStage,
+			}
+			var results []person
+			cursor, err := mongocursorpagination.Aggregate(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			for i := range results {
+				require.False(t, results[i].ID.IsZero())
+				results[i].ID = primitive.NilObjectID
+			}
+			require.Equal(t, tc.expected, results)
+
+			// The cursor is generated from the kept paginated field
+			p.Next = cursor.Next
+			results = nil
+			cursor, err = mongocursorpagination.Aggregate(context.Background(), p, &results)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, "Turing", results[0].Last)
+			require.False(t, cursor.HasNext)
+		})
+	}
+}
+
+type version struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Number string             `bson:"number"`
+}
+
+func TestMongoAggregateNumericStringField(t *testing.T) {
+	col := newTestCollection(t)
+	for _, number := range []string{"9", "10", "100", "11", "n/a"} {
+		insertDocuments(t, col, version{ID: primitive.NewObjectID(), Number: number})
+	}
+	p := mongocursorpagination.AggregateParams{
+		Collection:         col,
+		Limit:              2,
+		SortAscending:      true,
+		PaginatedField:     "number",
+		NumericStringField: true,
+	}
+
+	// Strings which aren't numbers are sorted first
+	forward, backward := aggregateTraverse[version](t, p)
+	var numbers, backwardNumbers []string
+	for _, v := range forward {
+		numbers = append(numbers, v.Number)
+	}
+	for _, v := range backward {
+		backwardNumbers = append(backwardNumbers, v.Number)
+	}
+	require.Equal(t, []string{"n/a", "9", "10", "11", "100"}, numbers)
+	require.Equal(t, reversed(numbers, 1), backwardNumbers)
+
+	p.SortAscending = false
+	var page []version
+	_, err := mongocursorpagination.Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, "100", page[0].Number)
+	require.Equal(t, "11", page[1].Number)
+
+	// The numeric key is removed from the results
+	var docs []bson.M
+	_, err = mongocursorpagination.Aggregate(context.Background(), p, &docs)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"_id": page[0].ID, "number": "100"}, docs[0])
+}
+
+func TestMongoAggregateCountTotal(t *testing.T) {
+	col := newTestCollection(t)
+	for _, s := range []struct {
+		name  string
+		score int
+	}{{"a", 10}, {"b", 30}, {"c", 20}, {"d", 5}} {
+		insertDocuments(t, col, player{ID: primitive.NewObjectID(), Name: s.name, Score: s.score})
+	}
+	p := mongocursorpagination.AggregateParams{
+		Collection:     col,
+		Pipeline:       []bson.M{{"$match": bson.M{"score": bson.M{"$gte": 10}}}},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	var page []player
+	cursor, err := mongocursorpagination.Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, 3, cursor.Count)
+
+	// The count ignores the cursor boundary
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, 3, cursor.Count)
+
+	// No document is counted as 0
+	p.Pipeline = []bson.M{{"$match": bson.M{"score": bson.M{"$gt": 100}}}}
+	p.Next = ""
+	cursor, err = mongocursorpagination.Aggregate(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Empty(t, page)
+	require.Equal(t, 0, cursor.Count)
+}
+
+type article struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Title     string             `bson:"title"`
+	UpdatedAt *int               `bson:"updatedAt,omitempty"`
+	CreatedAt int                `bson:"createdAt"`
+}
+
+func TestMongoAggregateFallbackField(t *testing.T) {
+	updated := func(at int) *int { return &at }
+	col := newTestCollection(t)
+	insertDocuments(t, col,
+		article{ID: primitive.NewObjectID(), Title: "a", UpdatedAt: updated(50), CreatedAt: 10},
+		article{ID: primitive.NewObjectID(), Title: "b", CreatedAt: 30},
+		article{ID: primitive.NewObjectID(), Title: "c", UpdatedAt: updated(20), CreatedAt: 5},
+		article{ID: primitive.NewObjectID(), Title: "d", CreatedAt: 40},
+		article{ID: primitive.NewObjectID(), Title: "e", UpdatedAt: updated(35), CreatedAt: 1},
+	)
+	p := mongocursorpagination.AggregateParams{
+		Collection:     col,
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "updatedAt",
+		FallbackField:  "createdAt",
+	}
+
+	// The documents without an updatedAt are sorted on their createdAt
+	forward, backward := aggregateTraverse[article](t, p)
+	var titles, backwardTitles []string
+	for _, a := range forward {
+		titles = append(titles, a.Title)
+	}
+	for _, a := range backward {
+		backwardTitles = append(backwardTitles, a.Title)
+	}
+	require.Equal(t, []string{"c", "b", "e", "d", "a"}, titles)
+	require.Equal(t, reversed(titles, 1), backwardTitles)
+
+	// The sort key is removed from the results
+	var docs []bson.M
+	_, err := mongocursorpagination.Aggregate(context.Background(), p, &docs)
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"_id": docs[0]["_id"], "title": "c", "updatedAt": int32(20), "createdAt": int32(5)}, docs[0])
+}
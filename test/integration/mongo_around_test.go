@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMongoFindAround(t *testing.T) {
+	col := newTestCollection(t)
+	items := insertItems(t, col, "a", "b", "c", "d", "e", "f", "g", "h", "i", "j")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	cursorOf := func(i item) string { return documentCursor(t, i, "name", "_id") }
+	anchor := cursorOf(items[4])
+
+	// The documents before and after the anchor are merged in the sort order
+	var results []item
+	cursor, err := mongocursorpagination.FindAround(context.Background(), p, anchor, 2, 2, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d", "e", "f", "g"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, int64(5), cursor.Returned)
+	require.Equal(t, 10, cursor.Count)
+	require.Equal(t, cursorOf(items[2]), cursor.StartCursor)
+	require.Equal(t, cursorOf(items[6]), cursor.EndCursor)
+
+	// The cursors extend the window
+	var page []item
+	p.Limit, p.Previous = 2, cursor.Previous
+	_, err = mongocursorpagination.Find(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(page))
+	p.Previous, p.Next = "", cursor.Next
+	_, err = mongocursorpagination.Find(context.Background(), p, &page)
+	require.NoError(t, err)
+	require.Equal(t, []string{"h", "i"}, itemNames(page))
+	p.Limit, p.Next = 0, ""
+
+	// The anchor can be left out
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, anchor, 2, 2, false, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d", "f", "g"}, itemNames(results))
+	require.Equal(t, cursorOf(items[6]), cursor.Next)
+
+	// The window stops at the ends of the collection
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[1]), 3, 3, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.Empty(t, cursor.Previous)
+	require.True(t, cursor.HasNext)
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[8]), 1, 3, false, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"h", "j"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.False(t, cursor.HasNext)
+	require.Empty(t, cursor.Next)
+
+	// A side of size 0 is probed for a single document past the window
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, anchor, 0, 1, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"e", "f"}, itemNames(results))
+	require.True(t, cursor.HasPrevious)
+	require.Equal(t, anchor, cursor.Previous)
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[0]), 0, 1, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+	require.Empty(t, cursor.Previous)
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[9]), 1, 0, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"i", "j"}, itemNames(results))
+	require.False(t, cursor.HasNext)
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[9]), 1, 0, false, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"i"}, itemNames(results))
+	require.True(t, cursor.HasNext)
+	require.Equal(t, cursorOf(items[8]), cursor.Next)
+
+	// A deleted anchor still anchors the window
+	_, err = col.collection.DeleteOne(context.Background(), bson.M{"_id": items[4].ID})
+	require.NoError(t, err)
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, anchor, 1, 1, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "f"}, itemNames(results))
+	require.Equal(t, int64(2), cursor.Returned)
+
+	// The anchor is queried by Find, so the Projection applies to it
+	p.Projection = bson.M{"name": 0}
+	cursor, err = mongocursorpagination.FindAround(context.Background(), p, cursorOf(items[5]), 0, 0, true, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, itemNames(results))
+	require.Equal(t, cursorOf(items[5]), cursor.StartCursor)
+}
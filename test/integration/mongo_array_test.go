@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type taggedDocument struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+	Tags []string           `bson:"tags"`
+}
+
+func newTaggedCollection(t *testing.T) *mongoCollectionWrapper {
+	t.Helper()
+	col := newTestCollection(t)
+	for _, doc := range []struct {
+		name string
+		tags []string
+	}{
+		{"a", []string{"m", "c"}},
+		{"b", []string{"b", "z"}},
+		{"c", []string{"d"}},
+		{"d", []string{"c", "e"}},
+		{"e", []string{"a", "x"}},
+		{"f", []string{"d", "f"}},
+	} {
+		insertDocuments(t, col, taggedDocument{ID: primitive.NewObjectID(), Name: doc.name, Tags: doc.tags})
+	}
+	return col
+}
+
+func taggedName(doc taggedDocument) string {
+	return doc.Name
+}
+
+func TestMongoFindArrayPaginatedField(t *testing.T) {
+	cases := []struct {
+		name          string
+		sortAscending bool
+		expected      []string
+	}{
+		// Sorted on the smallest tag, a and d sharing c, c and f sharing d
+		{"ascending", true, []string{"e", "b", "a", "d", "c", "f"}},
+		// Sorted on the largest tag
+		{"descending", false, []string{"b", "e", "a", "f", "d", "c"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := mongocursorpagination.FindParams{
+				Collection:          newTaggedCollection(t),
+				Query:               bson.M{},
+				Limit:               2,
+				SortAscending:       tc.sortAscending,
+				PaginatedField:      "tags",
+				ArrayPaginatedField: true,
+			}
+			forward, backward := traverse(t, p, taggedName)
+			require.Equal(t, tc.expected, forward)
+			require.Equal(t, reversed(tc.expected, 2), backward)
+		})
+	}
+}
+
+func TestMongoFindArrayPaginatedFieldPreviousPage(t *testing.T) {
+	p := mongocursorpagination.FindParams{
+		Collection:          newTaggedCollection(t),
+		Query:               bson.M{},
+		Limit:               3,
+		PaginatedField:      "tags",
+		ArrayPaginatedField: true,
+	}
+
+	// The page before the page of a Previous cursor is told apart from the first page
+	var results []taggedDocument
+	cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	p.Next, p.Previous = "", cursor.Previous
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	names := make([]string, 0, len(results))
+	for _, doc := range results {
+		names = append(names, taggedName(doc))
+	}
+	require.Equal(t, []string{"b", "e", "a"}, names)
+	require.False(t, cursor.HasPrevious)
+	require.True(t, cursor.HasNext)
+}
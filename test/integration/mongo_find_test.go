@@ -0,0 +1,1014 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestCollection returns an empty collection of the test_db named after the test, dropped when
+// the test completes.
+func newTestCollection(t *testing.T) *mongoCollectionWrapper {
+	t.Helper()
+	col := newMongoCollection(t)
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	col.collection = col.collection.Database().Collection(name)
+	require.NoError(t, col.collection.Drop(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, col.collection.Drop(context.Background()))
+		require.NoError(t, col.collection.Database().Client().Disconnect(context.Background()))
+	})
+	return col
+}
+
+func insertDocuments(t *testing.T, col *mongoCollectionWrapper, docs ...interface{}) {
+	t.Helper()
+	_, err := col.collection.InsertMany(context.Background(), docs)
+	require.NoError(t, err)
+}
+
+type item struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Name   string             `bson:"name"`
+	Group  string             `bson:"group,omitempty"`
+	Region string             `bson:"region,omitempty"`
+	Seq    int                `bson:"seq,omitempty"`
+}
+
+// insertItems inserts an item for each name, in order, and returns them.
+func insertItems(t *testing.T, col *mongoCollectionWrapper, names ...string) []item {
+	t.Helper()
+	items := make([]item, 0, len(names))
+	for _, name := range names {
+		i := item{ID: primitive.NewObjectID(), Name: name}
+		insertDocuments(t, col, i)
+		items = append(items, i)
+	}
+	return items
+}
+
+func itemName(i item) string {
+	return i.Name
+}
+
+// itemNames returns the names of the items, in order.
+func itemNames(items []item) []string {
+	var names []string
+	for _, i := range items {
+		names = append(names, i.Name)
+	}
+	return names
+}
+
+// documentCursor returns the cursor of the document holding the values of its fields.
+func documentCursor(t *testing.T, doc interface{}, fields ...string) string {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	cursorData := bson.D{}
+	for _, field := range fields {
+		cursorData = append(cursorData, bson.E{Key: field, Value: bson.Raw(data).Lookup(strings.Split(field, ".")...)})
+	}
+	return encodeCursor(t, cursorData)
+}
+
+// traverse walks all the pages forward using the Next cursors and then back to the first page
+// using the Previous cursors, returning the names of the results in the order they were visited.
+func traverse[T any](t *testing.T, p mongocursorpagination.FindParams, nameOf func(T) string) (forward []string, backward []string) {
+	t.Helper()
+	var cursor mongocursorpagination.Cursor
+	for {
+		var page []T
+		var err error
+		cursor, err = mongocursorpagination.Find(context.Background(), p, &page)
+		require.NoError(t, err)
+		for _, result := range page {
+			forward = append(forward, nameOf(result))
+		}
+		if !cursor.HasNext {
+			break
+		}
+		p.Next, p.Previous = cursor.Next, ""
+	}
+	for cursor.HasPrevious {
+		var page []T
+		var err error
+		p.Next, p.Previous = "", cursor.Previous
+		cursor, err = mongocursorpagination.Find(context.Background(), p, &page)
+		require.NoError(t, err)
+		for i := len(page) - 1; i >= 0; i-- {
+			backward = append(backward, nameOf(page[i]))
+		}
+	}
+	return forward, backward
+}
+
+// reversed returns the names in the opposite order, the last page excluded, which is the order in
+// which traverse visits them backward.
+func reversed(names []string, lastPageSize int) []string {
+	var r []string
+	for i := len(names) - lastPageSize - 1; i >= 0; i-- {
+		r = append(r, names[i])
+	}
+	return r
+}
+
+func TestMongoFindTextScore(t *testing.T) {
+	type article struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Name  string             `bson:"name"`
+		Title string             `bson:"title"`
+		Score float64            `bson:"textScore,omitempty"`
+	}
+	col := newTestCollection(t)
+	_, err := col.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{Keys: bson.D{{Key: "title", Value: "text"}}})
+	require.NoError(t, err)
+	// The articles with the same title have the same score, the ones matching both words scoring
+	// higher than the ones matching one
+	for _, doc := range []article{
+		{Name: "a", Title: "mongo cursor"},
+		{Name: "b", Title: "mongo cursor"},
+		{Name: "c", Title: "mongo"},
+		{Name: "d", Title: "mongo cursor"},
+		{Name: "e", Title: "python"},
+		{Name: "f", Title: "mongo"},
+		{Name: "g", Title: "mongo cursor"},
+	} {
+		doc.ID = primitive.NewObjectID()
+		insertDocuments(t, col, doc)
+	}
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{"$text": bson.M{"$search": "mongo cursor"}},
+		Limit:          2,
+		PaginatedField: mongocursorpagination.TextScore,
+		CountTotal:     true,
+	}
+
+	// The tied scores fall through to the _id descending, the pages splitting them without
+	// repeating or skipping any
+	expected := []string{"g", "d", "b", "a", "f", "c"}
+	nameOf := func(doc article) string { return doc.Name }
+	for _, limit := range []int64{1, 2, 3, 4} {
+		p.Limit = limit
+		forward, backward := traverse(t, p, nameOf)
+		require.Equal(t, expected, forward, "limit %d", limit)
+		lastPageSize := len(expected) % int(limit)
+		if lastPageSize == 0 {
+			lastPageSize = int(limit)
+		}
+		require.Equal(t, reversed(expected, lastPageSize), backward, "limit %d", limit)
+	}
+
+	// The results hold the score, the cursors the score and the _id of the last result
+	p.Limit = 3
+	var results []article
+	cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, 6, cursor.Count)
+	require.Equal(t, results[0].Score, results[2].Score)
+	values, err := mongocursorpagination.DecodeCursorToMap(cursor.Next)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{mongocursorpagination.TextScoreField: results[2].Score, "_id": results[2].ID}, values)
+
+	// The next page starts with the last document of the tied scores
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, "a", results[0].Name)
+	require.Greater(t, values[mongocursorpagination.TextScoreField], results[1].Score)
+	require.False(t, cursor.HasNext)
+	require.True(t, cursor.HasPrevious)
+}
+
+func TestMongoFindSnapshotWithExpiringDocuments(t *testing.T) {
+	type expiringItem struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Name      string             `bson:"name"`
+		ExpiresAt time.Time          `bson:"expiresAt"`
+	}
+	snapshot := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	newID := func(createdAt time.Time, n byte) primitive.ObjectID {
+		id := primitive.NewObjectIDFromTimestamp(createdAt)
+		id[11] = n
+		return id
+	}
+	createdAt := snapshot.Add(-time.Hour)
+	farFuture := snapshot.Add(24 * time.Hour)
+	col := newTestCollection(t)
+	insertDocuments(t, col,
+		expiringItem{ID: newID(createdAt, 1), Name: "test item 1", ExpiresAt: farFuture},
+		// Expired at the snapshot time but not removed yet by the TTL monitor
+		expiringItem{ID: newID(createdAt, 2), Name: "test item 2", ExpiresAt: snapshot.Add(-time.Minute)},
+		expiringItem{ID: newID(createdAt, 3), Name: "test item 3", ExpiresAt: farFuture},
+		// Expires while the pages are traversed
+		expiringItem{ID: newID(createdAt, 4), Name: "test item 4", ExpiresAt: snapshot.Add(time.Minute)},
+		expiringItem{ID: newID(createdAt, 5), Name: "test item 5", ExpiresAt: farFuture},
+		// Created in the same second as the snapshot
+		expiringItem{ID: newID(snapshot.Add(500*time.Millisecond), 6), Name: "test item 6", ExpiresAt: farFuture},
+	)
+	expiringItemNames := func(items []expiringItem) []string {
+		names := make([]string, 0, len(items))
+		for _, i := range items {
+			names = append(names, i.Name)
+		}
+		return names
+	}
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		SnapshotTime:   snapshot,
+		ExpiryField:    "expiresAt",
+	}
+
+	var results []expiringItem
+	cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.True(t, cursor.HasNext)
+	require.Equal(t, 5, cursor.Count)
+	require.Equal(t, []string{"test item 1", "test item 3"}, expiringItemNames(results))
+
+	// The TTL monitor removes the expired documents and a document is created after the snapshot
+	_, err = col.collection.DeleteMany(context.Background(), bson.M{"expiresAt": bson.M{"$lt": snapshot.Add(2 * time.Minute)}})
+	require.NoError(t, err)
+	insertDocuments(t, col, expiringItem{ID: newID(snapshot.Add(time.Hour), 7), Name: "test item 3b", ExpiresAt: farFuture})
+
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.False(t, cursor.HasNext)
+	require.Equal(t, []string{"test item 5", "test item 6"}, expiringItemNames(results))
+
+	p.Next, p.Previous = "", cursor.Previous
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.False(t, cursor.HasPrevious)
+	require.Equal(t, []string{"test item 1", "test item 3"}, expiringItemNames(results))
+}
+
+func TestMongoFindBidirectionalProbe(t *testing.T) {
+	newParams := func(col *mongoCollectionWrapper) mongocursorpagination.FindParams {
+		return mongocursorpagination.FindParams{
+			Collection:         col,
+			Query:              bson.M{},
+			Limit:              2,
+			SortAscending:      true,
+			BidirectionalProbe: true,
+		}
+	}
+	find := func(p mongocursorpagination.FindParams, next, previous string) ([]item, mongocursorpagination.Cursor) {
+		t.Helper()
+		p.Next, p.Previous = next, previous
+		var results []item
+		cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		return results, cursor
+	}
+	requireFlags := func(cursor mongocursorpagination.Cursor, hasPrevious, hasNext bool) {
+		t.Helper()
+		require.Equal(t, hasPrevious, cursor.HasPrevious)
+		require.Equal(t, hasPrevious, cursor.Previous != "")
+		require.Equal(t, hasNext, cursor.HasNext)
+		require.Equal(t, hasNext, cursor.Next != "")
+	}
+	remove := func(col *mongoCollectionWrapper, names ...string) {
+		t.Helper()
+		_, err := col.collection.DeleteMany(context.Background(), bson.M{"name": bson.M{"$in": names}})
+		require.NoError(t, err)
+	}
+
+	t.Run("first, middle and last pages", func(t *testing.T) {
+		col := newTestCollection(t)
+		insertItems(t, col, "a", "b", "c", "d", "e", "f")
+		p := newParams(col)
+		_, first := find(p, "", "")
+		requireFlags(first, false, true)
+		_, middle := find(p, first.Next, "")
+		requireFlags(middle, true, true)
+		_, last := find(p, middle.Next, "")
+		requireFlags(last, true, false)
+		_, middle = find(p, "", last.Previous)
+		requireFlags(middle, true, true)
+		_, first = find(p, "", middle.Previous)
+		requireFlags(first, false, true)
+	})
+
+	t.Run("documents before the page of a Next cursor deleted", func(t *testing.T) {
+		col := newTestCollection(t)
+		insertItems(t, col, "a", "b", "c", "d", "e", "f")
+		p := newParams(col)
+		_, first := find(p, "", "")
+		remove(col, "a", "b")
+		p.BidirectionalProbe = false
+		_, page := find(p, first.Next, "")
+		requireFlags(page, true, true)
+		p.BidirectionalProbe = true
+		results, page := find(p, first.Next, "")
+		require.Equal(t, "c", results[0].Name)
+		requireFlags(page, false, true)
+	})
+
+	t.Run("documents after the page of a Previous cursor deleted", func(t *testing.T) {
+		col := newTestCollection(t)
+		insertItems(t, col, "a", "b", "c", "d", "e", "f")
+		p := newParams(col)
+		_, first := find(p, "", "")
+		_, middle := find(p, first.Next, "")
+		_, last := find(p, middle.Next, "")
+		remove(col, "e", "f")
+		p.BidirectionalProbe = false
+		_, page := find(p, "", last.Previous)
+		requireFlags(page, true, true)
+		p.BidirectionalProbe = true
+		results, page := find(p, "", last.Previous)
+		require.Equal(t, "d", results[1].Name)
+		requireFlags(page, true, false)
+	})
+
+	t.Run("empty page of a Next cursor", func(t *testing.T) {
+		col := newTestCollection(t)
+		insertItems(t, col, "a", "b", "c")
+		p := newParams(col)
+		_, first := find(p, "", "")
+		remove(col, "c")
+		results, page := find(p, first.Next, "")
+		require.Empty(t, results)
+		require.True(t, page.HasPrevious)
+		require.False(t, page.HasNext)
+	})
+}
+
+func TestMongoFindNaturalOrder(t *testing.T) {
+	col := newTestCollection(t)
+	// The capped collection holds at most 5 documents, the oldest being removed when inserting
+	// past it
+	require.NoError(t, col.collection.Drop(context.Background()))
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(1 << 20).SetMaxDocuments(5)
+	require.NoError(t, col.collection.Database().CreateCollection(context.Background(), col.collection.Name(), opts))
+	insertItems(t, col, "a", "b", "c", "d", "e", "f")
+	p := mongocursorpagination.FindParams{
+		Collection:    col,
+		Query:         bson.M{},
+		Limit:         2,
+		SortAscending: true,
+		NaturalOrder:  true,
+	}
+
+	// The pages are sorted in natural order, the cursors holding the _id
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, []string{"b", "c", "d", "e", "f"}, forward)
+	require.Equal(t, reversed(forward, 1), backward)
+	p.SortAscending = false
+	forward, backward = traverse(t, p, itemName)
+	require.Equal(t, []string{"f", "e", "d", "c", "b"}, forward)
+	require.Equal(t, reversed(forward, 1), backward)
+	p.SortAscending = true
+
+	// Tailing the collection, the documents removed since the cursor was generated are skipped
+	var results []item
+	cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, itemNames(results))
+	insertItems(t, col, "g", "h", "i", "j")
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"f", "g"}, itemNames(results))
+	p.Next = cursor.Next
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"h", "i"}, itemNames(results))
+	require.True(t, cursor.HasNext)
+}
+
+func TestMongoFindCanonicalCursor(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "a", "b", "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:      col,
+		Query:           bson.M{},
+		Limit:           2,
+		SortAscending:   true,
+		PaginatedField:  "name",
+		CursorTTL:       time.Hour,
+		CanonicalCursor: true,
+	}
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, forward)
+	require.Equal(t, []string{"d", "c", "b", "a"}, backward)
+}
+
+func TestMongoFindJSONCursorFormat(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "a", "b", "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CursorFormat:   mongocursorpagination.CursorFormatJSON,
+	}
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, forward)
+	require.Equal(t, []string{"d", "c", "b", "a"}, backward)
+}
+
+func TestMongoFindEdges(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "test item 1", "test item 2", "test item 3", "test item 4")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          3,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// Getting the page after the cursor of an edge starts right after its node
+	edges, _, err := mongocursorpagination.FindEdges[*item](context.Background(), p)
+	require.NoError(t, err)
+	require.Len(t, edges, 3)
+	for i, edge := range edges {
+		p.Next = edge.Cursor
+		var results []item
+		_, err = mongocursorpagination.Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		if i < len(edges)-1 {
+			require.Equal(t, edges[i+1].Node.ID, results[0].ID)
+		} else {
+			require.Equal(t, "test item 4", results[0].Name)
+		}
+	}
+}
+
+func TestMongoFindIDs(t *testing.T) {
+	col := newTestCollection(t)
+	items := insertItems(t, col, "c", "a", "d", "b", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	ids, cursor, err := mongocursorpagination.FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{items[1].ID, items[3].ID}, ids)
+	require.True(t, cursor.HasNext)
+
+	// The cursor is valid for both FindIDs and Find
+	p.Next = cursor.Next
+	ids, cursor, err = mongocursorpagination.FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{items[0].ID, items[2].ID}, ids)
+	var results []item
+	_, err = mongocursorpagination.Find(context.Background(), p, &results)
+	require.NoError(t, err)
+	require.Equal(t, []item{items[0], items[2]}, results)
+
+	p.Next = cursor.Next
+	ids, cursor, err = mongocursorpagination.FindIDs(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{items[4].ID}, ids)
+	require.False(t, cursor.HasNext)
+	require.True(t, cursor.HasPrevious)
+}
+
+func TestMongoPaginatorCollation(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "b", "C", "a", "d", "e")
+	collation := &options.Collation{Locale: "en", Strength: 2}
+	pg := mongocursorpagination.NewPaginator(col.collection.Database(), col.collection.Name(),
+		mongocursorpagination.WithPaginatedField("name"),
+		mongocursorpagination.WithSortAscending(true),
+		mongocursorpagination.WithCollation(collation),
+		mongocursorpagination.WithCountTotal(true),
+	)
+	ctx := context.Background()
+
+	// The names are sorted regardless of their case
+	var results []item
+	cursor, err := pg.Next(ctx, bson.M{}, 2, "", &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.Equal(t, 5, cursor.Count)
+
+	results = nil
+	cursor, err = pg.Next(ctx, bson.M{}, 2, cursor.Next, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"C", "d"}, itemNames(results))
+
+	results = nil
+	cursor, err = pg.Previous(ctx, bson.M{}, 2, cursor.Previous, &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(results))
+	require.False(t, cursor.HasPrevious)
+
+	// The query and the limit are those of the call
+	results = nil
+	_, err = pg.Next(ctx, bson.M{"name": bson.M{"$ne": "a"}}, 1, "", &results)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, itemNames(results))
+}
+
+func TestMongoFindCursorSecret(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "a", "b", "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CursorSecret:   []byte("s3cr3t"),
+	}
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, forward)
+	require.Equal(t, []string{"d", "c", "b", "a"}, backward)
+}
+
+func TestMongoFindStream(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "c", "a", "e", "b", "d")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+	}
+	stream := func(p mongocursorpagination.FindParams) ([]string, mongocursorpagination.Cursor) {
+		var names []string
+		cursor, err := mongocursorpagination.FindStream(context.Background(), p, func(raw bson.Raw) error {
+			names = append(names, raw.Lookup("name").StringValue())
+			return nil
+		})
+		require.NoError(t, err)
+		return names, cursor
+	}
+	find := func(p mongocursorpagination.FindParams) mongocursorpagination.Cursor {
+		var results []item
+		cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		return cursor
+	}
+
+	// The pages are streamed in the sort order, with the cursors Find returns
+	var pages [][]string
+	for {
+		names, cursor := stream(p)
+		require.Equal(t, find(p), cursor)
+		pages = append(pages, names)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+
+	// and streaming back from the last page gets the previous pages in the sort order too
+	_, cursor := stream(p)
+	p.Next, p.Previous = "", cursor.Previous
+	pages = nil
+	for {
+		var names []string
+		names, cursor = stream(p)
+		require.Equal(t, find(p), cursor)
+		pages = append(pages, names)
+		if !cursor.HasPrevious {
+			break
+		}
+		p.Previous = cursor.Previous
+	}
+	require.Equal(t, [][]string{{"c", "d"}, {"a", "b"}}, pages)
+	require.Equal(t, 5, cursor.Count)
+}
+
+func TestMongoFindTypedRanks(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "a", "b", "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+
+	// The ranks of the documents are their positions in the sort order, on the pages of both
+	// cursors
+	results, cursor, err := mongocursorpagination.FindTyped[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, cursor.Ranks)
+	p.Next = cursor.Next
+	results, cursor, err = mongocursorpagination.FindTyped[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, []int{3, 4}, cursor.Ranks)
+	p.Next = cursor.Next
+	_, cursor, err = mongocursorpagination.FindTyped[item](context.Background(), p)
+	require.NoError(t, err)
+	p.Next, p.Previous = "", cursor.Previous
+	results, cursor, err = mongocursorpagination.FindTyped[item](context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d"}, itemNames(results))
+	require.Equal(t, []int{3, 4}, cursor.Ranks)
+}
+
+func TestMongoFindCompositeTieBreaker(t *testing.T) {
+	col := newTestCollection(t)
+	insertDocuments(t, col,
+		item{ID: primitive.NewObjectID(), Name: "b-emea-2", Group: "b", Region: "emea", Seq: 2},
+		item{ID: primitive.NewObjectID(), Name: "a-emea-10", Group: "a", Region: "emea", Seq: 10},
+		item{ID: primitive.NewObjectID(), Name: "a-amer-3", Group: "a", Region: "amer", Seq: 3},
+		item{ID: primitive.NewObjectID(), Name: "a-emea-9", Group: "a", Region: "emea", Seq: 9},
+		item{ID: primitive.NewObjectID(), Name: "b-amer-1", Group: "b", Region: "amer", Seq: 1},
+		item{ID: primitive.NewObjectID(), Name: "a-amer-1", Group: "a", Region: "amer", Seq: 1},
+		item{ID: primitive.NewObjectID(), Name: "b-emea-1", Group: "b", Region: "emea", Seq: 1},
+	)
+
+	// The strings and the integers of the tie-breaker fields are compared as such, e.g. 9 before
+	// 10, the pages splitting the ties without repeating or skipping any
+	expected := []string{"a-amer-1", "a-amer-3", "a-emea-9", "a-emea-10", "b-amer-1", "b-emea-1", "b-emea-2"}
+	p := mongocursorpagination.FindParams{
+		Collection:       col,
+		Query:            bson.M{},
+		Limit:            2,
+		SortAscending:    true,
+		PaginatedField:   "group",
+		TieBreakerFields: []string{"region", "seq"},
+	}
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, expected, forward)
+	require.Equal(t, reversed(expected, 1), backward)
+
+	p.SortAscending = false
+	forward, backward = traverse(t, p, itemName)
+	require.Equal(t, reversed(expected, 0), forward)
+	require.Equal(t, expected[1:], backward)
+}
+
+func TestMongoFindCaseInsensitiveCollation(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "banana", "Apple", "cherry", "apple", "Banana", "APPLE", "Cherry")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		Collation:      &options.Collation{Locale: "en", Strength: 2},
+	}
+
+	// The names equal under the collation are ordered by _id, and the pages split them without
+	// repeating or skipping any
+	expected := []string{"Apple", "apple", "APPLE", "banana", "Banana", "cherry", "Cherry"}
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, expected, forward)
+	require.Equal(t, reversed(expected, 1), backward)
+
+	p.SortAscending = false
+	forward, backward = traverse(t, p, itemName)
+	require.Equal(t, reversed(expected, 0), forward)
+	require.Equal(t, expected[1:], backward)
+
+	// Count matches the query with the collation too
+	p.Query = bson.M{"name": "apple"}
+	count, _, err := mongocursorpagination.Count(context.Background(), p)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+}
+
+func TestMongoFindPointerPaginatedField(t *testing.T) {
+	type task struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Name  string             `bson:"name"`
+		DueAt *time.Time         `bson:"dueAt"`
+	}
+	due := func(hour int) *time.Time {
+		dueAt := time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)
+		return &dueAt
+	}
+	col := newTestCollection(t)
+	for _, doc := range []task{
+		{Name: "a", DueAt: due(3)},
+		{Name: "b"},
+		{Name: "c", DueAt: due(1)},
+		{Name: "d"},
+		{Name: "e", DueAt: due(2)},
+	} {
+		doc.ID = primitive.NewObjectID()
+		insertDocuments(t, col, doc)
+	}
+
+	// The nil due dates are sorted before any date
+	for _, tc := range []struct {
+		sortAscending bool
+		expected      []string
+	}{
+		{true, []string{"b", "d", "c", "e", "a"}},
+		{false, []string{"a", "e", "c", "d", "b"}},
+	} {
+		p := mongocursorpagination.FindParams{
+			Collection:     col,
+			Query:          bson.M{},
+			Limit:          2,
+			SortAscending:  tc.sortAscending,
+			PaginatedField: "dueAt",
+		}
+		forward, backward := traverse(t, p, func(doc task) string { return doc.Name })
+		require.Equal(t, tc.expected, forward)
+		require.Equal(t, reversed(tc.expected, 1), backward)
+	}
+}
+
+func TestMongoFindIDSortAscending(t *testing.T) {
+	col := newTestCollection(t)
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		insertDocuments(t, col, item{ID: primitive.NewObjectID(), Name: name, Seq: []int{1, 2, 1, 2, 1, 3}[i]})
+	}
+	ascending, descending := true, false
+	var cases = []struct {
+		name            string
+		sortAscending   bool
+		idSortAscending *bool
+		expected        []string
+	}{
+		{"ascending score and _id", true, &ascending, []string{"a", "c", "e", "b", "d", "f"}},
+		{"ascending score, descending _id", true, &descending, []string{"e", "c", "a", "d", "b", "f"}},
+		{"descending score, ascending _id", false, &ascending, []string{"f", "b", "d", "a", "c", "e"}},
+		{"descending score and _id", false, &descending, []string{"f", "d", "b", "e", "c", "a"}},
+		{"_id sorted according to SortAscending when unset", false, nil, []string{"f", "d", "b", "e", "c", "a"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := mongocursorpagination.FindParams{
+				Collection:      col,
+				Query:           bson.M{},
+				Limit:           2,
+				SortAscending:   tc.sortAscending,
+				PaginatedField:  "seq",
+				IDSortAscending: tc.idSortAscending,
+			}
+			forward, backward := traverse(t, p, itemName)
+			require.Equal(t, tc.expected, forward)
+			require.Equal(t, reversed(tc.expected, 2), backward)
+		})
+	}
+}
+
+// requireTraversals asserts every limit walks the documents in the expected order, forward and
+// backward.
+func requireTraversals(t *testing.T, p mongocursorpagination.FindParams, expected []string) {
+	t.Helper()
+	nameOf := func(doc bson.M) string { return doc["name"].(string) }
+	for limit := int64(1); limit <= int64(len(expected)); limit++ {
+		p.Limit = limit
+		forward, backward := traverse(t, p, nameOf)
+		require.Equal(t, expected, forward, "limit %d", limit)
+		lastPageSize := len(expected) % int(limit)
+		if lastPageSize == 0 {
+			lastPageSize = int(limit)
+		}
+		require.Equal(t, reversed(expected, lastPageSize), backward, "limit %d", limit)
+	}
+}
+
+func TestMongoFindPaginatedFieldWithNulls(t *testing.T) {
+	col := newTestCollection(t)
+	for _, doc := range []bson.M{
+		{"name": "n1", "rank": int32(2)},
+		{"name": "n2"},
+		{"name": "n3", "rank": int32(1)},
+		{"name": "n4", "rank": nil},
+		{"name": "n5", "rank": int32(2)},
+		{"name": "n6"},
+		{"name": "n7", "rank": int32(3)},
+	} {
+		doc["_id"] = primitive.NewObjectID()
+		insertDocuments(t, col, doc)
+	}
+	cases := []struct {
+		name               string
+		sortAscending      bool
+		treatMissingAsLast bool
+		expected           []string
+	}{
+		{"null and missing values first ascending", true, false, []string{"n2", "n4", "n6", "n3", "n1", "n5", "n7"}},
+		{"null and missing values last descending", false, false, []string{"n7", "n5", "n1", "n3", "n6", "n4", "n2"}},
+		{"null and missing values last ascending", true, true, []string{"n3", "n1", "n5", "n7", "n2", "n4", "n6"}},
+		{"null and missing values still last descending", false, true, []string{"n7", "n5", "n1", "n3", "n6", "n4", "n2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			requireTraversals(t, mongocursorpagination.FindParams{
+				Collection:         col,
+				Query:              bson.M{},
+				SortAscending:      tc.sortAscending,
+				PaginatedField:     "rank",
+				TreatMissingAsLast: tc.treatMissingAsLast,
+			}, tc.expected)
+		})
+	}
+}
+
+func TestMongoFindPaginatedFieldsWithNulls(t *testing.T) {
+	// status ascending, score descending, label ascending, null and missing values sorting first
+	col := newTestCollection(t)
+	for _, doc := range []bson.M{
+		{"name": "n1", "score": int32(2), "label": "x"},
+		{"name": "n2", "status": nil, "score": int32(2)},
+		{"name": "n3", "label": "y"},
+		{"name": "n4", "status": "a", "score": int32(1), "label": "x"},
+		{"name": "n5", "status": "a", "score": int32(2), "label": "y"},
+		{"name": "n6", "status": "a", "score": int32(2), "label": nil},
+		{"name": "n7", "status": "a", "score": nil},
+		{"name": "n8", "status": "b", "score": int32(1), "label": "x"},
+		{"name": "n9", "status": "b", "score": int32(1)},
+		{"name": "n10", "status": "a", "score": int32(1), "label": "x"},
+		{"name": "n11", "status": nil, "score": int32(2), "label": nil},
+	} {
+		doc["_id"] = primitive.NewObjectID()
+		insertDocuments(t, col, doc)
+	}
+	requireTraversals(t, mongocursorpagination.FindParams{
+		Collection: col,
+		Query:      bson.M{},
+		PaginatedFields: []mongocursorpagination.SortField{
+			{Name: "status", Ascending: true},
+			{Name: "score", Ascending: false},
+			{Name: "label", Ascending: true},
+		},
+		// The _id tie-breaker is sorted ascending
+		SortAscending: true,
+	}, []string{"n2", "n11", "n1", "n3", "n6", "n5", "n4", "n10", "n7", "n9", "n8"})
+}
+
+func TestMongoFindPaginatedFieldsCompoundSort(t *testing.T) {
+	// status ascending, createdAt descending, _id ascending
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	col := newTestCollection(t)
+	for _, doc := range []struct {
+		name      string
+		status    string
+		createdAt time.Time
+	}{
+		{"a1", "active", day.Add(2 * time.Hour)},
+		{"c1", "closed", day.Add(1 * time.Hour)},
+		{"a2", "active", day.Add(1 * time.Hour)},
+		{"c2", "closed", day.Add(3 * time.Hour)},
+		{"a3", "active", day.Add(2 * time.Hour)},
+		{"a4", "active", day.Add(3 * time.Hour)},
+	} {
+		insertDocuments(t, col, bson.M{"_id": primitive.NewObjectID(), "name": doc.name, "status": doc.status, "createdAt": doc.createdAt})
+	}
+	requireTraversals(t, mongocursorpagination.FindParams{
+		Collection: col,
+		Query:      bson.M{},
+		PaginatedFields: []mongocursorpagination.SortField{
+			{Name: "status", Ascending: true},
+			{Name: "createdAt", Ascending: false},
+		},
+		SortAscending: true,
+	}, []string{"a4", "a1", "a3", "a2", "c2", "c1"})
+}
+
+func TestMongoFindOffset(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "a", "b", "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+	cases := []struct {
+		offset         int64
+		expectedNames  []string
+		expectedCursor mongocursorpagination.Cursor
+	}{
+		{2, []string{"c", "d"}, mongocursorpagination.Cursor{HasPrevious: true, HasNext: true, Returned: 2, EffectiveLimit: 2, Count: 5, Ranks: []int{3, 4}}},
+		{4, []string{"e"}, mongocursorpagination.Cursor{HasPrevious: true, HasNext: false, Returned: 1, EffectiveLimit: 2, Count: 5, Ranks: []int{5}}},
+		{6, nil, mongocursorpagination.Cursor{HasPrevious: true, HasNext: false, EffectiveLimit: 2, Count: 5}},
+	}
+	for _, tc := range cases {
+		p.Offset = tc.offset
+		var results []item
+		cursor, err := mongocursorpagination.Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedNames, itemNames(results), "offset %d", tc.offset)
+		require.Equal(t, tc.expectedCursor, cursor, "offset %d", tc.offset)
+	}
+}
+
+func TestMongoFindComputeRanks(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "c", "a", "e", "b", "d", "b")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+		CountTotal:     true,
+		ComputeRanks:   true,
+	}
+	var ranks []int
+	var names []string
+	var cursor mongocursorpagination.Cursor
+	for {
+		var results []item
+		var err error
+		cursor, err = mongocursorpagination.Find(context.Background(), p, &results)
+		require.NoError(t, err)
+		require.Equal(t, 6, cursor.Count)
+		names = append(names, itemNames(results)...)
+		ranks = append(ranks, cursor.Ranks...)
+		if !cursor.HasNext {
+			break
+		}
+		p.Next = cursor.Next
+	}
+	require.Equal(t, []string{"a", "b", "b", "c", "d", "e"}, names)
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6}, ranks)
+
+	// Ranks of a previous page
+	p.Next, p.Previous = "", cursor.Previous
+	cursor, err := mongocursorpagination.Find(context.Background(), p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4}, cursor.Ranks)
+
+	// Ranks in descending order
+	p.Previous, p.SortAscending = "", false
+	cursor, err = mongocursorpagination.Find(context.Background(), p, &[]item{})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, cursor.Ranks)
+}
+
+func TestMongoFindSkipFallback(t *testing.T) {
+	col := newTestCollection(t)
+	insertItems(t, col, "c", "a", "b", "a", "e", "d", "b")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "name",
+	}
+
+	// The pages reached by skipping are the pages of the keyset queries
+	for _, threshold := range []int64{8, 7} {
+		p.SkipFallbackThreshold = threshold
+		for _, tc := range []struct {
+			sortAscending bool
+			expected      []string
+		}{
+			{true, []string{"a", "a", "b", "b", "c", "d", "e"}},
+			{false, []string{"e", "d", "c", "b", "b", "a", "a"}},
+		} {
+			p.SortAscending = tc.sortAscending
+			forward, backward := traverse(t, p, itemName)
+			require.Equal(t, tc.expected, forward, "threshold %d", threshold)
+			require.Equal(t, reversed(tc.expected, 1), backward, "threshold %d", threshold)
+		}
+	}
+}
+
+func TestMongoFindMaxBytes(t *testing.T) {
+	col := newTestCollection(t)
+	big := strings.Repeat("b", 1000)
+	insertItems(t, col, "a", big, "c", "d", "e")
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          10,
+		SortAscending:  true,
+		PaginatedField: "name",
+		MaxBytes:       1,
+	}
+
+	// A document larger than the budget makes a page on its own
+	forward, backward := traverse(t, p, itemName)
+	require.Equal(t, []string{"a", big, "c", "d", "e"}, forward)
+	require.Equal(t, []string{"d", "c", big, "a"}, backward)
+}
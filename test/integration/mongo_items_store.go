@@ -38,6 +38,10 @@ func (c *mongoCollectionWrapper) Find(ctx context.Context, filter interface{}, o
 	return c.collection.Find(ctx, filter, opts...)
 }
 
+func (c *mongoCollectionWrapper) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (mongocursorpagination.MongoCursor, error) {
+	return c.collection.Aggregate(ctx, pipeline, opts...)
+}
+
 func (c *mongoCollectionWrapper) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
 	return c.collection.InsertOne(ctx, document, opts...)
 }
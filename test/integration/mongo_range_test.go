@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMongoCountBetween(t *testing.T) {
+	col := newTestCollection(t)
+	items := insertItems(t, col, "test item 1", "test item 2", "test item 3", "test item 4", "test item 5", "test item 6")
+	item2 := documentCursor(t, items[1], "name", "_id")
+	item5 := documentCursor(t, items[4], "name", "_id")
+	ascending := mongocursorpagination.FindParams{Collection: col, Query: bson.M{}, PaginatedField: "name", SortAscending: true}
+	descending := mongocursorpagination.FindParams{Collection: col, Query: bson.M{}, PaginatedField: "name", SortAscending: false}
+
+	var cases = []struct {
+		name          string
+		findParams    mongocursorpagination.FindParams
+		start         string
+		end           string
+		bounds        mongocursorpagination.RangeBounds
+		expectedCount int64
+	}{
+		{"count documents between the cursors excluding the bounds", ascending, item2, item5, mongocursorpagination.ExcludeBounds, 2},
+		{"count documents between the cursors including the start", ascending, item2, item5, mongocursorpagination.IncludeStart, 3},
+		{"count documents between the cursors including the end", ascending, item2, item5, mongocursorpagination.IncludeEnd, 3},
+		{"count documents between the cursors including the bounds", ascending, item2, item5, mongocursorpagination.IncludeBounds, 4},
+		{"count documents from the beginning when no start cursor is specified", ascending, "", item5, mongocursorpagination.ExcludeBounds, 4},
+		{"count documents to the end when no end cursor is specified", ascending, item2, "", mongocursorpagination.IncludeStart, 5},
+		{"count documents between the cursors in descending order", descending, item5, item2, mongocursorpagination.IncludeBounds, 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := mongocursorpagination.CountBetween(context.Background(), tc.findParams, tc.start, tc.end, tc.bounds)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCount, count)
+		})
+	}
+}
+
+func TestMongoFindWindowTokens(t *testing.T) {
+	col := newTestCollection(t)
+	items := insertItems(t, col, "a", "b", "c", "d", "e", "f", "g")
+	cursorOf := func(i item) string { return documentCursor(t, i, "name", "_id") }
+	p := mongocursorpagination.FindParams{Collection: col, Query: bson.M{}, PaginatedField: "name", SortAscending: true}
+
+	// Render the window [c, f)
+	var window []item
+	cursor, err := mongocursorpagination.FindWindowTokens(context.Background(), p, cursorOf(items[2]), cursorOf(items[5]), &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "d", "e"}, itemNames(window))
+
+	// Extend it in both directions
+	var before, after []item
+	p.Limit, p.Previous = 2, cursor.Previous
+	_, err = mongocursorpagination.Find(context.Background(), p, &before)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, itemNames(before))
+	p.Previous, p.Next = "", cursor.Next
+	_, err = mongocursorpagination.Find(context.Background(), p, &after)
+	require.NoError(t, err)
+	require.Equal(t, []string{"f", "g"}, itemNames(after))
+
+	// The limit caps the window
+	p.Limit, p.Next = 3, ""
+	cursor, err = mongocursorpagination.FindWindowTokens(context.Background(), p, cursorOf(items[1]), "", &window)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c", "d"}, itemNames(window))
+	require.True(t, cursor.HasNext)
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+
+	mongocursorpagination "github.com/qlik-oss/mongocursorpagination/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// money is an amount the default registry can't encode, its cents being unexported.
+type money struct {
+	cents int64
+}
+
+type product struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  string             `bson:"name"`
+	Price money              `bson:"price"`
+}
+
+// newMoneyRegistry returns a registry encoding money as its int64 cents.
+func newMoneyRegistry() *bsoncodec.Registry {
+	moneyType := reflect.TypeOf(money{})
+	return bson.NewRegistryBuilder().
+		RegisterTypeEncoder(moneyType, bsoncodec.ValueEncoderFunc(func(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+			return vw.WriteInt64(val.Interface().(money).cents)
+		})).
+		RegisterTypeDecoder(moneyType, bsoncodec.ValueDecoderFunc(func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+			cents, err := vr.ReadInt64()
+			if err != nil {
+				return err
+			}
+			val.Set(reflect.ValueOf(money{cents: cents}))
+			return nil
+		})).
+		Build()
+}
+
+func TestMongoFindRegistry(t *testing.T) {
+	col := newTestCollection(t)
+	for _, doc := range []struct {
+		name  string
+		cents int64
+	}{{"a", 300}, {"b", 100}, {"c", 500}, {"d", 200}, {"e", 400}} {
+		insertDocuments(t, col, bson.M{"_id": primitive.NewObjectID(), "name": doc.name, "price": doc.cents})
+	}
+	p := mongocursorpagination.FindParams{
+		Collection:     col,
+		Query:          bson.M{},
+		Limit:          2,
+		SortAscending:  true,
+		PaginatedField: "price",
+		Registry:       newMoneyRegistry(),
+	}
+
+	// The cursors hold the cents the prices are encoded to, querying the pages in the price order
+	forward, backward := traverse(t, p, func(doc product) string { return doc.Name })
+	require.Equal(t, []string{"b", "d", "a", "e", "c"}, forward)
+	require.Equal(t, reversed(forward, 1), backward)
+}